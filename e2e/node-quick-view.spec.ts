@@ -72,10 +72,11 @@ test.describe('NodeQuickView Component', () => {
     await expect(cpuUsageBar).toHaveAttribute('aria-valuemin', '0');
     await expect(cpuUsageBar).toHaveAttribute('aria-valuemax', '100');
 
-    // Assert: CPU usage percentage should be between 0-100
+    // Assert: CPU usage percentage should be between 0-100 (without metrics-server
+    // the bar shows pod requests, which may be 0)
     const cpuAriaValue = await cpuUsageBar.getAttribute('aria-valuenow');
     const cpuPercentage = parseFloat(cpuAriaValue!);
-    expect(cpuPercentage).toBeGreaterThan(0);
+    expect(cpuPercentage).toBeGreaterThanOrEqual(0);
     expect(cpuPercentage).toBeLessThanOrEqual(100);
 
     // Assert: Memory usage bar should have progressbar role
@@ -87,10 +88,11 @@ test.describe('NodeQuickView Component', () => {
     await expect(memoryUsageBar).toHaveAttribute('aria-valuemin', '0');
     await expect(memoryUsageBar).toHaveAttribute('aria-valuemax', '100');
 
-    // Assert: Memory usage percentage should be between 0-100
+    // Assert: Memory usage percentage should be between 0-100 (without metrics-server
+    // the bar shows pod requests, which may be 0)
     const memoryAriaValue = await memoryUsageBar.getAttribute('aria-valuenow');
     const memoryPercentage = parseFloat(memoryAriaValue!);
-    expect(memoryPercentage).toBeGreaterThan(0);
+    expect(memoryPercentage).toBeGreaterThanOrEqual(0);
     expect(memoryPercentage).toBeLessThanOrEqual(100);
   });
 
@@ -184,10 +184,11 @@ test.describe('Nodes Tab - CPU/Memory UsageBar Accessibility', () => {
     await expect(cpuProgressBar).toHaveAttribute('aria-valuemin', '0');
     await expect(cpuProgressBar).toHaveAttribute('aria-valuemax', '100');
 
-    // Assert: aria-valuenow should be a valid percentage (0-100)
+    // Assert: aria-valuenow should be a valid percentage (0-100); without
+    // metrics-server the bar shows pod requests, which may be 0
     const ariaValueNow = await cpuProgressBar.getAttribute('aria-valuenow');
     const cpuPercentage = parseFloat(ariaValueNow!);
-    expect(cpuPercentage).toBeGreaterThan(0);
+    expect(cpuPercentage).toBeGreaterThanOrEqual(0);
     expect(cpuPercentage).toBeLessThanOrEqual(100);
   });
 
@@ -211,10 +212,11 @@ test.describe('Nodes Tab - CPU/Memory UsageBar Accessibility', () => {
     await expect(memoryProgressBar).toHaveAttribute('aria-valuemin', '0');
     await expect(memoryProgressBar).toHaveAttribute('aria-valuemax', '100');
 
-    // Assert: aria-valuenow should be a valid percentage (0-100)
+    // Assert: aria-valuenow should be a valid percentage (0-100); without
+    // metrics-server the bar shows pod requests, which may be 0
     const ariaValueNow = await memoryProgressBar.getAttribute('aria-valuenow');
     const memoryPercentage = parseFloat(ariaValueNow!);
-    expect(memoryPercentage).toBeGreaterThan(0);
+    expect(memoryPercentage).toBeGreaterThanOrEqual(0);
     expect(memoryPercentage).toBeLessThanOrEqual(100);
   });
 
@@ -1,11 +1,27 @@
 import { fetchJSON } from './client';
 
+/** Requests and limits of a node's pods, relative to the node's allocatable. */
+export interface NodeAllocation {
+  cpuRequestsMillis: number;
+  cpuLimitsMillis: number;
+  memoryRequestsBytes: number;
+  memoryLimitsBytes: number;
+  cpuRequestsPercent: number;
+  cpuLimitsPercent: number;
+  memoryRequestsPercent: number;
+  memoryLimitsPercent: number;
+  podCount: number;
+}
+
 export interface NodeInfo {
   name: string;
   status: 'Ready' | 'NotReady' | 'Ready,SchedulingDisabled';
   role: string;
+  /** False when metrics-server has no data; cpuPercent and memoryPercent are then 0. */
+  metricsAvailable?: boolean;
   cpuPercent: number;
   memoryPercent: number;
+  allocation?: NodeAllocation;
   podCount: number;
 }
 
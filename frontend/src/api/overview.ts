@@ -1,5 +1,5 @@
 import { fetchJSON, buildURL } from './client';
-import type { NodeInfo } from './nodes';
+import type { NodeAllocation, NodeInfo } from './nodes';
 
 export interface UnhealthyPodInfo {
   name: string;
@@ -10,6 +10,19 @@ export interface UnhealthyPodInfo {
 /** Node info as returned by the overview endpoint (without podCount). */
 export type OverviewNodeInfo = Omit<NodeInfo, 'podCount'>;
 
+/**
+ * Cluster-wide requests and limits. Committed ratios are requests over
+ * allocatable; overcommit ratios are limits over allocatable.
+ */
+export interface ClusterAllocation extends NodeAllocation {
+  cpuAllocatableMillis: number;
+  memoryAllocatableBytes: number;
+  cpuCommittedRatio: number;
+  memoryCommittedRatio: number;
+  cpuOvercommitRatio: number;
+  memoryOvercommitRatio: number;
+}
+
 export interface OverviewData {
   nodes: {
     ready: number;
@@ -17,8 +30,11 @@ export interface OverviewData {
   };
   unhealthyPods: number;
   unhealthyPodsList?: UnhealthyPodInfo[];
+  /** False when metrics-server has no data; the averages are then 0. */
+  metricsAvailable?: boolean;
   avgCpuPercent: number;
   avgMemoryPercent: number;
+  allocation?: ClusterAllocation;
   nodesList?: OverviewNodeInfo[];
 }
 
@@ -1,6 +1,8 @@
 import { useEffect, useState } from 'react';
 import { PodDetails, DebugPodRequest, DebugPodResult } from '../api/pods';
 
+// Keep in sync with defaultDebugImages in handlers/debug_profiles.go, which the
+// server allows when no image allowlist is configured.
 export const DEBUG_IMAGE_PRESETS: { value: string; label: string }[] = [
   { value: 'nicolaka/netshoot:latest', label: 'netshoot (network debugging)' },
   { value: 'busybox:1.36', label: 'busybox (minimal)' },
@@ -717,4 +717,60 @@ describe('NodeCard Component', () => {
       expect(screen.getByTestId('node-pod-count')).toBeInTheDocument();
     });
   });
+
+  describe('Metrics Unavailable', () => {
+    it('should show pod requests instead of 0% usage', () => {
+      // Arrange
+      const node = {
+        name: 'test-node',
+        status: 'Ready' as const,
+        role: '',
+        metricsAvailable: false,
+        cpuPercent: 0,
+        memoryPercent: 0,
+        allocation: {
+          cpuRequestsMillis: 950,
+          cpuLimitsMillis: 2000,
+          memoryRequestsBytes: 1073741824,
+          memoryLimitsBytes: 2147483648,
+          cpuRequestsPercent: 47.5,
+          cpuLimitsPercent: 100,
+          memoryRequestsPercent: 25,
+          memoryLimitsPercent: 50,
+          podCount: 8,
+        },
+        podCount: 8,
+      };
+
+      // Act
+      render(<NodeCard {...node} />);
+
+      // Assert
+      expect(screen.getByTestId('node-metrics-unavailable')).toHaveTextContent(/metrics unavailable/i);
+      const bars = screen.getAllByRole('progressbar');
+      expect(bars[0]).toHaveAttribute('aria-valuenow', '47.5');
+      expect(bars[0]).toHaveAttribute('aria-label', 'CPU Requests: 47.5%');
+      expect(bars[1]).toHaveAttribute('aria-valuenow', '25');
+    });
+
+    it('should not show the unavailable note when metrics are available', () => {
+      // Arrange
+      const node = {
+        name: 'test-node',
+        status: 'Ready' as const,
+        role: '',
+        metricsAvailable: true,
+        cpuPercent: 30,
+        memoryPercent: 40,
+        podCount: 8,
+      };
+
+      // Act
+      render(<NodeCard {...node} />);
+
+      // Assert
+      expect(screen.queryByTestId('node-metrics-unavailable')).not.toBeInTheDocument();
+      expect(screen.getAllByRole('progressbar')[0]).toHaveAttribute('aria-valuenow', '30');
+    });
+  });
 });
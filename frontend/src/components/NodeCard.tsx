@@ -2,13 +2,27 @@ import { NodeInfo } from '../api/nodes';
 import { StatusBadge } from './StatusBadge';
 import { UsageBar } from './UsageBar';
 
-export function NodeCard({ name, status, role, cpuPercent, memoryPercent, podCount }: NodeInfo) {
+export function NodeCard({
+  name,
+  status,
+  role,
+  metricsAvailable = true,
+  cpuPercent,
+  memoryPercent,
+  allocation,
+  podCount,
+}: NodeInfo) {
   const isReady = status === 'Ready' || status === 'Ready,SchedulingDisabled';
   const isSchedulingDisabled = status === 'Ready,SchedulingDisabled';
 
+  // Without metrics, show what pods have requested instead of a misleading 0% usage
+  const cpuValue = metricsAvailable ? cpuPercent : (allocation?.cpuRequestsPercent ?? 0);
+  const memoryValue = metricsAvailable ? memoryPercent : (allocation?.memoryRequestsPercent ?? 0);
+  const barSuffix = metricsAvailable ? '' : ' Requests';
+
   // Clamp percentages between 0 and 100
-  const clampedCpuPercent = Math.max(0, Math.min(100, cpuPercent));
-  const clampedMemoryPercent = Math.max(0, Math.min(100, memoryPercent));
+  const clampedCpuPercent = Math.max(0, Math.min(100, cpuValue));
+  const clampedMemoryPercent = Math.max(0, Math.min(100, memoryValue));
 
   return (
     <div data-testid="node-card" className="bg-white rounded-lg shadow p-6 space-y-4">
@@ -41,14 +55,20 @@ export function NodeCard({ name, status, role, cpuPercent, memoryPercent, podCou
       {/* Ready Node - Show Usage Bars */}
       {isReady && (
         <div className="space-y-4">
+          {!metricsAvailable && (
+            <div data-testid="node-metrics-unavailable" className="text-xs text-gray-500">
+              Metrics unavailable. Showing pod requests.
+            </div>
+          )}
+
           <div data-testid="node-cpu-usage">
-            <div className="text-sm font-medium text-gray-700 mb-2">CPU</div>
-            <UsageBar percentage={clampedCpuPercent} label="CPU" />
+            <div className="text-sm font-medium text-gray-700 mb-2">CPU{barSuffix}</div>
+            <UsageBar percentage={clampedCpuPercent} label={`CPU${barSuffix}`} />
           </div>
 
           <div data-testid="node-memory-usage">
-            <div className="text-sm font-medium text-gray-700 mb-2">Memory</div>
-            <UsageBar percentage={clampedMemoryPercent} label="Memory" />
+            <div className="text-sm font-medium text-gray-700 mb-2">Memory{barSuffix}</div>
+            <UsageBar percentage={clampedMemoryPercent} label={`Memory${barSuffix}`} />
           </div>
         </div>
       )}
@@ -1480,4 +1480,54 @@ describe('NodeQuickView', () => {
       });
     });
   });
+
+  describe('metrics unavailable', () => {
+    it('should show pod requests for nodes without metrics', async () => {
+      // Arrange
+      vi.mocked(useDashboard).mockReturnValue({
+        overviewData: {
+          nodes: { ready: 1, total: 1 },
+          unhealthyPods: 0,
+          metricsAvailable: false,
+          avgCpuPercent: 0,
+          avgMemoryPercent: 0,
+          nodesList: [
+            {
+              name: 'node-1',
+              status: 'Ready',
+              role: '',
+              metricsAvailable: false,
+              cpuPercent: 0,
+              memoryPercent: 0,
+              allocation: {
+                cpuRequestsMillis: 950,
+                cpuLimitsMillis: 2000,
+                memoryRequestsBytes: 1073741824,
+                memoryLimitsBytes: 2147483648,
+                cpuRequestsPercent: 47.5,
+                cpuLimitsPercent: 100,
+                memoryRequestsPercent: 25,
+                memoryLimitsPercent: 50,
+                podCount: 8,
+              },
+            },
+          ],
+        },
+        isLoading: false,
+        error: null,
+        loadDashboard: vi.fn(),
+      });
+
+      // Act
+      render(<NodeQuickView />);
+
+      // Assert
+      await waitFor(() => {
+        expect(screen.getByTestId('node-metrics-unavailable')).toBeInTheDocument();
+        const bars = screen.getAllByRole('progressbar');
+        expect(bars[0]).toHaveAttribute('aria-label', 'CPU Requests: 47.5%');
+        expect(bars[1]).toHaveAttribute('aria-label', 'Memory Requests: 25%');
+      });
+    });
+  });
 });
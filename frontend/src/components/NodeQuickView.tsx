@@ -136,18 +136,40 @@ export function NodeQuickView() {
               </div>
 
               {/* Ready nodes (including SchedulingDisabled): show usage bars */}
-              {(node.status === 'Ready' || node.status === 'Ready,SchedulingDisabled') && (
-                <div className="space-y-2">
-                  <div data-testid="node-cpu-usage">
-                    <div className="text-xs text-gray-600 mb-1">CPU</div>
-                    <UsageBar percentage={node.cpuPercent} label="CPU" />
+              {(node.status === 'Ready' || node.status === 'Ready,SchedulingDisabled') &&
+                (node.metricsAvailable === false ? (
+                  // Without metrics, show what pods have requested instead of a misleading 0% usage
+                  <div className="space-y-2">
+                    <div data-testid="node-metrics-unavailable" className="text-xs text-gray-500">
+                      Metrics unavailable. Showing pod requests.
+                    </div>
+                    <div data-testid="node-cpu-usage">
+                      <div className="text-xs text-gray-600 mb-1">CPU Requests</div>
+                      <UsageBar
+                        percentage={Math.min(100, node.allocation?.cpuRequestsPercent ?? 0)}
+                        label="CPU Requests"
+                      />
+                    </div>
+                    <div data-testid="node-memory-usage">
+                      <div className="text-xs text-gray-600 mb-1">Memory Requests</div>
+                      <UsageBar
+                        percentage={Math.min(100, node.allocation?.memoryRequestsPercent ?? 0)}
+                        label="Memory Requests"
+                      />
+                    </div>
                   </div>
-                  <div data-testid="node-memory-usage">
-                    <div className="text-xs text-gray-600 mb-1">Memory</div>
-                    <UsageBar percentage={node.memoryPercent} label="Memory" />
+                ) : (
+                  <div className="space-y-2">
+                    <div data-testid="node-cpu-usage">
+                      <div className="text-xs text-gray-600 mb-1">CPU</div>
+                      <UsageBar percentage={node.cpuPercent} label="CPU" />
+                    </div>
+                    <div data-testid="node-memory-usage">
+                      <div className="text-xs text-gray-600 mb-1">Memory</div>
+                      <UsageBar percentage={node.memoryPercent} label="Memory" />
+                    </div>
                   </div>
-                </div>
-              )}
+                ))}
 
               {/* SchedulingDisabled nodes: show scheduling warning */}
               {node.status === 'Ready,SchedulingDisabled' && (
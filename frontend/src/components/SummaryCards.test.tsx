@@ -468,4 +468,53 @@ describe('SummaryCards', () => {
       });
     });
   });
+
+  describe('metrics unavailable', () => {
+    it('should show metrics unavailable with request bars instead of 0%', async () => {
+      // Arrange
+      vi.mocked(useDashboard).mockReturnValue({
+        overviewData: {
+          nodes: { ready: 2, total: 2 },
+          unhealthyPods: 0,
+          metricsAvailable: false,
+          avgCpuPercent: 0,
+          avgMemoryPercent: 0,
+          allocation: {
+            cpuRequestsMillis: 950,
+            cpuLimitsMillis: 2000,
+            memoryRequestsBytes: 1073741824,
+            memoryLimitsBytes: 2147483648,
+            cpuRequestsPercent: 47.5,
+            cpuLimitsPercent: 100,
+            memoryRequestsPercent: 25,
+            memoryLimitsPercent: 50,
+            podCount: 8,
+            cpuAllocatableMillis: 2000,
+            memoryAllocatableBytes: 4294967296,
+            cpuCommittedRatio: 0.475,
+            memoryCommittedRatio: 0.25,
+            cpuOvercommitRatio: 1,
+            memoryOvercommitRatio: 0.5,
+          },
+        },
+        isLoading: false,
+        error: null,
+        loadDashboard: vi.fn(),
+      });
+
+      // Act
+      render(<SummaryCards />);
+
+      // Assert
+      await waitFor(() => {
+        const cpuCard = screen.getByTestId('summary-card-avg-cpu');
+        expect(within(cpuCard).getByTestId('summary-card-value')).toHaveTextContent('Metrics unavailable');
+        expect(within(cpuCard).getByRole('progressbar')).toHaveAttribute('aria-valuenow', '47.5');
+
+        const memoryCard = screen.getByTestId('summary-card-avg-memory');
+        expect(within(memoryCard).getByTestId('summary-card-value')).toHaveTextContent('Metrics unavailable');
+        expect(within(memoryCard).getByRole('progressbar')).toHaveAttribute('aria-label', 'Memory Requests: 25%');
+      });
+    });
+  });
 });
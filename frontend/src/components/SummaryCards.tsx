@@ -78,6 +78,11 @@ export function SummaryCards() {
     return null;
   }
 
+  // Without metrics, the usage cards show what pods have requested instead of 0%
+  const metricsAvailable = data.metricsAvailable !== false;
+  const cpuRequestsPercent = Math.min(100, data.allocation?.cpuRequestsPercent ?? 0);
+  const memoryRequestsPercent = Math.min(100, data.allocation?.memoryRequestsPercent ?? 0);
+
   return (
     <div
       data-testid="summary-cards-container"
@@ -99,22 +104,34 @@ export function SummaryCards() {
       />
 
       {/* Avg CPU Card with UsageBar */}
-      <SummaryCard
-        label="Avg CPU"
-        value={`${data.avgCpuPercent.toFixed(1)}%`}
-        testId="summary-card-avg-cpu"
-      >
-        <UsageBar percentage={data.avgCpuPercent} label="CPU Usage" />
-      </SummaryCard>
+      {metricsAvailable ? (
+        <SummaryCard
+          label="Avg CPU"
+          value={`${data.avgCpuPercent.toFixed(1)}%`}
+          testId="summary-card-avg-cpu"
+        >
+          <UsageBar percentage={data.avgCpuPercent} label="CPU Usage" />
+        </SummaryCard>
+      ) : (
+        <SummaryCard label="Avg CPU" value="Metrics unavailable" testId="summary-card-avg-cpu">
+          <UsageBar percentage={cpuRequestsPercent} label="CPU Requests" />
+        </SummaryCard>
+      )}
 
       {/* Avg Memory Card with UsageBar */}
-      <SummaryCard
-        label="Avg Memory"
-        value={`${data.avgMemoryPercent.toFixed(1)}%`}
-        testId="summary-card-avg-memory"
-      >
-        <UsageBar percentage={data.avgMemoryPercent} label="Memory Usage" />
-      </SummaryCard>
+      {metricsAvailable ? (
+        <SummaryCard
+          label="Avg Memory"
+          value={`${data.avgMemoryPercent.toFixed(1)}%`}
+          testId="summary-card-avg-memory"
+        >
+          <UsageBar percentage={data.avgMemoryPercent} label="Memory Usage" />
+        </SummaryCard>
+      ) : (
+        <SummaryCard label="Avg Memory" value="Metrics unavailable" testId="summary-card-avg-memory">
+          <UsageBar percentage={memoryRequestsPercent} label="Memory Requests" />
+        </SummaryCard>
+      )}
     </div>
   );
 }
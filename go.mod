@@ -13,6 +13,7 @@ require (
 	k8s.io/apimachinery v0.27.0
 	k8s.io/client-go v0.27.0
 	k8s.io/metrics v0.27.0
+	sigs.k8s.io/yaml v1.3.0
 )
 
 replace github.com/argoproj/argo-workflows/v3 => ./internal/argoversioned
@@ -59,5 +60,4 @@ require (
 	k8s.io/utils v0.0.0-20230209194617-a36077c30491 // indirect
 	sigs.k8s.io/json v0.0.0-20221116044647-bc3834ca7abd // indirect
 	sigs.k8s.io/structured-merge-diff/v4 v4.2.3 // indirect
-	sigs.k8s.io/yaml v1.3.0 // indirect
 )
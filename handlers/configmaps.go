@@ -0,0 +1,456 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ConfigMap reference types reported by the consumers lookup.
+const (
+	configMapRefEnv     = "env"
+	configMapRefEnvFrom = "envFrom"
+	configMapRefVolume  = "volume"
+)
+
+// getConfigMapClientset is a package-level variable for obtaining the Kubernetes
+// client used by the configmap endpoints. Tests may override this.
+var getConfigMapClientset func() (kubernetes.Interface, error) = func() (kubernetes.Interface, error) {
+	return getKubernetesClient()
+}
+
+// configMapConsumerKinds are the workload kinds searched for consumers and
+// restarted after an edit.
+var configMapConsumerKinds = []workloadKind{workloadDeployment, workloadStatefulSet, workloadDaemonSet}
+
+// ConfigMapInfo represents summary information about a configmap
+type ConfigMapInfo struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Keys lists the keys of data and binaryData, sorted.
+	Keys      []string `json:"keys"`
+	Immutable bool     `json:"immutable"`
+	Age       string   `json:"age"`
+}
+
+// ConfigMapBinaryValue is a binaryData entry; Data is base64-encoded in JSON.
+type ConfigMapBinaryValue struct {
+	Size int    `json:"size"`
+	Data []byte `json:"data"`
+}
+
+// UnmarshalJSON accepts the {size, data} object returned by GET as well as a
+// plain base64 string, so edited details can be sent back as they were read.
+func (v *ConfigMapBinaryValue) UnmarshalJSON(b []byte) error {
+	var data []byte
+	if err := json.Unmarshal(b, &data); err != nil {
+		var obj struct {
+			Data []byte `json:"data"`
+		}
+		if err := json.Unmarshal(b, &obj); err != nil {
+			return err
+		}
+		data = obj.Data
+	}
+	*v = ConfigMapBinaryValue{Size: len(data), Data: data}
+	return nil
+}
+
+// ConfigMapDetail is the response of GET /api/configmaps/{ns}/{name}.
+type ConfigMapDetail struct {
+	ConfigMapInfo
+	Labels          map[string]string               `json:"labels"`
+	Annotations     map[string]string               `json:"annotations"`
+	Data            map[string]string               `json:"data"`
+	BinaryData      map[string]ConfigMapBinaryValue `json:"binaryData"`
+	ResourceVersion string                          `json:"resourceVersion"`
+}
+
+// configMapUpdateRequest is the JSON body for PUT /api/configmaps/{ns}/{name}.
+// Data and BinaryData replace the configmap's contents; an omitted (null) map
+// leaves that part unchanged, while an empty one clears it.
+type configMapUpdateRequest struct {
+	Data map[string]string `json:"data"`
+	// BinaryData values are base64 strings or {size, data} objects as returned by GET.
+	BinaryData map[string]ConfigMapBinaryValue `json:"binaryData"`
+	// ResourceVersion, when set, makes the update fail with a conflict if the
+	// configmap changed since it was read.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	// RestartConsumers restarts the Deployments, StatefulSets and DaemonSets
+	// using the configmap once the edit is saved.
+	RestartConsumers bool `json:"restartConsumers"`
+}
+
+// ConfigMapUpdateResponse reports the result of a configmap edit.
+type ConfigMapUpdateResponse struct {
+	Changed         bool   `json:"changed"`
+	ResourceVersion string `json:"resourceVersion"`
+	// Restarted and RestartFailed list consumer workloads as Kind/name.
+	Restarted     []string `json:"restarted"`
+	RestartFailed []string `json:"restartFailed"`
+	Message       string   `json:"message"`
+}
+
+// ConfigMapReference is one way a pod spec uses a configmap.
+type ConfigMapReference struct {
+	Type      string `json:"type"`
+	Container string `json:"container,omitempty"`
+	// Key is the configmap key read by an env reference.
+	Key      string `json:"key,omitempty"`
+	Volume   string `json:"volume,omitempty"`
+	Optional bool   `json:"optional"`
+}
+
+// ConfigMapConsumer is a pod or workload that uses a configmap.
+type ConfigMapConsumer struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Controller names the controller of a pod as Kind/name.
+	Controller string               `json:"controller,omitempty"`
+	References []ConfigMapReference `json:"references"`
+}
+
+// ConfigMapConsumers is the response of GET /api/configmaps/{ns}/{name}/consumers.
+type ConfigMapConsumers struct {
+	Workloads []ConfigMapConsumer `json:"workloads"`
+	Pods      []ConfigMapConsumer `json:"pods"`
+}
+
+// ConfigMapsHandler handles the GET /api/configmaps endpoint
+var ConfigMapsHandler = handleGet("Failed to fetch configmaps data", func(r *http.Request) (interface{}, error) {
+	clientset, err := getConfigMapClientset()
+	if err != nil {
+		return nil, err
+	}
+	return getConfigMapsData(r.Context(), clientset, r.URL.Query().Get("ns"))
+})
+
+func getConfigMapsData(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]ConfigMapInfo, error) {
+	list, err := clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	configMaps := make([]ConfigMapInfo, 0, len(list.Items))
+	for i := range list.Items {
+		configMaps = append(configMaps, buildConfigMapInfo(&list.Items[i]))
+	}
+	return configMaps, nil
+}
+
+// ConfigMapActionsHandler handles GET and PUT /api/configmaps/{ns}/{name} and
+// GET /api/configmaps/{ns}/{name}/consumers.
+func ConfigMapActionsHandler(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, consumersPathSuffix):
+		handleConfigMapConsumers(w, r)
+	case strings.Count(strings.TrimPrefix(r.URL.Path, configMapsPathPrefix), "/") == 1:
+		r = withTimeout(r)
+		switch r.Method {
+		case http.MethodGet:
+			handleGetConfigMapDetail(w, r)
+		case http.MethodPut:
+			handleUpdateConfigMap(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	default:
+		writeError(w, http.StatusNotFound, "Unknown configmap action")
+	}
+}
+
+func handleGetConfigMapDetail(w http.ResponseWriter, r *http.Request) {
+	namespace, name, clientset := parseNamespacedAction(w, r, configMapsPathPrefix, "", getConfigMapClientset)
+	if clientset == nil {
+		return
+	}
+
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(r.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		writeResourceError(w, err, errMsgConfigMapNotFound, errMsgConfigMapFetch)
+		return
+	}
+	writeJSON(w, http.StatusOK, buildConfigMapDetail(cm))
+}
+
+func handleUpdateConfigMap(w http.ResponseWriter, r *http.Request) {
+	namespace, name, clientset := parseNamespacedAction(w, r, configMapsPathPrefix, "", getConfigMapClientset)
+	if clientset == nil {
+		return
+	}
+
+	var req configMapUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errMsgConfigMapInvalidRequest)
+		return
+	}
+
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(r.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		writeResourceError(w, err, errMsgConfigMapNotFound, errMsgConfigMapUpdate)
+		return
+	}
+	if cm.Immutable != nil && *cm.Immutable {
+		writeError(w, http.StatusConflict, errMsgConfigMapImmutable)
+		return
+	}
+
+	data, binaryData := cm.Data, cm.BinaryData
+	if req.Data != nil {
+		data = req.Data
+	}
+	if req.BinaryData != nil {
+		binaryData = make(map[string][]byte, len(req.BinaryData))
+		for k, v := range req.BinaryData {
+			binaryData[k] = v.Data
+		}
+	}
+
+	resp := &ConfigMapUpdateResponse{
+		Changed:         !stringMapsEqual(cm.Data, data) || !binaryMapsEqual(cm.BinaryData, binaryData),
+		ResourceVersion: cm.ResourceVersion,
+		Restarted:       []string{},
+		RestartFailed:   []string{},
+		Message:         "No changes",
+	}
+	if resp.Changed {
+		cm.Data = data
+		cm.BinaryData = binaryData
+		if req.ResourceVersion != "" {
+			cm.ResourceVersion = req.ResourceVersion
+		}
+		updated, err := clientset.CoreV1().ConfigMaps(namespace).Update(r.Context(), cm, metav1.UpdateOptions{FieldManager: dashboardFieldManager})
+		if err != nil {
+			writeAPIError(w, err, errMsgConfigMapUpdate)
+			return
+		}
+		resp.ResourceVersion = updated.ResourceVersion
+		resp.Message = "ConfigMap updated"
+	}
+
+	// Consumers are only restarted when the edit changed something they read.
+	if req.RestartConsumers && resp.Changed {
+		consumers, err := findConfigMapConsumers(r.Context(), clientset, namespace, name)
+		if err != nil {
+			writeResourceError(w, err, errMsgConfigMapNotFound, errMsgConfigMapConsumers)
+			return
+		}
+		restartConfigMapConsumers(r.Context(), clientset, namespace, consumers.Workloads, resp)
+		resp.Message = "ConfigMap updated and consumers restarted"
+		if len(resp.RestartFailed) > 0 {
+			resp.Message = "ConfigMap updated; some consumers failed to restart"
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// restartConfigMapConsumers restarts each consumer workload, recording the
+// outcome in resp. A failed restart does not stop the others.
+func restartConfigMapConsumers(ctx context.Context, clientset kubernetes.Interface, namespace string, workloads []ConfigMapConsumer, resp *ConfigMapUpdateResponse) {
+	for _, consumer := range workloads {
+		ref := consumer.Kind + "/" + consumer.Name
+		for _, kind := range configMapConsumerKinds {
+			if kind.kind != consumer.Kind {
+				continue
+			}
+			if _, err := restartWorkload(ctx, clientset, kind, namespace, consumer.Name); err != nil {
+				slog.Error("Failed to restart configmap consumer", "workload", ref, "error", err)
+				resp.RestartFailed = append(resp.RestartFailed, ref)
+			} else {
+				resp.Restarted = append(resp.Restarted, ref)
+			}
+		}
+	}
+}
+
+// handleConfigMapConsumers handles GET /api/configmaps/{ns}/{name}/consumers.
+func handleConfigMapConsumers(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, clientset := parseNamespacedAction(w, r, configMapsPathPrefix, consumersPathSuffix, getConfigMapClientset)
+	if clientset == nil {
+		return
+	}
+
+	if _, err := clientset.CoreV1().ConfigMaps(namespace).Get(r.Context(), name, metav1.GetOptions{}); err != nil {
+		writeResourceError(w, err, errMsgConfigMapNotFound, errMsgConfigMapConsumers)
+		return
+	}
+	consumers, err := findConfigMapConsumers(r.Context(), clientset, namespace, name)
+	if err != nil {
+		writeResourceError(w, err, errMsgConfigMapNotFound, errMsgConfigMapConsumers)
+		return
+	}
+	writeJSON(w, http.StatusOK, consumers)
+}
+
+// findConfigMapConsumers finds the workloads and pods in the namespace whose pod
+// spec references the configmap, each sorted by name.
+func findConfigMapConsumers(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*ConfigMapConsumers, error) {
+	consumers := &ConfigMapConsumers{Workloads: []ConfigMapConsumer{}, Pods: []ConfigMapConsumer{}}
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	daemonSets, err := clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	addWorkload := func(kind, workloadName string, spec *corev1.PodSpec) {
+		if refs := podSpecConfigMapRefs(spec, name); len(refs) > 0 {
+			consumers.Workloads = append(consumers.Workloads, ConfigMapConsumer{Kind: kind, Name: workloadName, References: refs})
+		}
+	}
+	for i := range deployments.Items {
+		addWorkload(workloadDeployment.kind, deployments.Items[i].Name, &deployments.Items[i].Spec.Template.Spec)
+	}
+	for i := range statefulSets.Items {
+		addWorkload(workloadStatefulSet.kind, statefulSets.Items[i].Name, &statefulSets.Items[i].Spec.Template.Spec)
+	}
+	for i := range daemonSets.Items {
+		addWorkload(workloadDaemonSet.kind, daemonSets.Items[i].Name, &daemonSets.Items[i].Spec.Template.Spec)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		refs := podSpecConfigMapRefs(&pod.Spec, name)
+		if len(refs) == 0 {
+			continue
+		}
+		consumer := ConfigMapConsumer{Kind: "Pod", Name: pod.Name, References: refs}
+		if owner := metav1.GetControllerOf(pod); owner != nil {
+			consumer.Controller = owner.Kind + "/" + owner.Name
+		}
+		consumers.Pods = append(consumers.Pods, consumer)
+	}
+
+	sort.SliceStable(consumers.Workloads, func(i, j int) bool {
+		return consumers.Workloads[i].Name < consumers.Workloads[j].Name
+	})
+	sort.Slice(consumers.Pods, func(i, j int) bool { return consumers.Pods[i].Name < consumers.Pods[j].Name })
+	return consumers, nil
+}
+
+// podSpecConfigMapRefs returns the env, envFrom and volume references to the
+// named configmap in a pod spec, including projected volumes.
+func podSpecConfigMapRefs(spec *corev1.PodSpec, name string) []ConfigMapReference {
+	var refs []ConfigMapReference
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		for _, env := range c.Env {
+			if env.ValueFrom == nil || env.ValueFrom.ConfigMapKeyRef == nil || env.ValueFrom.ConfigMapKeyRef.Name != name {
+				continue
+			}
+			ref := env.ValueFrom.ConfigMapKeyRef
+			refs = append(refs, ConfigMapReference{
+				Type: configMapRefEnv, Container: c.Name, Key: ref.Key, Optional: ref.Optional != nil && *ref.Optional,
+			})
+		}
+		for _, envFrom := range c.EnvFrom {
+			if ref := envFrom.ConfigMapRef; ref != nil && ref.Name == name {
+				refs = append(refs, ConfigMapReference{
+					Type: configMapRefEnvFrom, Container: c.Name, Optional: ref.Optional != nil && *ref.Optional,
+				})
+			}
+		}
+	}
+	for _, v := range spec.Volumes {
+		if cm := v.ConfigMap; cm != nil && cm.Name == name {
+			refs = append(refs, ConfigMapReference{
+				Type: configMapRefVolume, Volume: v.Name, Optional: cm.Optional != nil && *cm.Optional,
+			})
+		}
+		if v.Projected == nil {
+			continue
+		}
+		for _, source := range v.Projected.Sources {
+			if cm := source.ConfigMap; cm != nil && cm.Name == name {
+				refs = append(refs, ConfigMapReference{
+					Type: configMapRefVolume, Volume: v.Name, Optional: cm.Optional != nil && *cm.Optional,
+				})
+			}
+		}
+	}
+	return refs
+}
+
+func buildConfigMapInfo(cm *corev1.ConfigMap) ConfigMapInfo {
+	keys := make([]string, 0, len(cm.Data)+len(cm.BinaryData))
+	for k := range cm.Data {
+		keys = append(keys, k)
+	}
+	for k := range cm.BinaryData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return ConfigMapInfo{
+		Name:      cm.Name,
+		Namespace: cm.Namespace,
+		Keys:      keys,
+		Immutable: cm.Immutable != nil && *cm.Immutable,
+		Age:       formatPodAge(cm.CreationTimestamp.Time),
+	}
+}
+
+func buildConfigMapDetail(cm *corev1.ConfigMap) *ConfigMapDetail {
+	detail := &ConfigMapDetail{
+		ConfigMapInfo:   buildConfigMapInfo(cm),
+		Labels:          cm.Labels,
+		Annotations:     cm.Annotations,
+		Data:            cm.Data,
+		BinaryData:      make(map[string]ConfigMapBinaryValue, len(cm.BinaryData)),
+		ResourceVersion: cm.ResourceVersion,
+	}
+	if detail.Data == nil {
+		detail.Data = map[string]string{}
+	}
+	for k, v := range cm.BinaryData {
+		detail.BinaryData[k] = ConfigMapBinaryValue{Size: len(v), Data: v}
+	}
+	return detail
+}
+
+func stringMapsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if other, ok := b[k]; !ok || other != v {
+			return false
+		}
+	}
+	return true
+}
+
+func binaryMapsEqual(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if other, ok := b[k]; !ok || !bytes.Equal(other, v) {
+			return false
+		}
+	}
+	return true
+}
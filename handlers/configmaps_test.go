@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// withConfigMapClientset injects cs for configmap endpoints.
+func withConfigMapClientset(t *testing.T, cs kubernetes.Interface) {
+	t.Helper()
+	old := getConfigMapClientset
+	getConfigMapClientset = func() (kubernetes.Interface, error) { return cs, nil }
+	t.Cleanup(func() { getConfigMapClientset = old })
+}
+
+func newTestConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "settings", ResourceVersion: "7"},
+		Data:       map[string]string{"LOG_LEVEL": "info", "app.yaml": "port: 8080\n"},
+		BinaryData: map[string][]byte{"logo.png": {0x89, 0x50, 0x4e, 0x47}},
+	}
+}
+
+// newConfigMapConsumers returns a deployment reading the settings configmap
+// through env, a statefulset mounting it and a daemonset not using it.
+func newConfigMapConsumers() (*appsv1.Deployment, *appsv1.StatefulSet, *appsv1.DaemonSet) {
+	d := newTestDeployment("default", "web", 2)
+	d.Spec.Template = podTemplate("web:1")
+	d.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{
+		Name: "LOG_LEVEL",
+		ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}, Key: "LOG_LEVEL",
+		}},
+	}}
+
+	sts := newTestStatefulSet()
+	sts.Spec.Template.Spec.Volumes = []corev1.Volume{{
+		Name: "config",
+		VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{{
+			ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}},
+		}}}},
+	}}
+
+	return d, sts, newTestDaemonSet()
+}
+
+func TestConfigMapDetail(t *testing.T) {
+	withConfigMapClientset(t, fake.NewSimpleClientset(newTestConfigMap()))
+
+	w := httptest.NewRecorder()
+	ConfigMapActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/configmaps/default/settings", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var detail ConfigMapDetail
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if strings.Join(detail.Keys, ",") != "LOG_LEVEL,app.yaml,logo.png" {
+		t.Errorf("unexpected keys: %v", detail.Keys)
+	}
+	if detail.Data["app.yaml"] != "port: 8080\n" || detail.ResourceVersion != "7" {
+		t.Errorf("unexpected detail: %+v", detail)
+	}
+	if logo := detail.BinaryData["logo.png"]; logo.Size != 4 || string(logo.Data) != "\x89PNG" {
+		t.Errorf("unexpected binary data: %+v", logo)
+	}
+	if !strings.Contains(w.Body.String(), `"data":"iVBORw=="`) {
+		t.Errorf("binary data should be base64-encoded: %s", w.Body.String())
+	}
+}
+
+func TestConfigMapDetail_NotFound(t *testing.T) {
+	withConfigMapClientset(t, fake.NewSimpleClientset())
+
+	w := httptest.NewRecorder()
+	ConfigMapActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/configmaps/default/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestFindConfigMapConsumers(t *testing.T) {
+	d, sts, ds := newConfigMapConsumers()
+	pod := newControlledPod("web-abc", "ReplicaSet", "rs-uid")
+	pod.Spec.Containers[0].EnvFrom = []corev1.EnvFromSource{{
+		ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}},
+	}}
+	unrelated := newControlledPod("other", "ReplicaSet", "rs-uid")
+
+	consumers, err := findConfigMapConsumers(context.Background(), fake.NewSimpleClientset(d, sts, ds, pod, unrelated), "default", "settings")
+	if err != nil {
+		t.Fatalf("findConfigMapConsumers: %v", err)
+	}
+
+	if len(consumers.Workloads) != 2 {
+		t.Fatalf("expected 2 workloads, got %+v", consumers.Workloads)
+	}
+	db, web := consumers.Workloads[0], consumers.Workloads[1]
+	if db.Kind != "StatefulSet" || db.References[0].Type != configMapRefVolume || db.References[0].Volume != "config" {
+		t.Errorf("unexpected statefulset consumer: %+v", db)
+	}
+	if web.Kind != "Deployment" || web.References[0].Type != configMapRefEnv || web.References[0].Key != "LOG_LEVEL" {
+		t.Errorf("unexpected deployment consumer: %+v", web)
+	}
+
+	if len(consumers.Pods) != 1 || consumers.Pods[0].Name != "web-abc" || consumers.Pods[0].Controller != "ReplicaSet/owner" {
+		t.Fatalf("unexpected pods: %+v", consumers.Pods)
+	}
+	if ref := consumers.Pods[0].References[0]; ref.Type != configMapRefEnvFrom || ref.Container != "app" {
+		t.Errorf("unexpected pod reference: %+v", ref)
+	}
+}
+
+func putConfigMap(t *testing.T, body string) (*httptest.ResponseRecorder, ConfigMapUpdateResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	ConfigMapActionsHandler(w, httptest.NewRequest(http.MethodPut, "/api/configmaps/default/settings", strings.NewReader(body)))
+	var resp ConfigMapUpdateResponse
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	return w, resp
+}
+
+func TestUpdateConfigMap_RestartsConsumers(t *testing.T) {
+	d, sts, ds := newConfigMapConsumers()
+	cs := fake.NewSimpleClientset(newTestConfigMap(), d, sts, ds)
+	withConfigMapClientset(t, cs)
+
+	w, resp := putConfigMap(t, `{"data":{"LOG_LEVEL":"debug"},"binaryData":{"logo.png":"iVBORw=="},"restartConsumers":true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !resp.Changed || strings.Join(resp.Restarted, ",") != "StatefulSet/db,Deployment/web" || len(resp.RestartFailed) != 0 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	cm, _ := cs.CoreV1().ConfigMaps("default").Get(context.Background(), "settings", metav1.GetOptions{})
+	if len(cm.Data) != 1 || cm.Data["LOG_LEVEL"] != "debug" || string(cm.BinaryData["logo.png"]) != "\x89PNG" {
+		t.Errorf("configmap not replaced: %+v", cm)
+	}
+	web, _ := cs.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	if web.Spec.Template.Annotations[annotationRestartedAt] == "" {
+		t.Error("consumer deployment should be restarted")
+	}
+	agent, _ := cs.AppsV1().DaemonSets("default").Get(context.Background(), "agent", metav1.GetOptions{})
+	if agent.Spec.Template.Annotations[annotationRestartedAt] != "" {
+		t.Error("unrelated daemonset should not be restarted")
+	}
+}
+
+func TestUpdateConfigMap_KeepsOmittedBinaryData(t *testing.T) {
+	cs := fake.NewSimpleClientset(newTestConfigMap())
+	withConfigMapClientset(t, cs)
+
+	w, resp := putConfigMap(t, `{"data":{"LOG_LEVEL":"debug"}}`)
+	if w.Code != http.StatusOK || !resp.Changed {
+		t.Fatalf("expected a change, got %d: %s", w.Code, w.Body.String())
+	}
+	cm, _ := cs.CoreV1().ConfigMaps("default").Get(context.Background(), "settings", metav1.GetOptions{})
+	if cm.Data["LOG_LEVEL"] != "debug" || string(cm.BinaryData["logo.png"]) != "\x89PNG" {
+		t.Errorf("binary data should be kept when omitted: %+v", cm)
+	}
+}
+
+func TestUpdateConfigMap_AcceptsDetailShape(t *testing.T) {
+	withConfigMapClientset(t, fake.NewSimpleClientset(newTestConfigMap()))
+
+	// Send back the detail exactly as GET returned it.
+	w := httptest.NewRecorder()
+	ConfigMapActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/configmaps/default/settings", nil))
+	w, resp := putConfigMap(t, w.Body.String())
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if resp.Changed {
+		t.Errorf("an unmodified round trip should not change anything: %+v", resp)
+	}
+}
+
+func TestUpdateConfigMap_Unchanged(t *testing.T) {
+	d, _, _ := newConfigMapConsumers()
+	cs := fake.NewSimpleClientset(newTestConfigMap(), d)
+	withConfigMapClientset(t, cs)
+
+	w, resp := putConfigMap(t, `{"data":{"LOG_LEVEL":"info","app.yaml":"port: 8080\n"},"binaryData":{"logo.png":"iVBORw=="},"restartConsumers":true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if resp.Changed || len(resp.Restarted) != 0 || resp.Message != "No changes" {
+		t.Errorf("unchanged edit should not restart consumers: %+v", resp)
+	}
+}
+
+func TestUpdateConfigMap_Rejected(t *testing.T) {
+	immutable := true
+	cm := newTestConfigMap()
+	cm.Immutable = &immutable
+
+	tests := []struct {
+		name     string
+		objects  []*corev1.ConfigMap
+		body     string
+		wantCode int
+	}{
+		{name: "invalid body", objects: []*corev1.ConfigMap{newTestConfigMap()}, body: `{`, wantCode: http.StatusBadRequest},
+		{name: "not found", body: `{"data":{}}`, wantCode: http.StatusNotFound},
+		{name: "immutable", objects: []*corev1.ConfigMap{cm}, body: `{"data":{}}`, wantCode: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset()
+			for _, obj := range tt.objects {
+				cs.Tracker().Add(obj) //nolint:errcheck
+			}
+			withConfigMapClientset(t, cs)
+
+			if w, _ := putConfigMap(t, tt.body); w.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -7,19 +7,68 @@ const (
 	podLogsPathPrefix     = "/api/pods/logs/"
 	podExecPathPrefix     = "/api/pods/exec/"
 	podDebugPathPrefix    = "/api/pods/debug/"
+	podPortForwardPathPrefix     = "/api/pods/portforward/"
+	podProxyPathPrefix           = "/api/pods/proxy/"
+	servicePortForwardPathPrefix = "/api/services/portforward/"
+	serviceProxyPathPrefix       = "/api/services/proxy/"
+	podFilesPathPrefix           = "/api/pods/files/"
+	debugCopiesPathPrefix        = "/api/pods/debug-copies/"
+	nodesPathPrefix              = "/api/nodes/"
+	podEphemeralPathPrefix       = "/api/pods/ephemeral/"
+	podDetailPathPrefix          = "/api/pods/detail/"
+	podHistoryPathPrefix         = "/api/pods/history/"
+	namespacesPathPrefix         = "/api/namespaces/"
+	statefulSetsPathPrefix       = "/api/statefulsets/"
+	daemonSetsPathPrefix         = "/api/daemonsets/"
+	jobsPathPrefix               = "/api/jobs/"
+	cronJobsPathPrefix           = "/api/cronjobs/"
+	resourcesPathPrefix          = "/api/resources/"
+	servicesPathPrefix           = "/api/services/"
+	ingressesPathPrefix          = "/api/ingresses/"
+	configMapsPathPrefix         = "/api/configmaps/"
 	restartPathSuffix     = "/restart"
+	scalePathSuffix       = "/scale"
+	rollbackPathSuffix    = "/rollback"
+	rolloutStatusPathSuffix = "/rollout-status"
+	pausePathSuffix         = "/pause"
+	imagePathSuffix         = "/image"
+	triggerPathSuffix       = "/trigger"
+	consumersPathSuffix     = "/consumers"
 	reconcilePathSuffix    = "/reconcile"
 	updateBranchPathSuffix = "/update-branch"
 	branchesPathSuffix     = "/branches"
 	suspendPathSuffix      = "/suspend"
 	resumePathSuffix       = "/resume"
 	resubmitPathSuffix     = "/resubmit"
+	downloadPathSuffix     = "/download"
+	uploadPathSuffix       = "/upload"
+	debugPathSuffix        = "/debug"
+	historyPathSuffix      = "/history"
+	cordonPathSuffix       = "/cordon"
+	uncordonPathSuffix     = "/uncordon"
+	drainPathSuffix        = "/drain"
 )
 
 // Kubernetes annotation keys.
 const (
 	annotationRestartedAt          = "kubectl.kubernetes.io/restartedAt"
 	annotationReconcileRequestedAt = "reconcile.fluxcd.io/requestedAt"
+	// annotationPreviousReplicas records a workload's replica count before it was scaled to zero.
+	annotationPreviousReplicas = "kubernetes-dashboard.dlddu.io/previous-replicas"
+	// annotationDebugCopyOf names the source pod of a debug copy.
+	annotationDebugCopyOf = "kubernetes-dashboard.dlddu.io/debug-copy-of"
+	// annotationNodeDebugNode and annotationNodeDebugExpiresAt record the target
+	// node and expiry (RFC 3339) of a node debug pod.
+	annotationNodeDebugNode      = "kubernetes-dashboard.dlddu.io/node-debug-node"
+	annotationNodeDebugExpiresAt = "kubernetes-dashboard.dlddu.io/node-debug-expires-at"
+)
+
+// Labels applied to objects created by the dashboard.
+const (
+	// labelDebugCopy marks a pod created by debug copy mode.
+	labelDebugCopy = "kubernetes-dashboard.dlddu.io/debug-copy"
+	// labelNodeDebug marks a privileged pod created to debug a node.
+	labelNodeDebug = "kubernetes-dashboard.dlddu.io/node-debug"
 )
 
 // Node role label keys.
@@ -46,7 +95,65 @@ const (
 	errMsgSecretFetch     = "Failed to fetch secret detail"
 	errMsgSecretDelete    = "Failed to delete secret"
 
-	errMsgDeploymentNotFound = "Deployment not found"
+	errMsgDeploymentNotFound    = "Deployment not found"
+	errMsgDeploymentScale       = "Failed to scale deployment"
+	errMsgScaleInvalidRequest   = "Request must set either a non-negative replicas or action \"restore\""
+	errMsgScaleNothingToRestore = "No previous replica count is recorded for this deployment"
+	errMsgScaleNotAtZero        = "Deployment is not scaled to zero; only a scaled-to-zero deployment can be restored"
+	errMsgDeploymentHistory     = "Failed to fetch deployment history"
+	errMsgDeploymentRollback    = "Failed to roll back deployment"
+	errMsgRevisionInvalid       = "revision must be a non-negative integer"
+	errMsgRollbackPaused        = "Cannot roll back a paused deployment; resume it first"
+	errMsgRollbackNoPrevious    = "No previous revision to roll back to"
+	errMsgRolloutStatus         = "Failed to fetch rollout status"
+	errMsgGenerationInvalid     = "generation must be a non-negative integer"
+	errMsgDeploymentPause       = "Failed to pause or resume deployment"
+	errMsgStatefulSetNotFound   = "StatefulSet not found"
+	errMsgDaemonSetNotFound     = "DaemonSet not found"
+	errMsgWorkloadDetail        = "Failed to fetch workload detail"
+	errMsgWorkloadRestart       = "Failed to restart workload"
+	errMsgImageUpdate           = "Failed to update container images"
+	errMsgImageInvalidRequest   = "Request must map at least one container name to a non-empty image"
+
+	errMsgJobNotFound     = "Job not found"
+	errMsgJobFetch        = "Failed to fetch job detail"
+	errMsgJobDelete       = "Failed to delete job"
+	errMsgCronJobNotFound = "CronJob not found"
+	errMsgCronJobFetch    = "Failed to fetch cronjob detail"
+	errMsgCronJobTrigger  = "Failed to create job from cronjob"
+	errMsgCronJobSuspend  = "Failed to suspend or resume cronjob"
+
+	errMsgConfigMapNotFound       = "ConfigMap not found"
+	errMsgConfigMapFetch          = "Failed to fetch configmap detail"
+	errMsgConfigMapUpdate         = "Failed to update configmap"
+	errMsgConfigMapConsumers      = "Failed to find configmap consumers"
+	errMsgConfigMapImmutable      = "ConfigMap is immutable and cannot be edited"
+	errMsgConfigMapInvalidRequest = "Invalid request body. Expected data and binaryData maps"
+
+	errMsgResourcePathInvalid    = "Invalid path format. Expected /api/resources/{group}/{version}/{resource}[/{namespace}/{name}]"
+	errMsgResourceFetch          = "Failed to fetch resource"
+	errMsgResourceList           = "Failed to list resources"
+	errMsgAPIResourcesFetch      = "Failed to discover API resources"
+	errMsgListLimitInvalid       = "limit must be a non-negative integer"
+	errMsgResourceApply          = "Failed to apply resource"
+	errMsgResourceApplyForbidden = "The dashboard's service account is not allowed to patch this resource, which editing requires"
+	errMsgResourceTooLarge       = "Request body is too large"
+
+	errMsgEventsFetch      = "Failed to fetch events"
+	errMsgEventTypeInvalid = "type must be Normal or Warning"
+
+	errMsgNamespaceNotFound = "Namespace not found"
+	errMsgNamespaceFetch    = "Failed to fetch namespace detail"
+
+	errMsgNodeNotFound        = "Node not found"
+	errMsgNodeDetailFetch     = "Failed to fetch node detail"
+	errMsgNodeCordon          = "Failed to update node schedulability"
+	errMsgNodeDrain           = "Failed to drain node"
+	errMsgNodeDrainInvalidReq = "Invalid drain request"
+	errMsgNodeDrainRunning    = "A drain is already running for this node"
+	errMsgNodeDrainNotRunning = "No drain is running for this node"
+	errMsgNodeDebugFailed     = "Failed to create node debug pod"
+	errMsgNodeDebugNotReady   = "Node debug pod did not become ready in time"
 
 	errMsgPodNotFound       = "Pod not found"
 	errMsgPodLogsFetch     = "Failed to fetch pod logs"
@@ -55,6 +162,16 @@ const (
 	errMsgPodExecFailed    = "Failed to exec into pod"
 	errMsgPodExecUpgrade   = "Failed to upgrade to WebSocket"
 	errMsgContainerRequired = "Container name is required"
+	errMsgExecModeInvalid        = "Mode must be exec or attach"
+	errMsgContainerNotAttachable = "Container is not running with a TTY and stdin, so it cannot be attached to"
+	errMsgPodEphemeralList       = "Failed to list ephemeral containers"
+	errMsgPodDetailFetch         = "Failed to fetch pod detail"
+	errMsgMetricsUnavailable     = "metrics-server is not available"
+	errMsgTopPods                = "Failed to compute top pods"
+	errMsgTopSortInvalid         = "sortBy must be one of cpu, memory, cpuLimitRatio, memoryLimitRatio"
+	errMsgTopLimitInvalid        = "limit must be a non-negative integer"
+	errMsgHistoryDisabled        = "Metrics history is disabled"
+	errMsgHistoryNotFound        = "No metrics history recorded for this resource"
 
 	errMsgPodDebugFailed     = "Failed to add ephemeral container"
 	errMsgPodDebugForbidden  = "Ephemeral containers not supported by the API server"
@@ -63,6 +180,30 @@ const (
 	errMsgPodDebugNameTaken  = "Container name already in use"
 	errMsgPodDebugNotReady   = "Ephemeral container did not become ready in time"
 	errMsgPodDebugImagePull  = "Ephemeral container image pull failed"
+	errMsgPodDebugImageDenied = "Image is not in the debug image allowlist"
+	errMsgDebugProfilesLoad   = "Failed to load debug profiles"
+	errMsgPodDebugCopyFailed  = "Failed to create debug copy of pod"
+	errMsgPodDebugCopyNotReady = "Debug copy did not become ready in time"
+	errMsgPodDebugCopyNotFound = "Debug copy not found"
+	errMsgPodDebugCopyDelete   = "Failed to delete debug copy"
+	errMsgPodDebugCopyList     = "Failed to list debug copies"
+
+	errMsgPortRequired        = "Port is required"
+	errMsgPortInvalid         = "Port must be a number between 1 and 65535 or a named container port"
+	errMsgPortForwardFailed   = "Failed to port-forward to pod"
+	errMsgOriginForbidden     = "Cross-origin requests are not allowed"
+	errMsgPodNotRunning       = "Pod is not running"
+	errMsgServiceNotFound     = "Service not found"
+	errMsgServiceNoReadyPods  = "Service has no ready pods to forward to"
+	errMsgServicePortNotFound = "Port not found on service"
+	errMsgServiceFetch        = "Failed to fetch service detail"
+	errMsgIngressNotFound     = "Ingress not found"
+	errMsgIngressFetch        = "Failed to fetch ingress detail"
+
+	errMsgFilePathInvalid    = "Path query parameter must be an absolute path"
+	errMsgFileNotFound       = "File or directory not found in container"
+	errMsgFileTooLarge       = "File transfer exceeds the size limit"
+	errMsgFileTransferFailed = "Failed to transfer files to or from container"
 
 	errMsgWorkflowNotFound  = "Workflow not found"
 	errMsgWorkflowDelete    = "Failed to delete workflow"
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+)
+
+// annotationCronJobInstantiate marks a job created from a CronJob by hand,
+// matching `kubectl create job --from=cronjob/...`.
+const annotationCronJobInstantiate = "cronjob.kubernetes.io/instantiate"
+
+// maxCronJobNameLength keeps job names created from a CronJob within the
+// length the CronJob controller itself allows for job names.
+const maxCronJobNameLength = 52
+
+// manualJobSuffix returns the random part of the name of a job created from a
+// CronJob by hand. Tests may override this.
+var manualJobSuffix = func() string {
+	return utilrand.String(5)
+}
+
+// CronJobInfo represents summary information about a cronjob
+type CronJobInfo struct {
+	Name               string     `json:"name"`
+	Namespace          string     `json:"namespace"`
+	Schedule           string     `json:"schedule"`
+	TimeZone           string     `json:"timeZone,omitempty"`
+	Suspend            bool       `json:"suspend"`
+	Active             int        `json:"active"`
+	LastScheduleTime   *time.Time `json:"lastScheduleTime,omitempty"`
+	LastSuccessfulTime *time.Time `json:"lastSuccessfulTime,omitempty"`
+	Age                string     `json:"age"`
+}
+
+// CronJobDetail is the response of GET /api/cronjobs/{ns}/{name}.
+type CronJobDetail struct {
+	CronJobInfo
+	Labels                     map[string]string `json:"labels"`
+	ConcurrencyPolicy          string            `json:"concurrencyPolicy"`
+	StartingDeadlineSeconds    *int64            `json:"startingDeadlineSeconds,omitempty"`
+	SuccessfulJobsHistoryLimit *int32            `json:"successfulJobsHistoryLimit,omitempty"`
+	FailedJobsHistoryLimit     *int32            `json:"failedJobsHistoryLimit,omitempty"`
+	Images                     []string          `json:"images"`
+	// Jobs are the jobs owned by the CronJob, newest first.
+	Jobs []JobInfo `json:"jobs"`
+}
+
+// CronJobsHandler handles the GET /api/cronjobs endpoint
+var CronJobsHandler = handleGet("Failed to fetch cronjobs data", func(r *http.Request) (interface{}, error) {
+	clientset, err := getJobClientset()
+	if err != nil {
+		return nil, err
+	}
+	return getCronJobsData(r.Context(), clientset, r.URL.Query().Get("ns"))
+})
+
+func getCronJobsData(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]CronJobInfo, error) {
+	list, err := clientset.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	cronJobs := make([]CronJobInfo, 0, len(list.Items))
+	for i := range list.Items {
+		cronJobs = append(cronJobs, buildCronJobInfo(&list.Items[i]))
+	}
+	return cronJobs, nil
+}
+
+// CronJobActionsHandler handles GET /api/cronjobs/{ns}/{name} and the
+// /api/cronjobs/{ns}/{name}/{trigger,suspend,resume} endpoints.
+func CronJobActionsHandler(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, triggerPathSuffix):
+		handleCronJobTrigger(w, r)
+	case strings.HasSuffix(r.URL.Path, suspendPathSuffix):
+		handleCronJobSuspend(w, r, suspendPathSuffix, true)
+	case strings.HasSuffix(r.URL.Path, resumePathSuffix):
+		handleCronJobSuspend(w, r, resumePathSuffix, false)
+	case strings.Count(strings.TrimPrefix(r.URL.Path, cronJobsPathPrefix), "/") == 1:
+		handleGetCronJobDetail(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "Unknown cronjob action")
+	}
+}
+
+func handleGetCronJobDetail(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, clientset := parseJobPath(w, r, cronJobsPathPrefix, "")
+	if clientset == nil {
+		return
+	}
+
+	detail, err := getCronJobDetail(r.Context(), clientset, namespace, name)
+	if err != nil {
+		writeResourceError(w, err, errMsgCronJobNotFound, errMsgCronJobFetch)
+		return
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// handleCronJobTrigger handles POST /api/cronjobs/{ns}/{name}/trigger by creating
+// a job from the CronJob's jobTemplate, as `kubectl create job --from` does.
+func handleCronJobTrigger(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, clientset := parseJobPath(w, r, cronJobsPathPrefix, triggerPathSuffix)
+	if clientset == nil {
+		return
+	}
+
+	cronJob, err := clientset.BatchV1().CronJobs(namespace).Get(r.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		writeResourceError(w, err, errMsgCronJobNotFound, errMsgCronJobTrigger)
+		return
+	}
+
+	job, err := clientset.BatchV1().Jobs(namespace).Create(r.Context(), jobFromCronJob(cronJob, manualJobSuffix()), metav1.CreateOptions{})
+	if err != nil {
+		writeAPIError(w, err, errMsgCronJobTrigger)
+		return
+	}
+	writeJSON(w, http.StatusOK, buildJobInfo(job, time.Now()))
+}
+
+// jobFromCronJob builds a job from the CronJob's jobTemplate, owned by the CronJob
+// so it is listed and garbage-collected with the scheduled ones.
+func jobFromCronJob(cronJob *batchv1.CronJob, suffix string) *batchv1.Job {
+	suffix = "-manual-" + suffix
+	base := cronJob.Name
+	if len(base)+len(suffix) > maxCronJobNameLength {
+		base = strings.TrimRight(base[:maxCronJobNameLength-len(suffix)], "-.")
+	}
+
+	labels := make(map[string]string, len(cronJob.Spec.JobTemplate.Labels))
+	for k, v := range cronJob.Spec.JobTemplate.Labels {
+		labels[k] = v
+	}
+	annotations := map[string]string{annotationCronJobInstantiate: "manual"}
+	for k, v := range cronJob.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            base + suffix,
+			Namespace:       cronJob.Namespace,
+			Labels:          labels,
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cronJob, batchv1.SchemeGroupVersion.WithKind("CronJob"))},
+		},
+		Spec: *cronJob.Spec.JobTemplate.Spec.DeepCopy(),
+	}
+}
+
+// handleCronJobSuspend handles POST /api/cronjobs/{ns}/{name}/suspend and /resume.
+func handleCronJobSuspend(w http.ResponseWriter, r *http.Request, suffix string, suspend bool) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, clientset := parseJobPath(w, r, cronJobsPathPrefix, suffix)
+	if clientset == nil {
+		return
+	}
+
+	patch := fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspend)
+	cronJob, err := clientset.BatchV1().CronJobs(namespace).Patch(r.Context(), name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		writeResourceError(w, err, errMsgCronJobNotFound, errMsgCronJobSuspend)
+		return
+	}
+
+	message := "CronJob resumed"
+	if suspend {
+		message = "CronJob suspended"
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": message,
+		"suspend": cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+	})
+}
+
+func getCronJobDetail(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*CronJobDetail, error) {
+	cronJob, err := clientset.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	jobList, err := clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	jobs := make([]JobInfo, 0)
+	for i := range jobList.Items {
+		if owner := metav1.GetControllerOf(&jobList.Items[i]); owner != nil && owner.UID == cronJob.UID {
+			jobs = append(jobs, buildJobInfo(&jobList.Items[i], now))
+		}
+	}
+	sortJobsNewestFirst(jobs)
+
+	return &CronJobDetail{
+		CronJobInfo:                buildCronJobInfo(cronJob),
+		Labels:                     cronJob.Labels,
+		ConcurrencyPolicy:          string(cronJob.Spec.ConcurrencyPolicy),
+		StartingDeadlineSeconds:    cronJob.Spec.StartingDeadlineSeconds,
+		SuccessfulJobsHistoryLimit: cronJob.Spec.SuccessfulJobsHistoryLimit,
+		FailedJobsHistoryLimit:     cronJob.Spec.FailedJobsHistoryLimit,
+		Images:                     templateImages(cronJob.Spec.JobTemplate.Spec.Template),
+		Jobs:                       jobs,
+	}, nil
+}
+
+func buildCronJobInfo(cronJob *batchv1.CronJob) CronJobInfo {
+	info := CronJobInfo{
+		Name:      cronJob.Name,
+		Namespace: cronJob.Namespace,
+		Schedule:  cronJob.Spec.Schedule,
+		Suspend:   cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+		Active:    len(cronJob.Status.Active),
+		Age:       formatPodAge(cronJob.CreationTimestamp.Time),
+	}
+	if cronJob.Spec.TimeZone != nil {
+		info.TimeZone = *cronJob.Spec.TimeZone
+	}
+	if t := cronJob.Status.LastScheduleTime; t != nil {
+		info.LastScheduleTime = &t.Time
+	}
+	if t := cronJob.Status.LastSuccessfulTime; t != nil {
+		info.LastSuccessfulTime = &t.Time
+	}
+	return info
+}
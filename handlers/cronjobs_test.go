@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestCronJob(name string) *batchv1.CronJob {
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name + "-uid")},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          "0 3 * * *",
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "batch"}},
+				Spec:       batchv1.JobSpec{Template: podTemplate("batch:1")},
+			},
+		},
+	}
+}
+
+func TestCronJobDetail(t *testing.T) {
+	cj := newTestCronJob("nightly")
+	now := time.Now()
+	withJobClientset(t, fake.NewSimpleClientset(cj,
+		newTestJob("nightly-1", now.Add(-48*time.Hour), cj),
+		newTestJob("nightly-2", now.Add(-24*time.Hour), cj),
+		newTestJob("unrelated", now, nil),
+	))
+
+	w := httptest.NewRecorder()
+	CronJobActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/cronjobs/default/nightly", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var detail CronJobDetail
+	if err := json.NewDecoder(w.Body).Decode(&detail); err != nil {
+		t.Fatal(err)
+	}
+	if detail.Schedule != "0 3 * * *" || detail.ConcurrencyPolicy != "Forbid" {
+		t.Errorf("unexpected detail: %+v", detail)
+	}
+	if len(detail.Jobs) != 2 || detail.Jobs[0].Name != "nightly-2" {
+		t.Errorf("expected the owned jobs newest first, got %+v", detail.Jobs)
+	}
+}
+
+func TestCronJobTrigger(t *testing.T) {
+	cs := fake.NewSimpleClientset(newTestCronJob("nightly"), newTestCronJob(strings.Repeat("a", 52)))
+	withJobClientset(t, cs)
+
+	w := httptest.NewRecorder()
+	CronJobActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/cronjobs/default/nightly/trigger", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var info JobInfo
+	json.NewDecoder(w.Body).Decode(&info) //nolint:errcheck
+	if !strings.HasPrefix(info.Name, "nightly-manual-") || info.CronJob != "nightly" {
+		t.Errorf("unexpected job: %+v", info)
+	}
+
+	job, err := cs.BatchV1().Jobs("default").Get(context.Background(), info.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Annotations[annotationCronJobInstantiate] != "manual" || job.Labels["team"] != "batch" {
+		t.Errorf("job metadata not taken from the jobTemplate: %v %v", job.Labels, job.Annotations)
+	}
+	if job.Spec.Template.Spec.Containers[0].Image != "batch:1" {
+		t.Errorf("job spec not taken from the jobTemplate: %+v", job.Spec)
+	}
+
+	long := jobFromCronJob(newTestCronJob(strings.Repeat("a", 52)), "x7k2q")
+	if len(long.Name) > maxCronJobNameLength || !strings.HasSuffix(long.Name, "-manual-x7k2q") {
+		t.Errorf("job name %q should end in -manual-x7k2q within %d characters", long.Name, maxCronJobNameLength)
+	}
+
+	cj := newTestCronJob("nightly")
+	jobFromCronJob(cj, "x7k2q").Labels["extra"] = "x"
+	if _, shared := cj.Spec.JobTemplate.Labels["extra"]; shared {
+		t.Error("job labels must not share the jobTemplate's map")
+	}
+
+	w = httptest.NewRecorder()
+	CronJobActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/cronjobs/default/missing/trigger", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing cronjob: expected 404, got %d", w.Code)
+	}
+}
+
+func TestCronJobTrigger_NameTaken(t *testing.T) {
+	old := manualJobSuffix
+	manualJobSuffix = func() string { return "x7k2q" }
+	t.Cleanup(func() { manualJobSuffix = old })
+	withJobClientset(t, fake.NewSimpleClientset(newTestCronJob("nightly"), newTestJob("nightly-manual-x7k2q", time.Now(), nil)))
+
+	w := httptest.NewRecorder()
+	CronJobActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/cronjobs/default/nightly/trigger", nil))
+	if w.Code != http.StatusConflict || strings.Contains(w.Body.String(), errMsgCronJobNotFound) {
+		t.Errorf("expected 409 with the API message, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCronJobSuspendResume(t *testing.T) {
+	cs := fake.NewSimpleClientset(newTestCronJob("nightly"))
+	withJobClientset(t, cs)
+
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"/api/cronjobs/default/nightly/suspend", true},
+		{"/api/cronjobs/default/nightly/resume", false},
+	} {
+		w := httptest.NewRecorder()
+		CronJobActionsHandler(w, httptest.NewRequest(http.MethodPost, tc.path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", tc.path, w.Code, w.Body.String())
+		}
+		cj, _ := cs.BatchV1().CronJobs("default").Get(context.Background(), "nightly", metav1.GetOptions{})
+		if cj.Spec.Suspend == nil || *cj.Spec.Suspend != tc.want {
+			t.Errorf("%s: suspend = %v", tc.path, cj.Spec.Suspend)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	CronJobActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/cronjobs/default/nightly/suspend", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET suspend: expected 405, got %d", w.Code)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DaemonSetInfo represents summary information about a daemonset
+type DaemonSetInfo struct {
+	Name                   string `json:"name"`
+	Namespace              string `json:"namespace"`
+	DesiredNumberScheduled int32  `json:"desiredNumberScheduled"`
+	CurrentNumberScheduled int32  `json:"currentNumberScheduled"`
+	NumberReady            int32  `json:"numberReady"`
+	UpdatedNumberScheduled int32  `json:"updatedNumberScheduled"`
+	NumberAvailable        int32  `json:"numberAvailable"`
+	// NumberMisscheduled counts daemon pods running on nodes they should not run on.
+	NumberMisscheduled int32 `json:"numberMisscheduled"`
+}
+
+// DaemonSetDetail is the response of GET /api/daemonsets/{ns}/{name}.
+type DaemonSetDetail struct {
+	DaemonSetInfo
+	Labels         map[string]string   `json:"labels"`
+	Selector       map[string]string   `json:"selector"`
+	NodeSelector   map[string]string   `json:"nodeSelector,omitempty"`
+	UpdateStrategy string              `json:"updateStrategy"`
+	MaxUnavailable string              `json:"maxUnavailable,omitempty"`
+	Images         []string            `json:"images"`
+	Conditions     []WorkloadCondition `json:"conditions"`
+	CreatedAt      time.Time           `json:"createdAt"`
+	Pods           []PodDetails        `json:"pods"`
+}
+
+// DaemonSetsHandler handles the GET /api/daemonsets endpoint
+var DaemonSetsHandler = handleGet("Failed to fetch daemonsets data", func(r *http.Request) (interface{}, error) {
+	clientset, err := getDeploymentClientset()
+	if err != nil {
+		return nil, err
+	}
+	return getDaemonSetsData(r.Context(), clientset, r.URL.Query().Get("ns"))
+})
+
+func getDaemonSetsData(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]DaemonSetInfo, error) {
+	list, err := clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	daemonSets := make([]DaemonSetInfo, 0, len(list.Items))
+	for i := range list.Items {
+		daemonSets = append(daemonSets, buildDaemonSetInfo(&list.Items[i]))
+	}
+	return daemonSets, nil
+}
+
+func buildDaemonSetInfo(ds *appsv1.DaemonSet) DaemonSetInfo {
+	return DaemonSetInfo{
+		Name:                   ds.Name,
+		Namespace:              ds.Namespace,
+		DesiredNumberScheduled: ds.Status.DesiredNumberScheduled,
+		CurrentNumberScheduled: ds.Status.CurrentNumberScheduled,
+		NumberReady:            ds.Status.NumberReady,
+		UpdatedNumberScheduled: ds.Status.UpdatedNumberScheduled,
+		NumberAvailable:        ds.Status.NumberAvailable,
+		NumberMisscheduled:     ds.Status.NumberMisscheduled,
+	}
+}
+
+func getDaemonSetDetail(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (interface{}, error) {
+	ds, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := listWorkloadPods(ctx, clientset, &workload{ObjectMeta: ds.ObjectMeta, Selector: ds.Spec.Selector})
+	if err != nil {
+		return nil, err
+	}
+
+	detail := DaemonSetDetail{
+		DaemonSetInfo:  buildDaemonSetInfo(ds),
+		Labels:         ds.Labels,
+		NodeSelector:   ds.Spec.Template.Spec.NodeSelector,
+		UpdateStrategy: string(ds.Spec.UpdateStrategy.Type),
+		Images:         templateImages(ds.Spec.Template),
+		Conditions:     make([]WorkloadCondition, 0, len(ds.Status.Conditions)),
+		CreatedAt:      ds.CreationTimestamp.Time,
+		Pods:           pods,
+	}
+	if ds.Spec.Selector != nil {
+		detail.Selector = ds.Spec.Selector.MatchLabels
+	}
+	if ru := ds.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.MaxUnavailable != nil {
+		detail.MaxUnavailable = ru.MaxUnavailable.String()
+	}
+	for _, c := range ds.Status.Conditions {
+		detail.Conditions = append(detail.Conditions, WorkloadCondition{
+			Type:               string(c.Type),
+			Status:             string(c.Status),
+			Reason:             c.Reason,
+			Message:            c.Message,
+			LastTransitionTime: c.LastTransitionTime.Time,
+		})
+	}
+	return detail, nil
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestDaemonSet() *appsv1.DaemonSet {
+	maxUnavailable := intstr.FromString("10%")
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "agent", UID: types.UID("agent-uid")},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: podTemplate("agent:1"),
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable},
+			},
+		},
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 3, CurrentNumberScheduled: 3, NumberReady: 2,
+			UpdatedNumberScheduled: 3, NumberAvailable: 2, NumberMisscheduled: 1,
+		},
+	}
+}
+
+func TestDaemonSetsHandler(t *testing.T) {
+	withDeploymentClientset(t, fake.NewSimpleClientset(newTestDaemonSet()))
+
+	w := httptest.NewRecorder()
+	DaemonSetsHandler(w, httptest.NewRequest(http.MethodGet, "/api/daemonsets", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var list []DaemonSetInfo
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].DesiredNumberScheduled != 3 || list[0].NumberReady != 2 || list[0].NumberMisscheduled != 1 {
+		t.Errorf("unexpected list: %+v", list)
+	}
+}
+
+func TestDaemonSetDetailAndRestart(t *testing.T) {
+	ds := newTestDaemonSet()
+	cs := fake.NewSimpleClientset(ds, newControlledPod("agent-x1", "DaemonSet", ds.UID))
+	withDeploymentClientset(t, cs)
+
+	w := httptest.NewRecorder()
+	DaemonSetActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/daemonsets/default/agent", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var detail DaemonSetDetail
+	if err := json.NewDecoder(w.Body).Decode(&detail); err != nil {
+		t.Fatal(err)
+	}
+	if detail.UpdateStrategy != "RollingUpdate" || detail.MaxUnavailable != "10%" || len(detail.Pods) != 1 {
+		t.Errorf("unexpected detail: %+v", detail)
+	}
+
+	w = httptest.NewRecorder()
+	DaemonSetActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/daemonsets/default/agent/restart", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("restart: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	got, _ := cs.AppsV1().DaemonSets("default").Get(context.Background(), "agent", metav1.GetOptions{})
+	if got.Spec.Template.Annotations[annotationRestartedAt] == "" {
+		t.Error("restartedAt annotation not set on the pod template")
+	}
+
+	w = httptest.NewRecorder()
+	DaemonSetActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/daemonsets/default/missing/restart", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing daemonset: expected 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// debugProfilesConfigEnv names the environment variable pointing at an optional
+// YAML file that overrides or extends the built-in debug profiles.
+const debugProfilesConfigEnv = "DEBUG_PROFILES_CONFIG"
+
+// debugAllowAnyImageEnv opts out of the default image allowlist when set to
+// true, like allowAnyImage in the config file. Without an allowlist or this
+// opt-out, only defaultDebugImages may be used, since debug containers, and
+// node debug pods in particular, may run privileged.
+const debugAllowAnyImageEnv = "DEBUG_ALLOW_ANY_IMAGE"
+
+// debugProfileDefault is used when a request does not name a profile. baseline
+// sets no security context, so capabilities are only added when asked for, as
+// before profiles existed.
+const debugProfileDefault = "baseline"
+
+// defaultDebugImages are the repositories allowed when no allowlist is
+// configured: the images DebugPodDialog offers as presets. The node debug image
+// is added at load time.
+var defaultDebugImages = []string{
+	"busybox",
+	"nicolaka/netshoot",
+	"alpine",
+	"ubuntu",
+	"praqma/network-multitool",
+	"curlimages/curl",
+}
+
+// debugProfile describes how an ephemeral debug container is secured, in the
+// spirit of `kubectl debug --profile`.
+type debugProfile struct {
+	Name         string `json:"name" yaml:"name"`
+	Description  string `json:"description" yaml:"description"`
+	DefaultImage string `json:"defaultImage,omitempty" yaml:"defaultImage"`
+	// AllowedImages restricts which images may be used with this profile.
+	// Entries are path.Match patterns; an entry without a tag also matches any
+	// tag or digest of that repository. Empty falls back to the global allowlist.
+	AllowedImages  []string `json:"allowedImages,omitempty" yaml:"allowedImages"`
+	AddCaps        []string `json:"addCapabilities,omitempty" yaml:"addCapabilities"`
+	DropCaps       []string `json:"dropCapabilities,omitempty" yaml:"dropCapabilities"`
+	Privileged     bool     `json:"privileged,omitempty" yaml:"privileged"`
+	RunAsNonRoot   bool     `json:"runAsNonRoot,omitempty" yaml:"runAsNonRoot"`
+	SeccompDefault bool     `json:"seccompRuntimeDefault,omitempty" yaml:"seccompRuntimeDefault"`
+	// NoEscalation sets allowPrivilegeEscalation=false and rejects the
+	// AllowPtrace/AllowSysAdmin request flags.
+	NoEscalation bool `json:"noPrivilegeEscalation,omitempty" yaml:"noPrivilegeEscalation"`
+}
+
+// debugProfilesConfig is the on-disk format read from DEBUG_PROFILES_CONFIG.
+// Without allowedImages, only defaultDebugImages and the node debug image are
+// allowed unless allowAnyImage is set.
+type debugProfilesConfig struct {
+	DefaultProfile string         `yaml:"defaultProfile"`
+	AllowedImages  []string       `yaml:"allowedImages"`
+	AllowAnyImage  bool           `yaml:"allowAnyImage"`
+	Profiles       []debugProfile `yaml:"profiles"`
+}
+
+// debugProfileSet is the resolved set of profiles served by the API.
+type debugProfileSet struct {
+	DefaultProfile string         `json:"defaultProfile"`
+	AllowedImages  []string       `json:"allowedImages,omitempty"`
+	AllowAnyImage  bool           `json:"allowAnyImage,omitempty"`
+	Profiles       []debugProfile `json:"profiles"`
+}
+
+// builtinDebugProfiles mirrors the profiles offered by kubectl debug.
+var builtinDebugProfiles = []debugProfile{
+	{
+		Name:        "general",
+		Description: "Adds SYS_PTRACE so the shell can inspect processes of the target container",
+		AddCaps:     []string{"SYS_PTRACE"},
+	},
+	{
+		Name:        "baseline",
+		Description: "Runs with the container runtime defaults; only the capabilities requested explicitly",
+	},
+	{
+		Name:        "netadmin",
+		Description: "Adds NET_ADMIN and NET_RAW for tcpdump, iptables and ip route",
+		AddCaps:     []string{"NET_ADMIN", "NET_RAW"},
+	},
+	{
+		Name:        "sysadmin",
+		Description: "Runs privileged for full access to the node's kernel interfaces",
+		Privileged:  true,
+	},
+	{
+		Name:           "restricted",
+		Description:    "Satisfies the restricted Pod Security Standard: non-root, all capabilities dropped",
+		DropCaps:       []string{"ALL"},
+		RunAsNonRoot:   true,
+		SeccompDefault: true,
+		NoEscalation:   true,
+	},
+}
+
+var (
+	debugProfiles     *debugProfileSet
+	debugProfilesErr  error
+	debugProfilesOnce sync.Once
+)
+
+// getDebugProfiles returns the cached profile set, loading DEBUG_PROFILES_CONFIG on first call.
+// Tests may override this to inject a custom set.
+var getDebugProfiles = func() (*debugProfileSet, error) {
+	debugProfilesOnce.Do(func() {
+		debugProfiles, debugProfilesErr = loadDebugProfiles(os.Getenv(debugProfilesConfigEnv))
+		if debugProfilesErr != nil {
+			slog.Error("Failed to load debug profiles", "error", debugProfilesErr, "path", os.Getenv(debugProfilesConfigEnv))
+		}
+	})
+	return debugProfiles, debugProfilesErr
+}
+
+// loadDebugProfiles merges the YAML file at configPath over the built-in profiles.
+// An empty configPath yields the built-ins with the default image allowlist.
+func loadDebugProfiles(configPath string) (*debugProfileSet, error) {
+	var cfg debugProfilesConfig
+	if configPath != "" {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", configPath, err)
+		}
+	}
+	if allow, _ := strconv.ParseBool(os.Getenv(debugAllowAnyImageEnv)); allow {
+		cfg.AllowAnyImage = true
+	}
+	return mergeDebugProfiles(cfg)
+}
+
+// mergeDebugProfiles overlays configured profiles on the built-ins by name.
+func mergeDebugProfiles(cfg debugProfilesConfig) (*debugProfileSet, error) {
+	byName := make(map[string]debugProfile, len(builtinDebugProfiles)+len(cfg.Profiles))
+	for _, p := range builtinDebugProfiles {
+		byName[p.Name] = p
+	}
+	for _, p := range cfg.Profiles {
+		if p.Name == "" {
+			return nil, fmt.Errorf("debug profile without a name")
+		}
+		byName[p.Name] = p
+	}
+
+	set := &debugProfileSet{
+		DefaultProfile: cfg.DefaultProfile,
+		AllowedImages:  cfg.AllowedImages,
+		AllowAnyImage:  cfg.AllowAnyImage,
+		Profiles:       make([]debugProfile, 0, len(byName)),
+	}
+	if len(set.AllowedImages) == 0 && !set.AllowAnyImage {
+		set.AllowedImages = append(append([]string{}, defaultDebugImages...), envOrDefault(nodeDebugImageEnv, defaultNodeDebugImage))
+	}
+	if set.DefaultProfile == "" {
+		set.DefaultProfile = debugProfileDefault
+	}
+	if _, ok := byName[set.DefaultProfile]; !ok {
+		return nil, fmt.Errorf("default debug profile %q is not defined", set.DefaultProfile)
+	}
+	for _, p := range byName {
+		set.Profiles = append(set.Profiles, p)
+	}
+	sort.Slice(set.Profiles, func(i, j int) bool { return set.Profiles[i].Name < set.Profiles[j].Name })
+	return set, nil
+}
+
+// lookup returns the named profile, or the default profile when name is empty.
+func (s *debugProfileSet) lookup(name string) (debugProfile, bool) {
+	if name == "" {
+		name = s.DefaultProfile
+	}
+	for _, p := range s.Profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return debugProfile{}, false
+}
+
+// imageAllowed reports whether image may be used with profile p: it must match
+// the profile's allowlist or, if it has none, the global one. Images are denied
+// by default; only AllowAnyImage admits every image.
+func (s *debugProfileSet) imageAllowed(p debugProfile, image string) bool {
+	patterns := p.AllowedImages
+	if len(patterns) == 0 {
+		if s.AllowAnyImage {
+			return true
+		}
+		patterns = s.AllowedImages
+	}
+	for _, pattern := range patterns {
+		if imageMatches(pattern, image) {
+			return true
+		}
+	}
+	return false
+}
+
+// imageMatches matches an image reference against an allowlist pattern.
+// A pattern without a tag or digest matches every tag and digest of the repository.
+func imageMatches(pattern, image string) bool {
+	if ok, _ := path.Match(pattern, image); ok {
+		return true
+	}
+	if !strings.ContainsAny(pattern, "*?[") && !strings.Contains(path.Base(pattern), ":") && !strings.Contains(pattern, "@") {
+		return strings.HasPrefix(image, pattern+":") || strings.HasPrefix(image, pattern+"@")
+	}
+	return false
+}
+
+// securityContext builds the container security context for the profile, adding
+// any capabilities requested explicitly. It returns nil when nothing is set.
+func (p debugProfile) securityContext(extraCaps []corev1.Capability) *corev1.SecurityContext {
+	add := make([]corev1.Capability, 0, len(p.AddCaps)+len(extraCaps))
+	seen := make(map[corev1.Capability]bool)
+	for _, c := range append(toCapabilities(p.AddCaps), extraCaps...) {
+		if !seen[c] {
+			seen[c] = true
+			add = append(add, c)
+		}
+	}
+
+	sc := &corev1.SecurityContext{}
+	empty := true
+	if len(add) > 0 || len(p.DropCaps) > 0 {
+		sc.Capabilities = &corev1.Capabilities{}
+		if len(add) > 0 {
+			sc.Capabilities.Add = add
+		}
+		if len(p.DropCaps) > 0 {
+			sc.Capabilities.Drop = toCapabilities(p.DropCaps)
+		}
+		empty = false
+	}
+	if p.Privileged {
+		privileged := true
+		sc.Privileged = &privileged
+		empty = false
+	}
+	if p.RunAsNonRoot {
+		nonRoot := true
+		sc.RunAsNonRoot = &nonRoot
+		empty = false
+	}
+	if p.NoEscalation {
+		escalation := false
+		sc.AllowPrivilegeEscalation = &escalation
+		empty = false
+	}
+	if p.SeccompDefault {
+		sc.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+		empty = false
+	}
+	if empty {
+		return nil
+	}
+	return sc
+}
+
+func toCapabilities(names []string) []corev1.Capability {
+	caps := make([]corev1.Capability, 0, len(names))
+	for _, n := range names {
+		caps = append(caps, corev1.Capability(strings.TrimPrefix(strings.ToUpper(n), "CAP_")))
+	}
+	return caps
+}
+
+// DebugProfilesHandler handles GET /api/pods/debug/profiles.
+// It lists the configured debug profiles and image allowlists so the UI can offer them.
+var DebugProfilesHandler = handleGet(errMsgDebugProfilesLoad, func(r *http.Request) (interface{}, error) {
+	return getDebugProfiles()
+})
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// withDebugProfiles swaps the profile set for the duration of the test.
+func withDebugProfiles(t *testing.T, cfg debugProfilesConfig) {
+	t.Helper()
+	set, err := mergeDebugProfiles(cfg)
+	if err != nil {
+		t.Fatalf("merge profiles: %v", err)
+	}
+	old := getDebugProfiles
+	getDebugProfiles = func() (*debugProfileSet, error) { return set, nil }
+	t.Cleanup(func() { getDebugProfiles = old })
+}
+
+// withAnyDebugImage lifts the default image allowlist for tests whose images are
+// not under test.
+func withAnyDebugImage(t *testing.T) {
+	t.Helper()
+	withDebugProfiles(t, debugProfilesConfig{AllowAnyImage: true})
+}
+
+func TestLoadDebugProfiles(t *testing.T) {
+	t.Run("built-ins without config", func(t *testing.T) {
+		set, err := loadDebugProfiles("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if set.DefaultProfile != "baseline" {
+			t.Errorf("default profile = %q", set.DefaultProfile)
+		}
+		for _, name := range []string{"general", "baseline", "netadmin", "sysadmin", "restricted"} {
+			if _, ok := set.lookup(name); !ok {
+				t.Errorf("missing built-in profile %q", name)
+			}
+		}
+	})
+
+	t.Run("config overrides and extends", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "profiles.yaml")
+		data := `defaultProfile: team
+allowedImages: ["registry.internal/debug/*"]
+profiles:
+  - name: team
+    defaultImage: registry.internal/debug/tools:1.0
+    addCapabilities: [NET_RAW]
+  - name: netadmin
+    allowedImages: [nicolaka/netshoot]
+`
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		set, err := loadDebugProfiles(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		team, ok := set.lookup("")
+		if !ok || team.Name != "team" || team.DefaultImage == "" {
+			t.Fatalf("expected default profile team, got %+v", team)
+		}
+		netadmin, _ := set.lookup("netadmin")
+		if len(netadmin.AddCaps) != 0 {
+			t.Errorf("configured profile should replace the built-in, got caps %v", netadmin.AddCaps)
+		}
+		if !set.imageAllowed(netadmin, "nicolaka/netshoot:v0.11") {
+			t.Error("per-profile allowlist should admit any tag of the repository")
+		}
+		if set.imageAllowed(netadmin, "registry.internal/debug/tools:1.0") {
+			t.Error("per-profile allowlist should take precedence over the global one")
+		}
+		if !set.imageAllowed(team, "registry.internal/debug/tools:1.0") || set.imageAllowed(team, "busybox") {
+			t.Error("global allowlist not applied")
+		}
+	})
+
+	t.Run("default allowlist without config", func(t *testing.T) {
+		t.Setenv(nodeDebugImageEnv, "registry.internal/node-shell:2")
+		set, err := loadDebugProfiles("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		baseline, _ := set.lookup("")
+		for _, preset := range []string{"busybox:1.36", "nicolaka/netshoot:latest", "alpine:3.19", "ubuntu:22.04",
+			"praqma/network-multitool", "curlimages/curl:latest", "registry.internal/node-shell:2"} {
+			if !set.imageAllowed(baseline, preset) {
+				t.Errorf("%s should be allowed by default", preset)
+			}
+		}
+		if set.imageAllowed(baseline, "evil/shell:latest") {
+			t.Error("unlisted images should be denied by default")
+		}
+
+		t.Setenv(debugAllowAnyImageEnv, "true")
+		if set, err = loadDebugProfiles(""); err != nil {
+			t.Fatal(err)
+		}
+		if !set.imageAllowed(baseline, "evil/shell:latest") {
+			t.Errorf("%s=true should allow any image", debugAllowAnyImageEnv)
+		}
+	})
+
+	t.Run("unknown default is an error", func(t *testing.T) {
+		if _, err := mergeDebugProfiles(debugProfilesConfig{DefaultProfile: "nope"}); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestImageMatches(t *testing.T) {
+	tests := []struct {
+		pattern, image string
+		want           bool
+	}{
+		{"busybox", "busybox:1.36", true},
+		{"busybox", "busybox@sha256:abc", true},
+		{"busybox", "busybox-evil:1", false},
+		{"busybox:1.36", "busybox:1.37", false},
+		{"registry:5000/tools", "registry:5000/tools:1", true},
+		{"nicolaka/*", "nicolaka/netshoot:latest", true},
+		{"nicolaka/*", "evil/netshoot:latest", false},
+	}
+	for _, tt := range tests {
+		if got := imageMatches(tt.pattern, tt.image); got != tt.want {
+			t.Errorf("imageMatches(%q, %q) = %v, want %v", tt.pattern, tt.image, got, tt.want)
+		}
+	}
+}
+
+func TestDebugProfileSecurityContext(t *testing.T) {
+	set, _ := loadDebugProfiles("")
+
+	baseline, _ := set.lookup("")
+	if sc := baseline.securityContext(nil); sc != nil {
+		t.Errorf("default profile should not set a security context, got %+v", sc)
+	}
+
+	general, _ := set.lookup("general")
+	sc := general.securityContext([]corev1.Capability{"SYS_PTRACE"})
+	if sc == nil || len(sc.Capabilities.Add) != 1 || sc.Capabilities.Add[0] != "SYS_PTRACE" {
+		t.Errorf("general: unexpected %+v", sc)
+	}
+
+	sysadmin, _ := set.lookup("sysadmin")
+	if sc := sysadmin.securityContext(nil); sc == nil || sc.Privileged == nil || !*sc.Privileged {
+		t.Errorf("sysadmin should be privileged, got %+v", sc)
+	}
+
+	restricted, _ := set.lookup("restricted")
+	sc = restricted.securityContext(nil)
+	if sc == nil || sc.RunAsNonRoot == nil || !*sc.RunAsNonRoot ||
+		sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation ||
+		sc.SeccompProfile == nil || len(sc.Capabilities.Drop) != 1 || sc.Capabilities.Drop[0] != "ALL" {
+		t.Errorf("restricted: unexpected %+v", sc)
+	}
+}
+
+func TestPodDebugHandler_ProfileValidation(t *testing.T) {
+	withDebugProfiles(t, debugProfilesConfig{AllowedImages: []string{"busybox"}})
+	withDebugClientset(t, fake.NewSimpleClientset(newRunningPod("default", "my-pod")))
+
+	tests := []struct {
+		name string
+		body debugPodRequest
+		want int
+	}{
+		{"unknown profile", debugPodRequest{Image: "busybox:1.36", Profile: "root"}, http.StatusBadRequest},
+		{"image not allowed", debugPodRequest{Image: "evil/shell:latest"}, http.StatusForbidden},
+		{"restricted rejects capabilities", debugPodRequest{Image: "busybox:1.36", Profile: "restricted", AllowPtrace: true}, http.StatusBadRequest},
+		{"invalid env name", debugPodRequest{Image: "busybox:1.36", Env: []debugEnvVar{{Name: "A=B"}}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			PodDebugHandler(w, newDebugRequest(t, "default", "my-pod", tt.body))
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPodDebugHandler_DeniesUnlistedImageByDefault(t *testing.T) {
+	withDebugProfiles(t, debugProfilesConfig{})
+	withDebugClientset(t, fake.NewSimpleClientset(newRunningPod("default", "my-pod")))
+
+	w := httptest.NewRecorder()
+	PodDebugHandler(w, newDebugRequest(t, "default", "my-pod", debugPodRequest{Image: "evil/shell:latest"}))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPodDebugHandler_AppliesProfileAndOptions(t *testing.T) {
+	withDebugProfiles(t, debugProfilesConfig{Profiles: []debugProfile{{
+		Name:         "netadmin",
+		DefaultImage: "nicolaka/netshoot:latest",
+		AddCaps:      []string{"NET_ADMIN"},
+	}}})
+	cs := fake.NewSimpleClientset(newRunningPod("default", "my-pod"))
+	withDebugClientset(t, cs)
+	withFastPolling(t, 200*time.Millisecond, 5*time.Millisecond)
+
+	req := newDebugRequest(t, "default", "my-pod", debugPodRequest{
+		Profile:     "netadmin",
+		Name:        "dbg",
+		AllowPtrace: true,
+		Command:     []string{"tcpdump"},
+		Args:        []string{"-i", "any"},
+		Env:         []debugEnvVar{{Name: "TERM", Value: "xterm"}},
+		WorkingDir:  "/tmp",
+	})
+	go func() {
+		time.Sleep(15 * time.Millisecond)
+		setEphemeralContainerRunning(t, cs, "default", "my-pod", "dbg")
+	}()
+	w := httptest.NewRecorder()
+	PodDebugHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	final, err := cs.CoreV1().Pods("default").Get(context.Background(), "my-pod", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec := final.Spec.EphemeralContainers[0]
+	if ec.Image != "nicolaka/netshoot:latest" {
+		t.Errorf("expected profile default image, got %q", ec.Image)
+	}
+	if len(ec.Command) != 1 || ec.Command[0] != "tcpdump" || len(ec.Args) != 2 || ec.WorkingDir != "/tmp" {
+		t.Errorf("command/args/workingDir not applied: %v %v %q", ec.Command, ec.Args, ec.WorkingDir)
+	}
+	if len(ec.Env) != 1 || ec.Env[0].Name != "TERM" {
+		t.Errorf("env not applied: %v", ec.Env)
+	}
+	if sc := ec.SecurityContext; sc == nil || len(sc.Capabilities.Add) != 2 {
+		t.Errorf("expected NET_ADMIN and SYS_PTRACE, got %+v", sc)
+	}
+}
+
+func TestDebugProfilesHandler(t *testing.T) {
+	withDebugProfiles(t, debugProfilesConfig{DefaultProfile: "general"})
+
+	w := httptest.NewRecorder()
+	DebugProfilesHandler(w, httptest.NewRequest(http.MethodGet, "/api/pods/debug/profiles", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var set debugProfileSet
+	if err := json.NewDecoder(w.Body).Decode(&set); err != nil {
+		t.Fatal(err)
+	}
+	if set.DefaultProfile != "general" || len(set.Profiles) != len(builtinDebugProfiles) {
+		t.Errorf("unexpected profile set: %+v", set)
+	}
+}
@@ -0,0 +1,457 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// Annotations maintained by the deployment controller and kubectl.
+const (
+	annotationRevision        = "deployment.kubernetes.io/revision"
+	annotationRevisionHistory = "deployment.kubernetes.io/revision-history"
+	annotationDesiredReplicas = "deployment.kubernetes.io/desired-replicas"
+	annotationMaxReplicas     = "deployment.kubernetes.io/max-replicas"
+	annotationChangeCause     = "kubernetes.io/change-cause"
+	annotationLastApplied     = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+// templateDiffContext is the number of unchanged lines shown around each change.
+const templateDiffContext = 3
+
+// DeploymentRevision is one entry of a deployment's rollout history.
+type DeploymentRevision struct {
+	Revision    int64     `json:"revision"`
+	ReplicaSet  string    `json:"replicaSet"`
+	Images      []string  `json:"images"`
+	ChangeCause string    `json:"changeCause,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Replicas    int32     `json:"replicas"`
+	Current     bool      `json:"current"`
+	// Diff is a unified diff of the pod template against the previous revision;
+	// it is empty for the oldest revision still retained.
+	Diff string `json:"diff,omitempty"`
+}
+
+// DeploymentHistoryResponse is returned by GET /api/deployments/{ns}/{name}/history.
+type DeploymentHistoryResponse struct {
+	Name            string               `json:"name"`
+	Namespace       string               `json:"namespace"`
+	CurrentRevision int64                `json:"currentRevision"`
+	Revisions       []DeploymentRevision `json:"revisions"`
+}
+
+// DeploymentHistoryHandler handles GET /api/deployments/{ns}/{name}/history.
+func DeploymentHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, clientset := parseDeploymentAction(w, r, historyPathSuffix)
+	if clientset == nil {
+		return
+	}
+
+	history, err := getDeploymentHistory(r.Context(), clientset, namespace, name)
+	if err != nil {
+		writeDeploymentError(w, err, errMsgDeploymentHistory)
+		return
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+// DeploymentRollbackHandler handles POST /api/deployments/{ns}/{name}/rollback?revision=N.
+// Without a revision (or with 0) it rolls back to the previous revision.
+func DeploymentRollbackHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+	r = withTimeout(r)
+
+	var revision int64
+	if v := r.URL.Query().Get("revision"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, errMsgRevisionInvalid)
+			return
+		}
+		revision = n
+	}
+
+	namespace, name, clientset := parseDeploymentAction(w, r, rollbackPathSuffix)
+	if clientset == nil {
+		return
+	}
+
+	resp, err := rollbackDeployment(r.Context(), clientset, namespace, name, revision)
+	if err != nil {
+		writeDeploymentError(w, err, errMsgDeploymentRollback)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseDeploymentAction parses /api/deployments/{ns}/{name}{suffix} and obtains the
+// client. It writes an error response and returns a nil client on failure.
+func parseDeploymentAction(w http.ResponseWriter, r *http.Request, suffix string) (string, string, kubernetes.Interface) {
+	return parseWorkloadAction(w, r, deploymentsPathPrefix, suffix)
+}
+
+// ownedReplicaSet is a ReplicaSet controlled by a deployment, with its parsed revision.
+type ownedReplicaSet struct {
+	rs       *appsv1.ReplicaSet
+	revision int64
+}
+
+// listOwnedReplicaSets returns the ReplicaSets controlled by the deployment,
+// oldest revision first.
+func listOwnedReplicaSets(ctx context.Context, clientset kubernetes.Interface, deployment *appsv1.Deployment) ([]ownedReplicaSet, error) {
+	opts := metav1.ListOptions{}
+	if deployment.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		opts.LabelSelector = selector.String()
+	}
+	rsList, err := clientset.AppsV1().ReplicaSets(deployment.Namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make([]ownedReplicaSet, 0, len(rsList.Items))
+	for i := range rsList.Items {
+		rs := &rsList.Items[i]
+		owner := metav1.GetControllerOf(rs)
+		if owner == nil || owner.UID != deployment.UID {
+			continue
+		}
+		revision, err := strconv.ParseInt(rs.Annotations[annotationRevision], 10, 64)
+		if err != nil {
+			continue
+		}
+		owned = append(owned, ownedReplicaSet{rs: rs, revision: revision})
+	}
+	sort.Slice(owned, func(i, j int) bool { return owned[i].revision < owned[j].revision })
+	return owned, nil
+}
+
+// getDeploymentHistory builds the rollout history from the deployment's ReplicaSets.
+func getDeploymentHistory(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*DeploymentHistoryResponse, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	owned, err := listOwnedReplicaSets(ctx, clientset, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	current, _ := strconv.ParseInt(deployment.Annotations[annotationRevision], 10, 64)
+	resp := &DeploymentHistoryResponse{
+		Name:            name,
+		Namespace:       namespace,
+		CurrentRevision: current,
+		Revisions:       make([]DeploymentRevision, 0, len(owned)),
+	}
+
+	var previous *corev1.PodTemplateSpec
+	for _, o := range owned {
+		template := templateWithoutHash(o.rs.Spec.Template)
+		rev := DeploymentRevision{
+			Revision:    o.revision,
+			ReplicaSet:  o.rs.Name,
+			Images:      templateImages(template),
+			ChangeCause: o.rs.Annotations[annotationChangeCause],
+			CreatedAt:   o.rs.CreationTimestamp.Time,
+			Replicas:    o.rs.Status.Replicas,
+			Current:     o.revision == current,
+		}
+		if previous != nil {
+			diff, err := yamlDiff(*previous, template)
+			if err != nil {
+				return nil, err
+			}
+			rev.Diff = diff
+		}
+		resp.Revisions = append(resp.Revisions, rev)
+		previous = &template
+	}
+
+	// Newest first, as `kubectl rollout history` users read it from the top.
+	sort.Slice(resp.Revisions, func(i, j int) bool { return resp.Revisions[i].Revision > resp.Revisions[j].Revision })
+	return resp, nil
+}
+
+// RollbackResponse reports the result of a rollback.
+type RollbackResponse struct {
+	Message    string `json:"message"`
+	ToRevision int64  `json:"toRevision"`
+	// Skipped is true when the deployment already runs the requested template.
+	Skipped bool `json:"skipped"`
+}
+
+// rollbackDeployment restores the pod template of the given revision, as
+// `kubectl rollout undo --to-revision` does. Revision 0 means the previous one.
+func rollbackDeployment(ctx context.Context, clientset kubernetes.Interface, namespace, name string, revision int64) (*RollbackResponse, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if deployment.Spec.Paused {
+		return nil, &statusError{status: http.StatusConflict, message: errMsgRollbackPaused}
+	}
+
+	owned, err := listOwnedReplicaSets(ctx, clientset, deployment)
+	if err != nil {
+		return nil, err
+	}
+	target := findRollbackTarget(owned, deployment, revision)
+	if target == nil {
+		if revision == 0 {
+			return nil, &statusError{status: http.StatusConflict, message: errMsgRollbackNoPrevious}
+		}
+		return nil, &statusError{status: http.StatusNotFound, message: fmt.Sprintf("Revision %d not found", revision)}
+	}
+
+	template := templateWithoutHash(target.rs.Spec.Template)
+	if apiequality.Semantic.DeepEqual(template, deployment.Spec.Template) {
+		return &RollbackResponse{
+			Message:    fmt.Sprintf("Skipped rollback: current template already matches revision %d", target.revision),
+			ToRevision: target.revision,
+			Skipped:    true,
+		}, nil
+	}
+
+	// Like kubectl, carry the ReplicaSet's annotations (notably change-cause) back
+	// onto the deployment, except those the controller manages itself.
+	annotations := make(map[string]string, len(deployment.Annotations))
+	for k, v := range deployment.Annotations {
+		annotations[k] = v
+	}
+	for k, v := range target.rs.Annotations {
+		switch k {
+		case annotationRevision, annotationRevisionHistory, annotationDesiredReplicas, annotationMaxReplicas, annotationLastApplied:
+			continue
+		}
+		annotations[k] = v
+	}
+
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "replace", "path": "/spec/template", "value": template},
+		{"op": "replace", "path": "/metadata/annotations", "value": annotations},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+		return nil, err
+	}
+
+	return &RollbackResponse{
+		Message:    fmt.Sprintf("Deployment rolled back to revision %d", target.revision),
+		ToRevision: target.revision,
+	}, nil
+}
+
+// findRollbackTarget picks the ReplicaSet for the requested revision, or for the
+// newest revision before the current one when revision is 0.
+func findRollbackTarget(owned []ownedReplicaSet, deployment *appsv1.Deployment, revision int64) *ownedReplicaSet {
+	if revision > 0 {
+		for i := range owned {
+			if owned[i].revision == revision {
+				return &owned[i]
+			}
+		}
+		return nil
+	}
+
+	current, _ := strconv.ParseInt(deployment.Annotations[annotationRevision], 10, 64)
+	for i := len(owned) - 1; i >= 0; i-- {
+		if owned[i].revision < current || (current == 0 && i < len(owned)-1) {
+			return &owned[i]
+		}
+	}
+	return nil
+}
+
+// templateWithoutHash returns a copy of the template without the pod-template-hash
+// label the deployment controller adds to each ReplicaSet.
+func templateWithoutHash(template corev1.PodTemplateSpec) corev1.PodTemplateSpec {
+	t := *template.DeepCopy()
+	delete(t.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	return t
+}
+
+// templateImages lists the images of the template's init and app containers.
+func templateImages(template corev1.PodTemplateSpec) []string {
+	images := make([]string, 0, len(template.Spec.InitContainers)+len(template.Spec.Containers))
+	for _, c := range template.Spec.InitContainers {
+		images = append(images, c.Image)
+	}
+	for _, c := range template.Spec.Containers {
+		images = append(images, c.Image)
+	}
+	return images
+}
+
+// yamlDiff renders a unified diff between the YAML of two objects.
+func yamlDiff(from, to interface{}) (string, error) {
+	a, err := yaml.Marshal(from)
+	if err != nil {
+		return "", err
+	}
+	b, err := yaml.Marshal(to)
+	if err != nil {
+		return "", err
+	}
+	return unifiedDiff(strings.Split(strings.TrimSuffix(string(a), "\n"), "\n"),
+		strings.Split(strings.TrimSuffix(string(b), "\n"), "\n"), templateDiffContext), nil
+}
+
+// maxDiffCells caps the LCS table diffLines builds, so large objects cannot make
+// a single diff allocate hundreds of megabytes.
+const maxDiffCells = 1 << 20
+
+// diffLine is one line of a diff: ' ' unchanged, '-' removed or '+' added.
+type diffLine struct {
+	op   byte
+	text string
+	ai   int // 1-based line number in a for ' ' and '-' lines
+	bi   int // 1-based line number in b for ' ' and '+' lines
+}
+
+// diffLines returns the line diff of a and b. The common prefix and suffix are
+// matched directly and the rest by longest common subsequence; if that part is
+// larger than maxDiffCells allows, it is reported as removed and re-added whole.
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]diffLine, 0, max(len(a), len(b)))
+	for k := 0; k < prefix; k++ {
+		lines = append(lines, diffLine{' ', a[k], k + 1, k + 1})
+	}
+
+	am, bm := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	i, j := 0, 0
+	if len(am)*len(bm) > maxDiffCells {
+		for ; i < len(am); i++ {
+			lines = append(lines, diffLine{'-', am[i], prefix + i + 1, prefix})
+		}
+		for ; j < len(bm); j++ {
+			lines = append(lines, diffLine{'+', bm[j], prefix + len(am), prefix + j + 1})
+		}
+	} else {
+		// Longest common subsequence table, filled from the end.
+		lcs := make([][]int32, len(am)+1)
+		for k := range lcs {
+			lcs[k] = make([]int32, len(bm)+1)
+		}
+		for x := len(am) - 1; x >= 0; x-- {
+			for y := len(bm) - 1; y >= 0; y-- {
+				if am[x] == bm[y] {
+					lcs[x][y] = lcs[x+1][y+1] + 1
+				} else {
+					lcs[x][y] = max(lcs[x+1][y], lcs[x][y+1])
+				}
+			}
+		}
+		for i < len(am) || j < len(bm) {
+			switch {
+			case i < len(am) && j < len(bm) && am[i] == bm[j]:
+				lines = append(lines, diffLine{' ', am[i], prefix + i + 1, prefix + j + 1})
+				i++
+				j++
+			case i < len(am) && (j == len(bm) || lcs[i+1][j] >= lcs[i][j+1]):
+				lines = append(lines, diffLine{'-', am[i], prefix + i + 1, prefix + j})
+				i++
+			default:
+				lines = append(lines, diffLine{'+', bm[j], prefix + i, prefix + j + 1})
+				j++
+			}
+		}
+	}
+
+	for k := suffix; k > 0; k-- {
+		lines = append(lines, diffLine{' ', a[len(a)-k], len(a) - k + 1, len(b) - k + 1})
+	}
+	return lines
+}
+
+// unifiedDiff returns the line diff of a and b in unified format with the given
+// number of context lines, or "" when they are equal.
+func unifiedDiff(a, b []string, context int) string {
+	lines := diffLines(a, b)
+
+	var out strings.Builder
+	for start := 0; start < len(lines); {
+		// Find the next change and the end of its hunk, merging changes whose
+		// context would overlap.
+		first := start
+		for first < len(lines) && lines[first].op == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		hunkStart := max(first-context, start)
+		last := first
+		for k := first; k < len(lines) && k <= last+2*context; k++ {
+			if lines[k].op != ' ' {
+				last = k
+			}
+		}
+		hunkEnd := min(last+context+1, len(lines))
+
+		var aStart, aCount, bStart, bCount int
+		for _, l := range lines[hunkStart:hunkEnd] {
+			if l.op != '+' {
+				if aCount == 0 {
+					aStart = l.ai
+				}
+				aCount++
+			}
+			if l.op != '-' {
+				if bCount == 0 {
+					bStart = l.bi
+				}
+				bCount++
+			}
+		}
+		if aCount == 0 {
+			aStart = lines[hunkStart].ai
+		}
+		if bCount == 0 {
+			bStart = lines[hunkStart].bi
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+		for _, l := range lines[hunkStart:hunkEnd] {
+			out.WriteByte(l.op)
+			out.WriteString(l.text)
+			out.WriteByte('\n')
+		}
+		start = hunkEnd
+	}
+	return out.String()
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func podTemplate(image string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}},
+	}
+}
+
+// newRolledOutDeployment returns a deployment at revision len(images) running the last image.
+func newRolledOutDeployment(images ...string) *appsv1.Deployment {
+	replicas := int32(2)
+	d := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: "web", UID: types.UID("web-uid"),
+			Annotations: map[string]string{annotationRevision: strconv.Itoa(len(images))},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: podTemplate(images[len(images)-1]),
+		},
+	}
+	return d
+}
+
+func newOwnedReplicaSet(d *appsv1.Deployment, uid types.UID, revision int, image, cause string) *appsv1.ReplicaSet {
+	controller := true
+	template := podTemplate(image)
+	template.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = "hash" + strconv.Itoa(revision)
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: d.Namespace, Name: d.Name + "-" + strconv.Itoa(revision),
+			Labels:          template.Labels,
+			Annotations:     map[string]string{annotationRevision: strconv.Itoa(revision)},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: d.Name, UID: uid, Controller: &controller}},
+		},
+		Spec: appsv1.ReplicaSetSpec{Template: template},
+	}
+	if cause != "" {
+		rs.Annotations[annotationChangeCause] = cause
+	}
+	return rs
+}
+
+// newHistoryClientset holds a deployment at revision 3 with one owned ReplicaSet
+// per revision, plus a ReplicaSet owned by another deployment.
+func newHistoryClientset() *fake.Clientset {
+	d := newRolledOutDeployment("app:v1", "app:v2", "app:v3")
+	return fake.NewSimpleClientset(d,
+		newOwnedReplicaSet(d, d.UID, 1, "app:v1", "initial"),
+		newOwnedReplicaSet(d, d.UID, 2, "app:v2", "bump to v2"),
+		newOwnedReplicaSet(d, d.UID, 3, "app:v3", ""),
+		newOwnedReplicaSet(d, "other-uid", 7, "app:other", ""),
+	)
+}
+
+func TestDeploymentHistoryHandler(t *testing.T) {
+	withDeploymentClientset(t, newHistoryClientset())
+
+	w := httptest.NewRecorder()
+	DeploymentActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/deployments/default/web/history", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var history DeploymentHistoryResponse
+	if err := json.NewDecoder(w.Body).Decode(&history); err != nil {
+		t.Fatal(err)
+	}
+
+	if history.CurrentRevision != 3 || len(history.Revisions) != 3 {
+		t.Fatalf("expected 3 owned revisions, got %+v", history)
+	}
+	newest, oldest := history.Revisions[0], history.Revisions[2]
+	if newest.Revision != 3 || !newest.Current || newest.Images[0] != "app:v3" {
+		t.Errorf("newest = %+v", newest)
+	}
+	if !strings.Contains(newest.Diff, "-  - image: app:v2\n") || !strings.Contains(newest.Diff, "+  - image: app:v3\n") {
+		t.Errorf("diff should show the image change:\n%s", newest.Diff)
+	}
+	if strings.Contains(newest.Diff, "pod-template-hash") {
+		t.Errorf("diff should ignore the pod-template-hash label:\n%s", newest.Diff)
+	}
+	if oldest.ChangeCause != "initial" || oldest.Diff != "" {
+		t.Errorf("oldest = %+v", oldest)
+	}
+}
+
+func TestDeploymentRollbackHandler(t *testing.T) {
+	cs := newHistoryClientset()
+	withDeploymentClientset(t, cs)
+	rollback := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		DeploymentActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/deployments/default/web/rollback"+query, nil))
+		return w
+	}
+
+	w := rollback("")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp RollbackResponse
+	json.NewDecoder(w.Body).Decode(&resp) //nolint:errcheck
+	if resp.ToRevision != 2 || resp.Skipped {
+		t.Errorf("default rollback should target the previous revision: %+v", resp)
+	}
+	d, _ := cs.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	if d.Spec.Template.Spec.Containers[0].Image != "app:v2" || d.Annotations[annotationChangeCause] != "bump to v2" {
+		t.Errorf("template/annotations not restored: %+v %v", d.Spec.Template.Spec.Containers, d.Annotations)
+	}
+	if _, ok := d.Spec.Template.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok {
+		t.Error("pod-template-hash label must not be copied into the deployment")
+	}
+	if d.Annotations[annotationRevision] != "3" {
+		t.Errorf("revision annotation is owned by the controller, got %q", d.Annotations[annotationRevision])
+	}
+
+	w = rollback("?revision=2")
+	json.NewDecoder(w.Body).Decode(&resp) //nolint:errcheck
+	if w.Code != http.StatusOK || !resp.Skipped {
+		t.Errorf("rolling back to the running template should be skipped: %d %+v", w.Code, resp)
+	}
+
+	for query, want := range map[string]int{
+		"?revision=9":   http.StatusNotFound,
+		"?revision=abc": http.StatusBadRequest,
+		"?revision=-1":  http.StatusBadRequest,
+	} {
+		if w := rollback(query); w.Code != want {
+			t.Errorf("%s: expected %d, got %d", query, want, w.Code)
+		}
+	}
+
+	d.Spec.Paused = true
+	cs.AppsV1().Deployments("default").Update(context.Background(), d, metav1.UpdateOptions{}) //nolint:errcheck
+	if w := rollback("?revision=1"); w.Code != http.StatusConflict {
+		t.Errorf("paused deployment: expected 409, got %d", w.Code)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	b := []string{"a", "B", "c", "d", "e", "f", "g", "h", "i", "j", "k"}
+	want := "@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+		"@@ -8,3 +8,4 @@\n h\n i\n j\n+k\n"
+	if got := unifiedDiff(a, b, 3); got != want {
+		t.Errorf("unexpected diff:\n%s", got)
+	}
+	if got := unifiedDiff(a, a, 3); got != "" {
+		t.Errorf("equal input should produce no diff, got %q", got)
+	}
+
+	// Past maxDiffCells the changed middle is replaced whole instead of being
+	// matched line by line.
+	large := func(prefix string) []string {
+		lines := []string{"kind: ConfigMap"}
+		for k := 0; k < 2000; k++ {
+			lines = append(lines, fmt.Sprintf("%s-%d", prefix, k))
+		}
+		return append(lines, "metadata: {}")
+	}
+	got := unifiedDiff(large("old"), large("new"), 1)
+	if !strings.HasPrefix(got, "@@ -1,2002 +1,2002 @@\n kind: ConfigMap\n-old-0\n") ||
+		!strings.HasSuffix(got, "+new-1999\n metadata: {}\n") ||
+		strings.Count(got, "\n-") != 2000 || strings.Count(got, "\n+") != 2000 {
+		t.Errorf("unexpected large diff:\n%.200s", got)
+	}
+}
@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// rolloutStatusTimeout bounds how long a rollout status stream stays open.
+var rolloutStatusTimeout = 15 * time.Minute
+
+// rolloutRewatchBackoff is the initial delay before a closed rollout watch is
+// re-established; it doubles, up to rolloutRewatchMaxBackoff, while watches end
+// without delivering an update.
+var (
+	rolloutRewatchBackoff    = time.Second
+	rolloutRewatchMaxBackoff = 30 * time.Second
+)
+
+// Rollout phases reported by the rollout status stream.
+const (
+	rolloutPhaseWaiting  = "waiting"
+	rolloutPhasePaused   = "paused"
+	rolloutPhaseComplete = "complete"
+	rolloutPhaseFailed   = "failed"
+)
+
+// reasonProgressDeadlineExceeded is the Progressing condition reason set when a
+// rollout makes no progress within spec.progressDeadlineSeconds.
+const reasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+// RolloutCondition is the deployment's Progressing condition.
+type RolloutCondition struct {
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// RolloutStatus is one update of the rollout status stream.
+type RolloutStatus struct {
+	Generation          int64             `json:"generation"`
+	ObservedGeneration  int64             `json:"observedGeneration"`
+	DesiredReplicas     int32             `json:"desiredReplicas"`
+	UpdatedReplicas     int32             `json:"updatedReplicas"`
+	ReadyReplicas       int32             `json:"readyReplicas"`
+	AvailableReplicas   int32             `json:"availableReplicas"`
+	UnavailableReplicas int32             `json:"unavailableReplicas"`
+	Paused              bool              `json:"paused"`
+	Progressing         *RolloutCondition `json:"progressing,omitempty"`
+	Phase               string            `json:"phase"`
+	Message             string            `json:"message"`
+	// Done is set on the last event of the stream.
+	Done bool `json:"done"`
+}
+
+// DeploymentRolloutStatusHandler handles GET /api/deployments/{ns}/{name}/rollout-status.
+// It streams RolloutStatus updates as server-sent events until the rollout
+// completes or fails. The optional generation query parameter, as returned by the
+// restart endpoint, makes the stream wait until that generation is observed.
+func DeploymentRolloutStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	var generation int64
+	if v := r.URL.Query().Get("generation"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, errMsgGenerationInvalid)
+			return
+		}
+		generation = n
+	}
+
+	namespace, name, clientset := parseDeploymentAction(w, r, rolloutStatusPathSuffix)
+	if clientset == nil {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), rolloutStatusTimeout)
+	defer cancel()
+
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		writeResourceError(w, err, errMsgDeploymentNotFound, errMsgRolloutStatus)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	emit := func(status RolloutStatus) {
+		data, _ := json.Marshal(status)
+		fmt.Fprintf(w, "data: %s\n\n", data) //nolint:errcheck
+		flusher.Flush()
+	}
+
+	watchRollout(ctx, clientset, deployment, generation, emit)
+}
+
+// watchRollout emits the rollout status of the deployment and of every change
+// to it until the rollout reaches a terminal phase or ctx ends. The watch is
+// re-established, with backoff, from the last seen resourceVersion if the server
+// closes it; if the watch fails (e.g. that version has expired), the deployment
+// is fetched again and the watch continues from its current resourceVersion.
+func watchRollout(ctx context.Context, clientset kubernetes.Interface, deployment *appsv1.Deployment, generation int64, emit func(RolloutStatus)) {
+	status := rolloutStatus(deployment, generation)
+	emit(status)
+	if status.Done {
+		return
+	}
+
+	namespace, name := deployment.Namespace, deployment.Name
+	resourceVersion := deployment.ResourceVersion
+	backoff := rolloutRewatchBackoff
+	for {
+		watcher, err := clientset.AppsV1().Deployments(namespace).Watch(ctx, metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", name).String(),
+			ResourceVersion: resourceVersion,
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				emit(RolloutStatus{Phase: rolloutPhaseFailed, Message: fmt.Sprintf("watch failed: %v", err), Done: true})
+			}
+			return
+		}
+
+		progressed, failed := false, false
+		for event := range watcher.ResultChan() {
+			if event.Type == watch.Error {
+				failed = true
+				break
+			}
+			d, ok := event.Object.(*appsv1.Deployment)
+			if !ok || d.Name != name {
+				continue
+			}
+			if event.Type == watch.Deleted {
+				emit(RolloutStatus{Phase: rolloutPhaseFailed, Message: "deployment was deleted", Done: true})
+				watcher.Stop()
+				return
+			}
+			progressed = true
+			resourceVersion = d.ResourceVersion
+			status = rolloutStatus(d, generation)
+			emit(status)
+			if status.Done {
+				watcher.Stop()
+				return
+			}
+		}
+		watcher.Stop()
+
+		if progressed {
+			backoff = rolloutRewatchBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, rolloutRewatchMaxBackoff)
+
+		// Resync: the watch may have ended on an error such as an expired
+		// resourceVersion, and updates may have been missed meanwhile.
+		d, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if k8serrors.IsNotFound(err) {
+				emit(RolloutStatus{Phase: rolloutPhaseFailed, Message: "deployment was deleted", Done: true})
+			} else {
+				emit(RolloutStatus{Phase: rolloutPhaseFailed, Message: fmt.Sprintf("watch failed: %v", err), Done: true})
+			}
+			return
+		}
+		if failed || d.ResourceVersion != resourceVersion {
+			resourceVersion = d.ResourceVersion
+			status = rolloutStatus(d, generation)
+			emit(status)
+			if status.Done {
+				return
+			}
+		}
+	}
+}
+
+// rolloutStatus evaluates a deployment's rollout the way `kubectl rollout status` does.
+func rolloutStatus(d *appsv1.Deployment, generation int64) RolloutStatus {
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+	s := RolloutStatus{
+		Generation:          d.Generation,
+		ObservedGeneration:  d.Status.ObservedGeneration,
+		DesiredReplicas:     desired,
+		UpdatedReplicas:     d.Status.UpdatedReplicas,
+		ReadyReplicas:       d.Status.ReadyReplicas,
+		AvailableReplicas:   d.Status.AvailableReplicas,
+		UnavailableReplicas: d.Status.UnavailableReplicas,
+		Paused:              d.Spec.Paused,
+	}
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing {
+			s.Progressing = &RolloutCondition{Status: string(c.Status), Reason: c.Reason, Message: c.Message}
+		}
+	}
+
+	want := max(generation, d.Generation)
+	switch {
+	case d.Status.ObservedGeneration < want:
+		s.Phase = rolloutPhaseWaiting
+		s.Message = "Waiting for deployment spec update to be observed"
+	case s.Progressing != nil && s.Progressing.Reason == reasonProgressDeadlineExceeded && s.Progressing.Status != string(corev1.ConditionTrue):
+		s.Phase = rolloutPhaseFailed
+		s.Message = fmt.Sprintf("Deployment %q exceeded its progress deadline", d.Name)
+		s.Done = true
+	case d.Spec.Paused:
+		s.Phase = rolloutPhasePaused
+		s.Message = "Rollout is paused; resume it to continue"
+	case s.UpdatedReplicas < desired:
+		s.Phase = rolloutPhaseWaiting
+		s.Message = fmt.Sprintf("%d of %d updated replicas", s.UpdatedReplicas, desired)
+	case d.Status.Replicas > s.UpdatedReplicas:
+		s.Phase = rolloutPhaseWaiting
+		s.Message = fmt.Sprintf("%d old replicas are pending termination", d.Status.Replicas-s.UpdatedReplicas)
+	case s.AvailableReplicas < s.UpdatedReplicas:
+		s.Phase = rolloutPhaseWaiting
+		s.Message = fmt.Sprintf("%d of %d updated replicas are available", s.AvailableReplicas, s.UpdatedReplicas)
+	default:
+		s.Phase = rolloutPhaseComplete
+		s.Message = fmt.Sprintf("Deployment %q successfully rolled out", d.Name)
+		s.Done = true
+	}
+	return s
+}
+
+// DeploymentPauseHandler handles POST /api/deployments/{ns}/{name}/pause.
+func DeploymentPauseHandler(w http.ResponseWriter, r *http.Request) {
+	setDeploymentPaused(w, r, pausePathSuffix, true)
+}
+
+// DeploymentResumeHandler handles POST /api/deployments/{ns}/{name}/resume.
+func DeploymentResumeHandler(w http.ResponseWriter, r *http.Request) {
+	setDeploymentPaused(w, r, resumePathSuffix, false)
+}
+
+func setDeploymentPaused(w http.ResponseWriter, r *http.Request, suffix string, paused bool) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, clientset := parseDeploymentAction(w, r, suffix)
+	if clientset == nil {
+		return
+	}
+
+	patch := fmt.Sprintf(`{"spec":{"paused":%t}}`, paused)
+	deployment, err := clientset.AppsV1().Deployments(namespace).Patch(r.Context(), name, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		writeResourceError(w, err, errMsgDeploymentNotFound, errMsgDeploymentPause)
+		return
+	}
+
+	message := "Deployment rollout resumed"
+	if paused {
+		message = "Deployment rollout paused"
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":    message,
+		"paused":     deployment.Spec.Paused,
+		"generation": deployment.Generation,
+	})
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// withRolloutState returns a copy of d with the given generation and status counts.
+func withRolloutState(d *appsv1.Deployment, generation, observed int64, replicas, updated, available int32) *appsv1.Deployment {
+	d = d.DeepCopy()
+	d.Generation = generation
+	d.Status.ObservedGeneration = observed
+	d.Status.Replicas = replicas
+	d.Status.UpdatedReplicas = updated
+	d.Status.ReadyReplicas = available
+	d.Status.AvailableReplicas = available
+	return d
+}
+
+func TestRolloutStatus(t *testing.T) {
+	base := newTestDeployment("default", "web", 3)
+	deadline := withRolloutState(base, 2, 2, 3, 1, 1)
+	deadline.Status.Conditions = []appsv1.DeploymentCondition{{
+		Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: reasonProgressDeadlineExceeded,
+	}}
+	paused := withRolloutState(base, 2, 2, 3, 1, 1)
+	paused.Spec.Paused = true
+
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		generation int64
+		phase      string
+		message    string
+	}{
+		{"spec not observed", withRolloutState(base, 2, 1, 3, 3, 3), 0, rolloutPhaseWaiting, "spec update"},
+		{"requested generation not observed", withRolloutState(base, 2, 2, 3, 3, 3), 3, rolloutPhaseWaiting, "spec update"},
+		{"updating", withRolloutState(base, 2, 2, 3, 1, 3), 0, rolloutPhaseWaiting, "1 of 3 updated"},
+		{"old replicas terminating", withRolloutState(base, 2, 2, 4, 3, 3), 0, rolloutPhaseWaiting, "1 old replicas"},
+		{"not yet available", withRolloutState(base, 2, 2, 3, 3, 2), 0, rolloutPhaseWaiting, "2 of 3 updated replicas are available"},
+		{"complete", withRolloutState(base, 2, 2, 3, 3, 3), 0, rolloutPhaseComplete, "successfully rolled out"},
+		{"deadline exceeded", deadline, 0, rolloutPhaseFailed, "progress deadline"},
+		{"paused", paused, 0, rolloutPhasePaused, "paused"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := rolloutStatus(tt.deployment, tt.generation)
+			if s.Phase != tt.phase || !strings.Contains(s.Message, tt.message) {
+				t.Errorf("got %s %q", s.Phase, s.Message)
+			}
+			if s.Done != (tt.phase == rolloutPhaseComplete || tt.phase == rolloutPhaseFailed) {
+				t.Errorf("done = %v for phase %s", s.Done, s.Phase)
+			}
+		})
+	}
+}
+
+func TestDeploymentRolloutStatusHandler_Streams(t *testing.T) {
+	base := newTestDeployment("default", "web", 2)
+	cs := fake.NewSimpleClientset(withRolloutState(base, 2, 1, 2, 0, 2))
+	fw := watch.NewFake()
+	cs.PrependWatchReactor("deployments", func(clienttesting.Action) (bool, watch.Interface, error) {
+		return true, fw, nil
+	})
+	withDeploymentClientset(t, cs)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		w := httptest.NewRecorder()
+		DeploymentActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/deployments/default/web/rollout-status?generation=2", nil))
+		done <- w
+	}()
+
+	// Each Modify blocks until the handler has received the event.
+	fw.Modify(withRolloutState(base, 2, 2, 3, 1, 2))
+	fw.Modify(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"}})
+	fw.Modify(withRolloutState(base, 2, 2, 2, 2, 2))
+
+	var w *httptest.ResponseRecorder
+	select {
+	case w = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream did not end after the rollout completed")
+	}
+	if w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected event stream, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var phases []string
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			var status RolloutStatus
+			if err := json.Unmarshal([]byte(data), &status); err != nil {
+				t.Fatalf("bad event %q: %v", data, err)
+			}
+			phases = append(phases, status.Phase)
+		}
+	}
+	if got := strings.Join(phases, ","); got != "waiting,waiting,complete" {
+		t.Errorf("phases = %s", got)
+	}
+}
+
+func TestDeploymentRolloutStatusHandler_RewatchesAfterError(t *testing.T) {
+	oldBackoff := rolloutRewatchBackoff
+	rolloutRewatchBackoff = time.Millisecond
+	t.Cleanup(func() { rolloutRewatchBackoff = oldBackoff })
+
+	base := newTestDeployment("default", "web", 2)
+	initial := withRolloutState(base, 2, 2, 2, 0, 2)
+	initial.ResourceVersion = "5"
+	cs := fake.NewSimpleClientset(initial)
+	watchers := []*watch.FakeWatcher{watch.NewFake(), watch.NewFake()}
+	var watchedVersions []string
+	watching := make(chan struct{}, len(watchers))
+	cs.PrependWatchReactor("deployments", func(action clienttesting.Action) (bool, watch.Interface, error) {
+		watchedVersions = append(watchedVersions, action.(clienttesting.WatchAction).GetWatchRestrictions().ResourceVersion)
+		if len(watchedVersions) > len(watchers) {
+			return true, nil, fmt.Errorf("unexpected watch %d", len(watchedVersions))
+		}
+		watching <- struct{}{}
+		return true, watchers[len(watchedVersions)-1], nil
+	})
+	withDeploymentClientset(t, cs)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		w := httptest.NewRecorder()
+		DeploymentActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/deployments/default/web/rollout-status", nil))
+		done <- w
+	}()
+
+	// The deployment progresses while the watch expires.
+	<-watching
+	updated := withRolloutState(base, 2, 2, 2, 1, 2)
+	updated.ResourceVersion = "9"
+	if err := cs.Tracker().Update(appsv1.SchemeGroupVersion.WithResource("deployments"), updated, "default"); err != nil {
+		t.Fatal(err)
+	}
+	watchers[0].Error(&metav1.Status{Status: metav1.StatusFailure, Code: http.StatusGone, Reason: metav1.StatusReasonExpired})
+	watchers[1].Modify(withRolloutState(base, 2, 2, 2, 2, 2))
+
+	var w *httptest.ResponseRecorder
+	select {
+	case w = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream did not end after the rollout completed")
+	}
+
+	var messages []string
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			var status RolloutStatus
+			if err := json.Unmarshal([]byte(data), &status); err != nil {
+				t.Fatalf("bad event %q: %v", data, err)
+			}
+			messages = append(messages, status.Phase+": "+status.Message)
+		}
+	}
+	if len(messages) != 3 || !strings.Contains(messages[1], "1 of 2 updated") || !strings.HasPrefix(messages[2], rolloutPhaseComplete) {
+		t.Errorf("expected the re-fetched status before completion, got %q", messages)
+	}
+	if strings.Join(watchedVersions, ",") != "5,9" {
+		t.Errorf("expected the watch to resume from the re-fetched version, got %v", watchedVersions)
+	}
+}
+
+func TestDeploymentRolloutStatusHandler_Errors(t *testing.T) {
+	withDeploymentClientset(t, fake.NewSimpleClientset())
+	for path, want := range map[string]int{
+		"/api/deployments/default/missing/rollout-status":              http.StatusNotFound,
+		"/api/deployments/default/web/rollout-status?generation=x":     http.StatusBadRequest,
+		"/api/deployments/default/web/extra/rollout-status?generation": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		DeploymentActionsHandler(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
+func TestDeploymentPauseResume(t *testing.T) {
+	cs := fake.NewSimpleClientset(newTestDeployment("default", "web", 1))
+	withDeploymentClientset(t, cs)
+
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"/api/deployments/default/web/pause", true},
+		{"/api/deployments/default/web/resume", false},
+	} {
+		w := httptest.NewRecorder()
+		DeploymentActionsHandler(w, httptest.NewRequest(http.MethodPost, tc.path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", tc.path, w.Code, w.Body.String())
+		}
+		d, _ := cs.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+		if d.Spec.Paused != tc.want {
+			t.Errorf("%s: paused = %v", tc.path, d.Spec.Paused)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	DeploymentActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/deployments/default/missing/pause", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing deployment: expected 404, got %d", w.Code)
+	}
+}
+
+func TestRestartDeploymentReturnsGeneration(t *testing.T) {
+	d := newTestDeployment("default", "web", 1)
+	cs := fake.NewSimpleClientset(d)
+	cs.PrependReactor("update", "deployments", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		updated := action.(clienttesting.UpdateAction).GetObject().(*appsv1.Deployment).DeepCopy()
+		updated.Generation = 5
+		return true, updated, nil
+	})
+
+	generation, err := restartDeployment(context.Background(), cs, "default", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generation != 5 {
+		t.Errorf("expected the generation of the updated deployment, got %d", generation)
+	}
+}
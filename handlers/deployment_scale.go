@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// scaleActionRestore restores the replica count recorded before scaling to zero.
+const scaleActionRestore = "restore"
+
+// getDeploymentClientset is a package-level variable for obtaining the Kubernetes client
+// used by the deployment, statefulset and daemonset action endpoints. Tests may override this.
+var getDeploymentClientset func() (kubernetes.Interface, error) = func() (kubernetes.Interface, error) {
+	return getKubernetesClient()
+}
+
+// scaleRequest is the JSON body for PUT /api/deployments/{ns}/{name}/scale.
+// Exactly one of Replicas and Action must be set.
+type scaleRequest struct {
+	Replicas *int32 `json:"replicas,omitempty"`
+	Action   string `json:"action,omitempty"`
+}
+
+// ScaleResponse reports the result of a scale operation.
+type ScaleResponse struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Replicas  int32  `json:"replicas"`
+	// PreviousReplicas is the replica count before this change; after scaling
+	// to zero it is also stored on the deployment so it can be restored.
+	PreviousReplicas int32  `json:"previousReplicas"`
+	Message          string `json:"message"`
+}
+
+// DeploymentScaleHandler handles PUT /api/deployments/{ns}/{name}/scale.
+func DeploymentScaleHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPut) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, clientset := parseDeploymentAction(w, r, scalePathSuffix)
+	if clientset == nil {
+		return
+	}
+
+	var req scaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errMsgScaleInvalidRequest)
+		return
+	}
+	if (req.Replicas == nil) == (req.Action == "") ||
+		(req.Replicas != nil && *req.Replicas < 0) ||
+		(req.Action != "" && req.Action != scaleActionRestore) {
+		writeError(w, http.StatusBadRequest, errMsgScaleInvalidRequest)
+		return
+	}
+
+	resp, err := scaleDeployment(r.Context(), clientset, namespace, name, req)
+	if err != nil {
+		writeDeploymentError(w, err, errMsgDeploymentScale)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// writeDeploymentError maps a deployment operation error onto an HTTP response.
+func writeDeploymentError(w http.ResponseWriter, err error, internalMsg string) {
+	writeWorkloadError(w, err, workloadDeployment, internalMsg)
+}
+
+// scaleDeployment changes the replica count through the scale subresource.
+// Scaling to zero records the current count in annotationPreviousReplicas first;
+// the restore action scales back to that count and removes the annotation.
+func scaleDeployment(ctx context.Context, clientset kubernetes.Interface, namespace, name string, req scaleRequest) (*ScaleResponse, error) {
+	deployments := clientset.AppsV1().Deployments(namespace)
+	deployment, err := deployments.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if hpa, err := findOwningHPA(ctx, clientset, namespace, "Deployment", name); err != nil {
+		return nil, err
+	} else if hpa != "" {
+		return nil, &statusError{status: http.StatusConflict, message: fmt.Sprintf(
+			"Replicas of deployment %s are managed by HorizontalPodAutoscaler %s; change its minReplicas/maxReplicas instead", name, hpa)}
+	}
+
+	scale, err := deployments.GetScale(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	current := scale.Spec.Replicas
+	recorded, hasRecorded := deployment.Annotations[annotationPreviousReplicas]
+
+	var target int32
+	if req.Action == scaleActionRestore {
+		if !hasRecorded {
+			return nil, &statusError{status: http.StatusConflict, message: errMsgScaleNothingToRestore}
+		}
+		if current != 0 {
+			return nil, &statusError{status: http.StatusConflict, message: errMsgScaleNotAtZero}
+		}
+		n, err := strconv.ParseInt(recorded, 10, 32)
+		if err != nil || n < 0 {
+			return nil, &statusError{status: http.StatusConflict, message: errMsgScaleNothingToRestore}
+		}
+		target = int32(n)
+	} else {
+		target = *req.Replicas
+	}
+
+	// Record the count before scaling so a failed scale never loses it.
+	if target == 0 && current > 0 {
+		if err := setPreviousReplicasAnnotation(ctx, clientset, namespace, name, strconv.Itoa(int(current))); err != nil {
+			return nil, err
+		}
+	}
+
+	if target != current {
+		scale.Spec.Replicas = target
+		if _, err := deployments.UpdateScale(ctx, name, scale, metav1.UpdateOptions{}); err != nil {
+			return nil, err
+		}
+	}
+
+	if target > 0 && hasRecorded {
+		if err := setPreviousReplicasAnnotation(ctx, clientset, namespace, name, ""); err != nil {
+			slog.Warn("Failed to clear previous replicas annotation", "error", err, "namespace", namespace, "deployment", name)
+		}
+	}
+
+	message := fmt.Sprintf("Deployment scaled from %d to %d replicas", current, target)
+	if req.Action == scaleActionRestore {
+		message = fmt.Sprintf("Deployment restored to %d replicas", target)
+	}
+	return &ScaleResponse{
+		Name:             name,
+		Namespace:        namespace,
+		Replicas:         target,
+		PreviousReplicas: current,
+		Message:          message,
+	}, nil
+}
+
+// setPreviousReplicasAnnotation sets annotationPreviousReplicas, or removes it when value is empty.
+func setPreviousReplicasAnnotation(ctx context.Context, clientset kubernetes.Interface, namespace, name, value string) error {
+	var annotation interface{}
+	if value != "" {
+		annotation = value
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{annotationPreviousReplicas: annotation},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// findOwningHPA returns the name of the HorizontalPodAutoscaler that targets the
+// given workload, or "" when none does.
+func findOwningHPA(ctx context.Context, clientset kubernetes.Interface, namespace, kind, name string) (string, error) {
+	hpaList, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		// Clusters without autoscaling/v2 cannot have an HPA owning the workload.
+		if k8serrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, hpa := range hpaList.Items {
+		ref := hpa.Spec.ScaleTargetRef
+		if ref.Kind == kind && ref.Name == name && strings.HasPrefix(ref.APIVersion, "apps/") {
+			return hpa.Name, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// withDeploymentClientset injects cs for deployment endpoints.
+func withDeploymentClientset(t *testing.T, cs kubernetes.Interface) {
+	t.Helper()
+	old := getDeploymentClientset
+	getDeploymentClientset = func() (kubernetes.Interface, error) { return cs, nil }
+	t.Cleanup(func() { getDeploymentClientset = old })
+}
+
+// newScalableClientset returns a fake clientset whose deployments/scale
+// subresource reads and writes spec.replicas of the stored deployment.
+func newScalableClientset(objects ...runtime.Object) *fake.Clientset {
+	cs := fake.NewSimpleClientset(objects...)
+	gvr := appsv1.SchemeGroupVersion.WithResource("deployments")
+	cs.PrependReactor("get", "deployments", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		obj, err := cs.Tracker().Get(gvr, action.GetNamespace(), action.(clienttesting.GetAction).GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		d := obj.(*appsv1.Deployment)
+		return true, &autoscalingv1.Scale{
+			ObjectMeta: metav1.ObjectMeta{Name: d.Name, Namespace: d.Namespace},
+			Spec:       autoscalingv1.ScaleSpec{Replicas: *d.Spec.Replicas},
+		}, nil
+	})
+	cs.PrependReactor("update", "deployments", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		scale := action.(clienttesting.UpdateAction).GetObject().(*autoscalingv1.Scale)
+		obj, err := cs.Tracker().Get(gvr, action.GetNamespace(), scale.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		d := obj.(*appsv1.Deployment).DeepCopy()
+		d.Spec.Replicas = &scale.Spec.Replicas
+		return true, scale, cs.Tracker().Update(gvr, d, action.GetNamespace())
+	})
+	return cs
+}
+
+func newTestDeployment(namespace, name string, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+}
+
+func putScale(t *testing.T, path, body string) (*httptest.ResponseRecorder, ScaleResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	DeploymentActionsHandler(w, httptest.NewRequest(http.MethodPut, path, strings.NewReader(body)))
+	var resp ScaleResponse
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w, resp
+}
+
+func TestDeploymentScale_ZeroAndRestore(t *testing.T) {
+	cs := newScalableClientset(newTestDeployment("default", "web", 3))
+	withDeploymentClientset(t, cs)
+	get := func() *appsv1.Deployment {
+		d, err := cs.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	w, resp := putScale(t, "/api/deployments/default/web/scale", `{"replicas":0}`)
+	if w.Code != http.StatusOK || resp.Replicas != 0 || resp.PreviousReplicas != 3 {
+		t.Fatalf("scale to zero: %d %s", w.Code, w.Body.String())
+	}
+	d := get()
+	if *d.Spec.Replicas != 0 || d.Annotations[annotationPreviousReplicas] != "3" {
+		t.Errorf("expected 0 replicas with 3 remembered, got %d %v", *d.Spec.Replicas, d.Annotations)
+	}
+
+	// Scaling an already-empty deployment to zero again must keep the remembered count.
+	putScale(t, "/api/deployments/default/web/scale", `{"replicas":0}`)
+	if get().Annotations[annotationPreviousReplicas] != "3" {
+		t.Error("remembered count overwritten by a no-op scale")
+	}
+
+	w, resp = putScale(t, "/api/deployments/default/web/scale", `{"action":"restore"}`)
+	if w.Code != http.StatusOK || resp.Replicas != 3 {
+		t.Fatalf("restore: %d %s", w.Code, w.Body.String())
+	}
+	d = get()
+	if *d.Spec.Replicas != 3 {
+		t.Errorf("expected 3 replicas after restore, got %d", *d.Spec.Replicas)
+	}
+	if _, ok := d.Annotations[annotationPreviousReplicas]; ok {
+		t.Error("annotation should be removed after restore")
+	}
+
+	w, _ = putScale(t, "/api/deployments/default/web/scale", `{"action":"restore"}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("restore without a recorded count: expected 409, got %d", w.Code)
+	}
+}
+
+func TestDeploymentScale_RefusesHPAOwned(t *testing.T) {
+	cs := newScalableClientset(newTestDeployment("default", "api", 2),
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-hpa"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "api"},
+				MaxReplicas:    5,
+			},
+		})
+	withDeploymentClientset(t, cs)
+
+	w, _ := putScale(t, "/api/deployments/default/api/scale", `{"replicas":4}`)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "api-hpa") {
+		t.Errorf("expected 409 naming the HPA, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDeploymentScale_Validation(t *testing.T) {
+	withDeploymentClientset(t, newScalableClientset(newTestDeployment("default", "web", 1)))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"negative replicas", http.MethodPut, "/api/deployments/default/web/scale", `{"replicas":-1}`, http.StatusBadRequest},
+		{"both replicas and action", http.MethodPut, "/api/deployments/default/web/scale", `{"replicas":1,"action":"restore"}`, http.StatusBadRequest},
+		{"unknown action", http.MethodPut, "/api/deployments/default/web/scale", `{"action":"double"}`, http.StatusBadRequest},
+		{"empty body", http.MethodPut, "/api/deployments/default/web/scale", `{}`, http.StatusBadRequest},
+		{"missing name", http.MethodPut, "/api/deployments/default//scale", `{"replicas":1}`, http.StatusBadRequest},
+		{"missing deployment", http.MethodPut, "/api/deployments/default/nope/scale", `{"replicas":1}`, http.StatusNotFound},
+		{"wrong method", http.MethodPost, "/api/deployments/default/web/scale", `{"replicas":1}`, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			DeploymentActionsHandler(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
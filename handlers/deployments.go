@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -54,6 +55,29 @@ func getDeploymentsData(ctx context.Context, clientset *kubernetes.Clientset, na
 	return deploymentsData, nil
 }
 
+// DeploymentActionsHandler handles the /api/deployments/:ns/:name/{action} endpoints,
+// dispatching on the path suffix.
+func DeploymentActionsHandler(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, scalePathSuffix):
+		DeploymentScaleHandler(w, r)
+	case strings.HasSuffix(r.URL.Path, historyPathSuffix):
+		DeploymentHistoryHandler(w, r)
+	case strings.HasSuffix(r.URL.Path, rollbackPathSuffix):
+		DeploymentRollbackHandler(w, r)
+	case strings.HasSuffix(r.URL.Path, rolloutStatusPathSuffix):
+		DeploymentRolloutStatusHandler(w, r)
+	case strings.HasSuffix(r.URL.Path, pausePathSuffix):
+		DeploymentPauseHandler(w, r)
+	case strings.HasSuffix(r.URL.Path, resumePathSuffix):
+		DeploymentResumeHandler(w, r)
+	case strings.HasSuffix(r.URL.Path, imagePathSuffix):
+		DeploymentImageHandler(w, r)
+	default:
+		DeploymentRestartHandler(w, r)
+	}
+}
+
 // DeploymentRestartHandler handles the POST /api/deployments/:ns/:name/restart endpoint
 func DeploymentRestartHandler(w http.ResponseWriter, r *http.Request) {
 	if !requireMethod(w, r, http.MethodPost) {
@@ -67,22 +91,24 @@ func DeploymentRestartHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := restartDeployment(r.Context(), rc.clientset, rc.namespace, rc.name)
+	generation, err := restartDeployment(r.Context(), rc.clientset, rc.namespace, rc.name)
 	if err != nil {
 		writeResourceError(w, err, errMsgDeploymentNotFound, err.Error())
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]string{
-		"message": "Deployment restarted successfully",
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":    "Deployment restarted successfully",
+		"generation": generation,
 	})
 }
 
-// restartDeployment restarts a deployment by adding/updating the restartedAt annotation
-func restartDeployment(ctx context.Context, clientset *kubernetes.Clientset, namespace, deploymentName string) error {
+// restartDeployment restarts a deployment by adding/updating the restartedAt annotation.
+// It returns the deployment generation to pass to the rollout status stream.
+func restartDeployment(ctx context.Context, clientset kubernetes.Interface, namespace, deploymentName string) (int64, error) {
 	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if deployment.Spec.Template.Annotations == nil {
@@ -91,6 +117,9 @@ func restartDeployment(ctx context.Context, clientset *kubernetes.Clientset, nam
 
 	deployment.Spec.Template.Annotations[annotationRestartedAt] = time.Now().Format(time.RFC3339)
 
-	_, err = clientset.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{})
-	return err
+	updated, err := clientset.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return updated.Generation, nil
 }
@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// eventsFollowTimeout bounds how long an events follow stream stays open.
+var eventsFollowTimeout = 30 * time.Minute
+
+// eventsFollowMaxOccurrences bounds how many occurrences a follow stream keeps
+// to deduplicate against; the least recently seen are forgotten first.
+var eventsFollowMaxOccurrences = 5000
+
+// eventsRewatchBackoff is the initial delay before a closed events watch is
+// re-established; it doubles, up to eventsRewatchMaxBackoff, while watches end
+// without delivering an event.
+var (
+	eventsRewatchBackoff    = time.Second
+	eventsRewatchMaxBackoff = 30 * time.Second
+)
+
+// getEventClientset is a package-level variable for obtaining the Kubernetes
+// client used by the events endpoint. Tests may override this.
+var getEventClientset func() (kubernetes.Interface, error) = func() (kubernetes.Interface, error) {
+	return getKubernetesClient()
+}
+
+// EventObject is the object an event is about.
+type EventObject struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	FieldPath string `json:"fieldPath,omitempty"`
+}
+
+// ClusterEvent is an event, or several events reporting the same occurrence
+// for the same object, with their counts added up.
+type ClusterEvent struct {
+	ResourceEvent
+	// Key identifies the occurrence; a followed stream resends an event with the
+	// same key when its count or last-seen time changes.
+	Key            string      `json:"key"`
+	Namespace      string      `json:"namespace"`
+	InvolvedObject EventObject `json:"involvedObject"`
+	FirstSeen      time.Time   `json:"firstSeen"`
+}
+
+// eventFilter holds the query filters of GET /api/events.
+type eventFilter struct {
+	namespace string
+	kind      string
+	name      string
+	eventType string
+	reason    string
+}
+
+// fieldSelector narrows the list or watch server-side. The filters are applied
+// again by matches, since not every client honours field selectors. The kind
+// is left to matches: field selectors compare exactly, and kind is matched
+// case-insensitively so that kind=pod finds Pod events.
+func (f eventFilter) fieldSelector() string {
+	set := fields.Set{}
+	if f.name != "" {
+		set["involvedObject.name"] = f.name
+	}
+	if f.eventType != "" {
+		set["type"] = f.eventType
+	}
+	if f.reason != "" {
+		set["reason"] = f.reason
+	}
+	return set.String()
+}
+
+func (f eventFilter) matches(ev *corev1.Event) bool {
+	return (f.kind == "" || strings.EqualFold(ev.InvolvedObject.Kind, f.kind)) &&
+		(f.name == "" || ev.InvolvedObject.Name == f.name) &&
+		(f.eventType == "" || ev.Type == f.eventType) &&
+		(f.reason == "" || ev.Reason == f.reason)
+}
+
+// EventsHandler handles GET /api/events. It lists events newest first, filtered
+// by the optional ns, kind, name, type and reason query parameters and capped by
+// limit. With follow=true it streams the matching events, then each new or
+// updated one, as server-sent events.
+func EventsHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := eventFilter{
+		namespace: query.Get("ns"),
+		kind:      query.Get("kind"),
+		name:      query.Get("name"),
+		eventType: query.Get("type"),
+		reason:    query.Get("reason"),
+	}
+	if filter.eventType != "" && filter.eventType != corev1.EventTypeNormal && filter.eventType != corev1.EventTypeWarning {
+		writeError(w, http.StatusBadRequest, errMsgEventTypeInvalid)
+		return
+	}
+	var limit int
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, errMsgListLimitInvalid)
+			return
+		}
+		limit = n
+	}
+	follow, _ := strconv.ParseBool(query.Get("follow"))
+
+	clientset, err := getEventClientset()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	if follow {
+		streamEvents(w, r, clientset, filter)
+		return
+	}
+
+	r = withTimeout(r)
+	agg, _, err := listEvents(r.Context(), clientset, filter)
+	if err != nil {
+		slog.Error("API handler error", "error", err, "path", r.URL.Path)
+		writeError(w, http.StatusInternalServerError, errMsgEventsFetch)
+		return
+	}
+	events := agg.summaries()
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+// listEvents lists the matching events into a new aggregator and returns the
+// list's resourceVersion to watch from.
+func listEvents(ctx context.Context, clientset kubernetes.Interface, filter eventFilter) (*eventAggregator, string, error) {
+	list, err := clientset.CoreV1().Events(filter.namespace).List(ctx, metav1.ListOptions{FieldSelector: filter.fieldSelector()})
+	if err != nil {
+		return nil, "", err
+	}
+	agg := newEventAggregator()
+	for i := range list.Items {
+		if filter.matches(&list.Items[i]) {
+			agg.add(list.Items[i])
+		}
+	}
+	return agg, list.ResourceVersion, nil
+}
+
+// streamEvents writes the matching events oldest first, then watches from the
+// list's resourceVersion and writes every new or updated occurrence.
+func streamEvents(w http.ResponseWriter, r *http.Request, clientset kubernetes.Interface, filter eventFilter) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), eventsFollowTimeout)
+	defer cancel()
+
+	agg, resourceVersion, err := listEvents(ctx, clientset, filter)
+	if err != nil {
+		slog.Error("API handler error", "error", err, "path", r.URL.Path)
+		writeError(w, http.StatusInternalServerError, errMsgEventsFetch)
+		return
+	}
+	agg.setLimit(eventsFollowMaxOccurrences)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	emit := func(event ClusterEvent) {
+		data, _ := json.Marshal(event)
+		fmt.Fprintf(w, "data: %s\n\n", data) //nolint:errcheck
+		flusher.Flush()
+	}
+
+	initial := agg.summaries()
+	for i := len(initial) - 1; i >= 0; i-- {
+		emit(initial[i])
+	}
+	watchEvents(ctx, clientset, filter, agg, resourceVersion, emit)
+}
+
+// watchEvents emits the aggregated occurrence of every added or modified event
+// until ctx ends. The watch is re-established, with backoff, from the last seen
+// resourceVersion if the server closes it; if that version has expired, the
+// watch restarts from a fresh list, and events in the gap are not emitted. Any
+// other watch error ends the stream.
+func watchEvents(ctx context.Context, clientset kubernetes.Interface, filter eventFilter, agg *eventAggregator, resourceVersion string, emit func(ClusterEvent)) {
+	backoff := eventsRewatchBackoff
+	for ctx.Err() == nil {
+		watcher, err := clientset.CoreV1().Events(filter.namespace).Watch(ctx, metav1.ListOptions{
+			FieldSelector:   filter.fieldSelector(),
+			ResourceVersion: resourceVersion,
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("Failed to watch events", "error", err)
+			}
+			return
+		}
+
+		progressed := false
+		var watchErr error
+		for event := range watcher.ResultChan() {
+			if event.Type == watch.Error {
+				watchErr = k8serrors.FromObject(event.Object)
+				break
+			}
+			ev, ok := event.Object.(*corev1.Event)
+			if !ok {
+				continue
+			}
+			progressed = true
+			resourceVersion = ev.ResourceVersion
+			switch {
+			case event.Type == watch.Deleted:
+				agg.remove(*ev)
+			case (event.Type == watch.Added || event.Type == watch.Modified) && filter.matches(ev):
+				emit(agg.add(*ev))
+			}
+		}
+		watcher.Stop()
+
+		if watchErr != nil && !k8serrors.IsResourceExpired(watchErr) && !k8serrors.IsGone(watchErr) {
+			slog.Warn("Event watch failed", "error", watchErr)
+			return
+		}
+
+		if progressed {
+			backoff = eventsRewatchBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, eventsRewatchMaxBackoff)
+
+		if watchErr != nil {
+			list, err := clientset.CoreV1().Events(filter.namespace).List(ctx, metav1.ListOptions{FieldSelector: filter.fieldSelector(), Limit: 1})
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Warn("Failed to relist events", "error", err)
+				}
+				return
+			}
+			resourceVersion = list.ResourceVersion
+		}
+	}
+}
+
+// eventAggregator deduplicates events: events about the same object with the
+// same type, reason and message are one occurrence whose count is the sum of
+// their counts (or series counts). With a limit, it keeps at most that many
+// occurrences.
+type eventAggregator struct {
+	groups map[string]map[types.UID]corev1.Event
+	limit  int
+}
+
+func newEventAggregator() *eventAggregator {
+	return &eventAggregator{groups: make(map[string]map[types.UID]corev1.Event)}
+}
+
+// add records ev, replacing an earlier version of the same event, and returns
+// its updated occurrence.
+func (a *eventAggregator) add(ev corev1.Event) ClusterEvent {
+	key := eventKey(&ev)
+	if a.groups[key] == nil {
+		a.groups[key] = make(map[types.UID]corev1.Event)
+	}
+	a.groups[key][eventID(&ev)] = ev
+	summary := a.summary(key)
+	if a.limit > 0 && len(a.groups) > a.limit {
+		a.evictOldest(key)
+	}
+	return summary
+}
+
+// remove forgets a deleted event.
+func (a *eventAggregator) remove(ev corev1.Event) {
+	key := eventKey(&ev)
+	delete(a.groups[key], eventID(&ev))
+	if len(a.groups[key]) == 0 {
+		delete(a.groups, key)
+	}
+}
+
+// setLimit caps the number of occurrences kept, evicting the least recently
+// seen ones beyond it.
+func (a *eventAggregator) setLimit(limit int) {
+	a.limit = limit
+	for limit > 0 && len(a.groups) > limit {
+		a.evictOldest("")
+	}
+}
+
+// evictOldest forgets the least recently seen occurrence other than keep.
+func (a *eventAggregator) evictOldest(keep string) {
+	var oldestKey string
+	var oldest time.Time
+	for key, group := range a.groups {
+		if key == keep {
+			continue
+		}
+		var last time.Time
+		for _, ev := range group {
+			if t := eventTime(ev); t.After(last) {
+				last = t
+			}
+		}
+		if oldestKey == "" || last.Before(oldest) {
+			oldestKey, oldest = key, last
+		}
+	}
+	delete(a.groups, oldestKey)
+}
+
+// summaries returns every occurrence, newest first.
+func (a *eventAggregator) summaries() []ClusterEvent {
+	events := make([]ClusterEvent, 0, len(a.groups))
+	for key := range a.groups {
+		events = append(events, a.summary(key))
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].LastSeen.Equal(events[j].LastSeen) {
+			return events[i].LastSeen.After(events[j].LastSeen)
+		}
+		return events[i].Key < events[j].Key
+	})
+	return events
+}
+
+func (a *eventAggregator) summary(key string) ClusterEvent {
+	var out ClusterEvent
+	var count int32
+	for _, ev := range a.groups[key] {
+		count += eventCount(ev)
+		if first := eventFirstSeen(ev); out.FirstSeen.IsZero() || first.Before(out.FirstSeen) {
+			out.FirstSeen = first
+		}
+		if out.Key == "" || eventTime(ev).After(out.LastSeen) {
+			out.ResourceEvent = buildResourceEvent(ev)
+			out.Key = key
+			out.Namespace = ev.Namespace
+			out.InvolvedObject = EventObject{
+				Kind:      ev.InvolvedObject.Kind,
+				Name:      ev.InvolvedObject.Name,
+				Namespace: ev.InvolvedObject.Namespace,
+				FieldPath: ev.InvolvedObject.FieldPath,
+			}
+		}
+	}
+	out.Count = count
+	return out
+}
+
+// eventKey identifies what an event reports, independent of which Event object
+// (and which series of it) reported it.
+func eventKey(ev *corev1.Event) string {
+	obj := ev.InvolvedObject
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		ev.Namespace, obj.Kind, obj.Namespace, obj.Name, obj.FieldPath, ev.Type, ev.Reason, ev.Message,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// eventID identifies an Event object.
+func eventID(ev *corev1.Event) types.UID {
+	if ev.UID != "" {
+		return ev.UID
+	}
+	return types.UID(ev.Namespace + "/" + ev.Name)
+}
+
+// eventFirstSeen returns when the event was first reported.
+func eventFirstSeen(ev corev1.Event) time.Time {
+	switch {
+	case !ev.FirstTimestamp.IsZero():
+		return ev.FirstTimestamp.Time
+	case !ev.EventTime.IsZero():
+		return ev.EventTime.Time
+	default:
+		return ev.CreationTimestamp.Time
+	}
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// withEventClientset injects cs for the events endpoint.
+func withEventClientset(t *testing.T, cs kubernetes.Interface) {
+	t.Helper()
+	old := getEventClientset
+	getEventClientset = func() (kubernetes.Interface, error) { return cs, nil }
+	t.Cleanup(func() { getEventClientset = old })
+}
+
+// newTestEvent returns an event about the named pod, last seen at lastSeen.
+func newTestEvent(name, pod, eventType, reason string, count int32, lastSeen time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name + "-uid")},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: pod},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        reason + " on " + pod,
+		Count:          count,
+		FirstTimestamp: metav1.Time{Time: lastSeen.Add(-time.Hour)},
+		LastTimestamp:  metav1.Time{Time: lastSeen},
+	}
+}
+
+func getEvents(t *testing.T, query string) (*httptest.ResponseRecorder, []ClusterEvent) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	EventsHandler(w, httptest.NewRequest(http.MethodGet, "/api/events"+query, nil))
+	var events []ClusterEvent
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &events); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	return w, events
+}
+
+func TestEventsHandler_FiltersAndDeduplicates(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	series := newTestEvent("backoff-series", "web-1", corev1.EventTypeWarning, "BackOff", 0, now)
+	series.Series = &corev1.EventSeries{Count: 4, LastObservedTime: metav1.MicroTime{Time: now}}
+	withEventClientset(t, fake.NewSimpleClientset(
+		newTestEvent("backoff", "web-1", corev1.EventTypeWarning, "BackOff", 3, now.Add(-time.Minute)),
+		series,
+		newTestEvent("pulled", "web-1", corev1.EventTypeNormal, "Pulled", 1, now.Add(-2*time.Minute)),
+		newTestEvent("other", "db-0", corev1.EventTypeWarning, "BackOff", 1, now.Add(-3*time.Minute)),
+	))
+
+	w, events := getEvents(t, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 deduplicated events, got %+v", events)
+	}
+	backoff := events[0]
+	if backoff.Reason != "BackOff" || backoff.Count != 7 || !backoff.LastSeen.Equal(now) {
+		t.Errorf("duplicate events should be merged: %+v", backoff)
+	}
+	if !backoff.FirstSeen.Equal(now.Add(-time.Minute - time.Hour)) {
+		t.Errorf("first seen should be the earliest: %v", backoff.FirstSeen)
+	}
+	if backoff.InvolvedObject.Kind != "Pod" || backoff.InvolvedObject.Name != "web-1" || backoff.Key == "" {
+		t.Errorf("unexpected involved object: %+v", backoff)
+	}
+
+	if _, events := getEvents(t, "?type=Warning&kind=pod&name=web-1"); len(events) != 1 || events[0].Reason != "BackOff" {
+		t.Errorf("unexpected filtered events: %+v", events)
+	}
+	if _, events := getEvents(t, "?reason=BackOff&limit=1"); len(events) != 1 || events[0].InvolvedObject.Name != "web-1" {
+		t.Errorf("unexpected limited events: %+v", events)
+	}
+}
+
+func TestEventFilter_FieldSelector(t *testing.T) {
+	filter := eventFilter{kind: "pod", name: "web-1", eventType: corev1.EventTypeWarning}
+	selector := filter.fieldSelector()
+	if strings.Contains(selector, "involvedObject.kind") {
+		t.Errorf("kind must be matched case-insensitively, not by field selector: %s", selector)
+	}
+	if !strings.Contains(selector, "involvedObject.name=web-1") || !strings.Contains(selector, "type=Warning") {
+		t.Errorf("unexpected selector: %s", selector)
+	}
+}
+
+func TestEventAggregator_RemoveAndLimit(t *testing.T) {
+	now := time.Now()
+	agg := newEventAggregator()
+	agg.add(*newTestEvent("old", "web-1", corev1.EventTypeWarning, "BackOff", 1, now.Add(-time.Hour)))
+	agg.add(*newTestEvent("mid", "web-2", corev1.EventTypeWarning, "BackOff", 1, now.Add(-time.Minute)))
+	agg.add(*newTestEvent("new", "web-3", corev1.EventTypeWarning, "BackOff", 1, now))
+
+	agg.remove(*newTestEvent("mid", "web-2", corev1.EventTypeWarning, "BackOff", 1, now))
+	if got := agg.summaries(); len(got) != 2 || got[0].InvolvedObject.Name != "web-3" || got[1].InvolvedObject.Name != "web-1" {
+		t.Fatalf("deleted events should be forgotten: %+v", got)
+	}
+
+	agg.setLimit(2)
+	agg.add(*newTestEvent("newest", "web-4", corev1.EventTypeWarning, "BackOff", 1, now.Add(time.Minute)))
+	var names []string
+	for _, ev := range agg.summaries() {
+		names = append(names, ev.InvolvedObject.Name)
+	}
+	if strings.Join(names, ",") != "web-4,web-3" {
+		t.Errorf("the least recently seen occurrence should be evicted, got %v", names)
+	}
+}
+
+func TestEventsHandler_InvalidQuery(t *testing.T) {
+	withEventClientset(t, fake.NewSimpleClientset())
+
+	for _, query := range []string{"?type=Error", "?limit=-1", "?limit=x"} {
+		if w, _ := getEvents(t, query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestEventsHandler_Follow(t *testing.T) {
+	now := time.Now()
+	cs := fake.NewSimpleClientset(newTestEvent("backoff", "web-1", corev1.EventTypeWarning, "BackOff", 2, now))
+	fw := watch.NewFake()
+	cs.PrependWatchReactor("events", func(clienttesting.Action) (bool, watch.Interface, error) {
+		return true, fw, nil
+	})
+	withEventClientset(t, cs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		w := httptest.NewRecorder()
+		EventsHandler(w, httptest.NewRequest(http.MethodGet, "/api/events?follow=true&type=Warning", nil).WithContext(ctx))
+		done <- w
+	}()
+
+	// Each Add and Modify blocks until the handler has received the event.
+	fw.Modify(newTestEvent("backoff", "web-1", corev1.EventTypeWarning, "BackOff", 5, now.Add(time.Minute)))
+	fw.Add(newTestEvent("pulled", "web-1", corev1.EventTypeNormal, "Pulled", 1, now))
+	fw.Add(newTestEvent("failed", "web-2", corev1.EventTypeWarning, "Failed", 1, now))
+	cancel()
+	fw.Stop()
+
+	var w *httptest.ResponseRecorder
+	select {
+	case w = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream did not end after the request was cancelled")
+	}
+	if w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected event stream, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got []string
+	var keys []string
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			var ev ClusterEvent
+			if err := json.Unmarshal([]byte(data), &ev); err != nil {
+				t.Fatalf("bad event %q: %v", data, err)
+			}
+			got = append(got, ev.Reason+"/"+ev.InvolvedObject.Name)
+			keys = append(keys, ev.Key)
+			if ev.Reason == "BackOff" && len(got) == 2 && ev.Count != 5 {
+				t.Errorf("updated event should carry the new count: %+v", ev)
+			}
+		}
+	}
+	if strings.Join(got, ",") != "BackOff/web-1,BackOff/web-1,Failed/web-2" {
+		t.Errorf("streamed events = %v", got)
+	}
+	if keys[0] != keys[1] || keys[1] == keys[2] {
+		t.Errorf("an updated event should keep its key: %v", keys)
+	}
+}
+
+func TestEventsHandler_FollowWatchErrors(t *testing.T) {
+	oldBackoff, oldMax := eventsRewatchBackoff, eventsRewatchMaxBackoff
+	eventsRewatchBackoff, eventsRewatchMaxBackoff = time.Millisecond, time.Millisecond
+	t.Cleanup(func() { eventsRewatchBackoff, eventsRewatchMaxBackoff = oldBackoff, oldMax })
+
+	cs := fake.NewSimpleClientset()
+	watchers := make(chan *watch.FakeWatcher, 3)
+	watches := 0
+	cs.PrependWatchReactor("events", func(clienttesting.Action) (bool, watch.Interface, error) {
+		watches++
+		fw := watch.NewFake()
+		watchers <- fw
+		return true, fw, nil
+	})
+	withEventClientset(t, cs)
+
+	done := make(chan struct{})
+	go func() {
+		EventsHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/events?follow=true", nil))
+		close(done)
+	}()
+
+	// An expired resourceVersion relists and watches again.
+	expired := k8serrors.NewResourceExpired("too old resource version")
+	(<-watchers).Error(&expired.ErrStatus)
+	// Any other error ends the stream instead of rewatching.
+	forbidden := k8serrors.NewForbidden(schema.GroupResource{Resource: "events"}, "", nil)
+	(<-watchers).Error(&forbidden.ErrStatus)
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream did not end after a non-expiry watch error")
+	}
+	if watches != 2 {
+		t.Errorf("expected 2 watches, got %d", watches)
+	}
+}
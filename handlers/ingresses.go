@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// IngressBackendInfo is an ingress backend resolved against its Service.
+type IngressBackendInfo struct {
+	// Service and Port name the backend Service; Port is a number or port name.
+	Service string `json:"service,omitempty"`
+	Port    string `json:"port,omitempty"`
+	// Resource names a non-Service backend as Kind/name.
+	Resource     string         `json:"resource,omitempty"`
+	ServiceFound bool           `json:"serviceFound"`
+	PortFound    bool           `json:"portFound"`
+	Endpoints    EndpointCounts `json:"endpoints"`
+	// Pods are the pods selected by the Service.
+	Pods []string `json:"pods"`
+	// NoMatchingPods is set when the Service has a selector that matches no pods.
+	NoMatchingPods bool `json:"noMatchingPods"`
+}
+
+// IngressPathInfo is a path of an ingress rule.
+type IngressPathInfo struct {
+	Path     string             `json:"path"`
+	PathType string             `json:"pathType,omitempty"`
+	Backend  IngressBackendInfo `json:"backend"`
+}
+
+// IngressRuleInfo is a host rule of an ingress; Host is empty for all hosts.
+type IngressRuleInfo struct {
+	Host  string            `json:"host,omitempty"`
+	Paths []IngressPathInfo `json:"paths"`
+}
+
+// IngressTLSInfo is a TLS entry of an ingress.
+type IngressTLSInfo struct {
+	Hosts      []string `json:"hosts"`
+	SecretName string   `json:"secretName,omitempty"`
+}
+
+// IngressInfo represents summary information about an ingress
+type IngressInfo struct {
+	Name           string              `json:"name"`
+	Namespace      string              `json:"namespace"`
+	ClassName      string              `json:"className,omitempty"`
+	Hosts          []string            `json:"hosts"`
+	Addresses      []string            `json:"addresses"`
+	Rules          []IngressRuleInfo   `json:"rules"`
+	DefaultBackend *IngressBackendInfo `json:"defaultBackend,omitempty"`
+	TLS            []IngressTLSInfo    `json:"tls"`
+	// UnhealthyBackends counts backends whose Service or port is missing or
+	// whose Service selects no pods.
+	UnhealthyBackends int    `json:"unhealthyBackends"`
+	Age               string `json:"age"`
+}
+
+// IngressDetail is the response of GET /api/ingresses/{ns}/{name}.
+type IngressDetail struct {
+	IngressInfo
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// IngressesHandler handles the GET /api/ingresses endpoint
+var IngressesHandler = handleGet("Failed to fetch ingresses data", func(r *http.Request) (interface{}, error) {
+	clientset, err := getNetworkClientset()
+	if err != nil {
+		return nil, err
+	}
+	return getIngressesData(r.Context(), clientset, r.URL.Query().Get("ns"))
+})
+
+func getIngressesData(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]IngressInfo, error) {
+	list, err := clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	idx, err := loadServiceIndex(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	ingresses := make([]IngressInfo, 0, len(list.Items))
+	for i := range list.Items {
+		ingresses = append(ingresses, idx.ingressInfo(&list.Items[i]))
+	}
+	sort.Slice(ingresses, func(i, j int) bool {
+		if ingresses[i].Namespace != ingresses[j].Namespace {
+			return ingresses[i].Namespace < ingresses[j].Namespace
+		}
+		return ingresses[i].Name < ingresses[j].Name
+	})
+	return ingresses, nil
+}
+
+// IngressDetailHandler handles GET /api/ingresses/{ns}/{name}.
+func IngressDetailHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, clientset := parseNamespacedAction(w, r, ingressesPathPrefix, "", getNetworkClientset)
+	if clientset == nil {
+		return
+	}
+
+	detail, err := getIngressDetail(r.Context(), clientset, namespace, name)
+	if err != nil {
+		writeResourceError(w, err, errMsgIngressNotFound, errMsgIngressFetch)
+		return
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+func getIngressDetail(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*IngressDetail, error) {
+	ing, err := clientset.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	idx, err := loadServiceIndex(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &IngressDetail{
+		IngressInfo: idx.ingressInfo(ing),
+		Labels:      ing.Labels,
+		Annotations: ing.Annotations,
+	}, nil
+}
+
+func (idx *serviceIndex) ingressInfo(ing *networkingv1.Ingress) IngressInfo {
+	info := IngressInfo{
+		Name:      ing.Name,
+		Namespace: ing.Namespace,
+		Hosts:     []string{},
+		Addresses: []string{},
+		Rules:     make([]IngressRuleInfo, 0, len(ing.Spec.Rules)),
+		TLS:       make([]IngressTLSInfo, 0, len(ing.Spec.TLS)),
+		Age:       formatPodAge(ing.CreationTimestamp.Time),
+	}
+	if ing.Spec.IngressClassName != nil {
+		info.ClassName = *ing.Spec.IngressClassName
+	}
+	for _, lb := range ing.Status.LoadBalancer.Ingress {
+		info.Addresses = append(info.Addresses, loadBalancerAddress(lb.IP, lb.Hostname))
+	}
+	for _, tls := range ing.Spec.TLS {
+		hosts := tls.Hosts
+		if hosts == nil {
+			hosts = []string{}
+		}
+		info.TLS = append(info.TLS, IngressTLSInfo{Hosts: hosts, SecretName: tls.SecretName})
+	}
+
+	addBackend := func(backend *networkingv1.IngressBackend) IngressBackendInfo {
+		resolved := idx.resolveIngressBackend(ing.Namespace, backend)
+		if resolved.Service != "" && (!resolved.ServiceFound || !resolved.PortFound || resolved.NoMatchingPods) {
+			info.UnhealthyBackends++
+		}
+		return resolved
+	}
+	if ing.Spec.DefaultBackend != nil {
+		backend := addBackend(ing.Spec.DefaultBackend)
+		info.DefaultBackend = &backend
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.Host != "" && !containsString(info.Hosts, rule.Host) {
+			info.Hosts = append(info.Hosts, rule.Host)
+		}
+		ruleInfo := IngressRuleInfo{Host: rule.Host, Paths: []IngressPathInfo{}}
+		if rule.HTTP != nil {
+			for i := range rule.HTTP.Paths {
+				path := &rule.HTTP.Paths[i]
+				pathInfo := IngressPathInfo{Path: path.Path, Backend: addBackend(&path.Backend)}
+				if path.PathType != nil {
+					pathInfo.PathType = string(*path.PathType)
+				}
+				ruleInfo.Paths = append(ruleInfo.Paths, pathInfo)
+			}
+		}
+		info.Rules = append(info.Rules, ruleInfo)
+	}
+	return info
+}
+
+// resolveIngressBackend looks up the backend's Service, port, endpoints and pods.
+func (idx *serviceIndex) resolveIngressBackend(namespace string, backend *networkingv1.IngressBackend) IngressBackendInfo {
+	info := IngressBackendInfo{Pods: []string{}}
+	if backend.Resource != nil {
+		info.Resource = backend.Resource.Kind + "/" + backend.Resource.Name
+		return info
+	}
+	if backend.Service == nil {
+		return info
+	}
+
+	info.Service = backend.Service.Name
+	info.Port = backend.Service.Port.Name
+	if info.Port == "" {
+		info.Port = strconv.Itoa(int(backend.Service.Port.Number))
+	}
+	svc := idx.services[namespace+"/"+backend.Service.Name]
+	if svc == nil {
+		return info
+	}
+	info.ServiceFound = true
+	info.PortFound = serviceHasPort(svc, backend.Service.Port)
+	info.Endpoints = idx.endpointCounts(namespace, svc.Name)
+	pods := idx.matchingPods(svc)
+	for _, pod := range pods {
+		info.Pods = append(info.Pods, pod.Name)
+	}
+	info.NoMatchingPods = len(svc.Spec.Selector) > 0 && len(pods) == 0
+	return info
+}
+
+// serviceHasPort reports whether the service exposes the backend port.
+func serviceHasPort(svc *corev1.Service, port networkingv1.ServiceBackendPort) bool {
+	for _, p := range svc.Spec.Ports {
+		if (port.Name != "" && p.Name == port.Name) || (port.Name == "" && p.Port == port.Number) {
+			return true
+		}
+	}
+	return false
+}
+
+// ingressServiceBackends returns the Service backends of the ingress, including
+// its default backend.
+func ingressServiceBackends(ing *networkingv1.Ingress) []*networkingv1.IngressServiceBackend {
+	var backends []*networkingv1.IngressServiceBackend
+	if b := ing.Spec.DefaultBackend; b != nil && b.Service != nil {
+		backends = append(backends, b.Service)
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil {
+				backends = append(backends, path.Backend.Service)
+			}
+		}
+	}
+	return backends
+}
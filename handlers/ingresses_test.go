@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func ingressPath(path, service string, port networkingv1.ServiceBackendPort) networkingv1.HTTPIngressPath {
+	pathType := networkingv1.PathTypePrefix
+	return networkingv1.HTTPIngressPath{
+		Path:     path,
+		PathType: &pathType,
+		Backend:  networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: service, Port: port}},
+	}
+}
+
+func newTestIngress() *networkingv1.Ingress {
+	className := "nginx"
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "site"},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &className,
+			TLS:              []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "site-tls"}},
+			Rules: []networkingv1.IngressRule{{
+				Host: "example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+					ingressPath("/", "web", networkingv1.ServiceBackendPort{Name: "http"}),
+					ingressPath("/api", "orphan", networkingv1.ServiceBackendPort{Number: 80}),
+					ingressPath("/old", "missing", networkingv1.ServiceBackendPort{Number: 80}),
+					ingressPath("/admin", "web", networkingv1.ServiceBackendPort{Number: 8080}),
+				}}},
+			}},
+		},
+		Status: networkingv1.IngressStatus{LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+			Ingress: []networkingv1.IngressLoadBalancerIngress{{IP: "203.0.113.1"}, {Hostname: "lb.example.net"}},
+		}},
+	}
+}
+
+func TestGetIngressesData(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		newTestIngress(),
+		newTestService("web", map[string]string{"app": "web"}),
+		newTestService("orphan", map[string]string{"app": "gone"}),
+		newLabeledPod("web-1", map[string]string{"app": "web"}, true),
+		newTestEndpointSlice("web-v4", "web", discoveryv1.AddressTypeIPv4, map[string]bool{"web-1": true}),
+	)
+
+	ingresses, err := getIngressesData(context.Background(), cs, "")
+	if err != nil {
+		t.Fatalf("getIngressesData: %v", err)
+	}
+	if len(ingresses) != 1 {
+		t.Fatalf("expected 1 ingress, got %d", len(ingresses))
+	}
+	ing := ingresses[0]
+	if ing.ClassName != "nginx" || len(ing.Hosts) != 1 || ing.Hosts[0] != "example.com" {
+		t.Errorf("unexpected ingress: %+v", ing)
+	}
+	if len(ing.Addresses) != 2 || ing.Addresses[0] != "203.0.113.1" || ing.Addresses[1] != "lb.example.net" {
+		t.Errorf("unexpected addresses: %v", ing.Addresses)
+	}
+	if len(ing.TLS) != 1 || ing.TLS[0].SecretName != "site-tls" {
+		t.Errorf("unexpected TLS: %+v", ing.TLS)
+	}
+	if ing.UnhealthyBackends != 3 {
+		t.Errorf("expected 3 unhealthy backends, got %d", ing.UnhealthyBackends)
+	}
+
+	paths := ing.Rules[0].Paths
+	web := paths[0].Backend
+	if !web.ServiceFound || !web.PortFound || web.NoMatchingPods || len(web.Pods) != 1 || web.Pods[0] != "web-1" || web.Endpoints.Ready != 1 {
+		t.Errorf("unexpected web backend: %+v", web)
+	}
+	if orphan := paths[1].Backend; !orphan.ServiceFound || !orphan.NoMatchingPods {
+		t.Errorf("backend selecting no pods should be flagged: %+v", orphan)
+	}
+	if missing := paths[2].Backend; missing.ServiceFound {
+		t.Errorf("missing service should not be found: %+v", missing)
+	}
+	if badPort := paths[3].Backend; !badPort.ServiceFound || badPort.PortFound || badPort.Port != "8080" {
+		t.Errorf("unknown service port should not be found: %+v", badPort)
+	}
+}
+
+func TestIngressDetailHandler(t *testing.T) {
+	ing := newTestIngress()
+	ing.Annotations = map[string]string{"nginx.ingress.kubernetes.io/rewrite-target": "/"}
+	ing.Spec.DefaultBackend = &networkingv1.IngressBackend{Resource: &corev1.TypedLocalObjectReference{Kind: "StorageBucket", Name: "static"}}
+	withNetworkClientset(t, fake.NewSimpleClientset(ing))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/ingresses/default/site", nil)
+	w := httptest.NewRecorder()
+	IngressDetailHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var detail IngressDetail
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if detail.Annotations["nginx.ingress.kubernetes.io/rewrite-target"] != "/" {
+		t.Errorf("unexpected annotations: %v", detail.Annotations)
+	}
+	if detail.DefaultBackend == nil || detail.DefaultBackend.Resource != "StorageBucket/static" {
+		t.Errorf("unexpected default backend: %+v", detail.DefaultBackend)
+	}
+	// Resource backends are not Services and are never counted as unhealthy.
+	if detail.UnhealthyBackends != 4 {
+		t.Errorf("expected 4 unhealthy backends, got %d", detail.UnhealthyBackends)
+	}
+}
+
+func TestIngressDetailHandler_NotFound(t *testing.T) {
+	withNetworkClientset(t, fake.NewSimpleClientset())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/ingresses/default/missing", nil)
+	w := httptest.NewRecorder()
+	IngressDetailHandler(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Job status strings.
+const (
+	jobStatusRunning   = "Running"
+	jobStatusComplete  = "Complete"
+	jobStatusFailed    = "Failed"
+	jobStatusSuspended = "Suspended"
+)
+
+// getJobClientset is a package-level variable for obtaining the Kubernetes client
+// used by the job and cronjob endpoints. Tests may override this.
+var getJobClientset func() (kubernetes.Interface, error) = func() (kubernetes.Interface, error) {
+	return getKubernetesClient()
+}
+
+// JobInfo represents summary information about a job
+type JobInfo struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Status    string `json:"status"`
+	// Completions is the number of successful pods required; 1 when unset.
+	Completions int32 `json:"completions"`
+	Succeeded   int32 `json:"succeeded"`
+	Failed      int32 `json:"failed"`
+	Active      int32 `json:"active"`
+	// CronJob names the CronJob that created the job, if any.
+	CronJob        string     `json:"cronJob,omitempty"`
+	StartTime      *time.Time `json:"startTime,omitempty"`
+	CompletionTime *time.Time `json:"completionTime,omitempty"`
+	// Duration runs from the start time to completion, or to now while the job runs.
+	Duration        string `json:"duration,omitempty"`
+	DurationSeconds int64  `json:"durationSeconds"`
+	Age             string `json:"age"`
+}
+
+// JobDetail is the response of GET /api/jobs/{ns}/{name}.
+type JobDetail struct {
+	JobInfo
+	Labels                map[string]string   `json:"labels"`
+	Parallelism           *int32              `json:"parallelism,omitempty"`
+	BackoffLimit          *int32              `json:"backoffLimit,omitempty"`
+	ActiveDeadlineSeconds *int64              `json:"activeDeadlineSeconds,omitempty"`
+	CompletionMode        string              `json:"completionMode,omitempty"`
+	Images                []string            `json:"images"`
+	Conditions            []WorkloadCondition `json:"conditions"`
+	Pods                  []PodDetails        `json:"pods"`
+}
+
+// JobsHandler handles the GET /api/jobs endpoint
+var JobsHandler = handleGet("Failed to fetch jobs data", func(r *http.Request) (interface{}, error) {
+	clientset, err := getJobClientset()
+	if err != nil {
+		return nil, err
+	}
+	return getJobsData(r.Context(), clientset, r.URL.Query().Get("ns"))
+})
+
+func getJobsData(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]JobInfo, error) {
+	list, err := clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]JobInfo, 0, len(list.Items))
+	for i := range list.Items {
+		jobs = append(jobs, buildJobInfo(&list.Items[i], time.Now()))
+	}
+	sortJobsNewestFirst(jobs)
+	return jobs, nil
+}
+
+// JobDetailHandler handles the /api/jobs/{ns}/{name} endpoint.
+// Supports GET (detail) and DELETE (deletes the job and its pods).
+func JobDetailHandler(w http.ResponseWriter, r *http.Request) {
+	r = withTimeout(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		handleGetJobDetail(w, r)
+	case http.MethodDelete:
+		handleDeleteJob(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func handleGetJobDetail(w http.ResponseWriter, r *http.Request) {
+	namespace, name, clientset := parseJobPath(w, r, jobsPathPrefix, "")
+	if clientset == nil {
+		return
+	}
+
+	detail, err := getJobDetail(r.Context(), clientset, namespace, name)
+	if err != nil {
+		writeResourceError(w, err, errMsgJobNotFound, errMsgJobFetch)
+		return
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+func handleDeleteJob(w http.ResponseWriter, r *http.Request) {
+	namespace, name, clientset := parseJobPath(w, r, jobsPathPrefix, "")
+	if clientset == nil {
+		return
+	}
+
+	// Jobs default to orphaning their pods; background propagation removes them too.
+	propagation := metav1.DeletePropagationBackground
+	err := clientset.BatchV1().Jobs(namespace).Delete(r.Context(), name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil {
+		writeResourceError(w, err, errMsgJobNotFound, errMsgJobDelete)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message": "Job deleted successfully",
+	})
+}
+
+// parseJobPath parses {prefix}{ns}/{name}{suffix} and obtains the client. It
+// writes an error response and returns a nil client on failure.
+func parseJobPath(w http.ResponseWriter, r *http.Request, prefix, suffix string) (string, string, kubernetes.Interface) {
+	return parseNamespacedAction(w, r, prefix, suffix, getJobClientset)
+}
+
+func getJobDetail(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*JobDetail, error) {
+	job, err := clientset.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := listWorkloadPods(ctx, clientset, &workload{ObjectMeta: job.ObjectMeta, Selector: job.Spec.Selector})
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &JobDetail{
+		JobInfo:               buildJobInfo(job, time.Now()),
+		Labels:                job.Labels,
+		Parallelism:           job.Spec.Parallelism,
+		BackoffLimit:          job.Spec.BackoffLimit,
+		ActiveDeadlineSeconds: job.Spec.ActiveDeadlineSeconds,
+		Images:                templateImages(job.Spec.Template),
+		Conditions:            make([]WorkloadCondition, 0, len(job.Status.Conditions)),
+		Pods:                  pods,
+	}
+	if job.Spec.CompletionMode != nil {
+		detail.CompletionMode = string(*job.Spec.CompletionMode)
+	}
+	for _, c := range job.Status.Conditions {
+		detail.Conditions = append(detail.Conditions, WorkloadCondition{
+			Type:               string(c.Type),
+			Status:             string(c.Status),
+			Reason:             c.Reason,
+			Message:            c.Message,
+			LastTransitionTime: c.LastTransitionTime.Time,
+		})
+	}
+	return detail, nil
+}
+
+// buildJobInfo summarizes a job; now is the end of the duration of a running job.
+func buildJobInfo(job *batchv1.Job, now time.Time) JobInfo {
+	completions := int32(1)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+	info := JobInfo{
+		Name:        job.Name,
+		Namespace:   job.Namespace,
+		Status:      jobStatus(job),
+		Completions: completions,
+		Succeeded:   job.Status.Succeeded,
+		Failed:      job.Status.Failed,
+		Active:      job.Status.Active,
+		Age:         formatPodAge(job.CreationTimestamp.Time),
+	}
+	if owner := metav1.GetControllerOf(job); owner != nil && owner.Kind == "CronJob" {
+		info.CronJob = owner.Name
+	}
+
+	if job.Status.StartTime != nil {
+		start := job.Status.StartTime.Time
+		info.StartTime = &start
+		end := now
+		if job.Status.CompletionTime != nil {
+			end = job.Status.CompletionTime.Time
+			info.CompletionTime = &end
+		} else if failed := jobCondition(job, batchv1.JobFailed); failed != nil {
+			end = failed.LastTransitionTime.Time
+		}
+		duration := end.Sub(start)
+		info.DurationSeconds = int64(duration.Seconds())
+		info.Duration = formatDuration(int(duration.Hours()/24), int(duration.Hours())%24,
+			int(duration.Minutes())%60, int(duration.Seconds())%60)
+	}
+	return info
+}
+
+// jobStatus derives a job's status from its terminal conditions.
+func jobStatus(job *batchv1.Job) string {
+	if jobCondition(job, batchv1.JobComplete) != nil {
+		return jobStatusComplete
+	}
+	if jobCondition(job, batchv1.JobFailed) != nil {
+		return jobStatusFailed
+	}
+	if job.Spec.Suspend != nil && *job.Spec.Suspend {
+		return jobStatusSuspended
+	}
+	return jobStatusRunning
+}
+
+// jobCondition returns the job's condition of the given type if it is true.
+func jobCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) *batchv1.JobCondition {
+	for i := range job.Status.Conditions {
+		if c := &job.Status.Conditions[i]; c.Type == conditionType && c.Status == corev1.ConditionTrue {
+			return c
+		}
+	}
+	return nil
+}
+
+// sortJobsNewestFirst orders jobs by start time, unstarted jobs first.
+func sortJobsNewestFirst(jobs []JobInfo) {
+	sort.SliceStable(jobs, func(i, j int) bool {
+		a, b := jobs[i].StartTime, jobs[j].StartTime
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		return a.After(*b)
+	})
+}
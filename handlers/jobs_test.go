@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// withJobClientset injects cs for job and cronjob endpoints.
+func withJobClientset(t *testing.T, cs kubernetes.Interface) {
+	t.Helper()
+	old := getJobClientset
+	getJobClientset = func() (kubernetes.Interface, error) { return cs, nil }
+	t.Cleanup(func() { getJobClientset = old })
+}
+
+// newTestJob returns a job started at start, owned by the named CronJob if cronJob is set.
+func newTestJob(name string, start time.Time, cronJob *batchv1.CronJob) *batchv1.Job {
+	completions := int32(3)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name + "-uid")},
+		Spec: batchv1.JobSpec{
+			Completions: &completions,
+			Selector:    &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template:    podTemplate("batch:1"),
+		},
+		Status: batchv1.JobStatus{StartTime: &metav1.Time{Time: start}, Succeeded: 1, Failed: 2, Active: 1},
+	}
+	if cronJob != nil {
+		job.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(cronJob, batchv1.SchemeGroupVersion.WithKind("CronJob"))}
+	}
+	return job
+}
+
+func TestBuildJobInfo(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	now := start.Add(90 * time.Minute)
+
+	running := newTestJob("running", start, &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "nightly", UID: "cj-uid"}})
+	info := buildJobInfo(running, now)
+	if info.Status != jobStatusRunning || info.Completions != 3 || info.Failed != 2 || info.CronJob != "nightly" {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	if info.DurationSeconds != 5400 || info.Duration != "1h30m" {
+		t.Errorf("running job should measure to now: %d %s", info.DurationSeconds, info.Duration)
+	}
+
+	complete := newTestJob("complete", start, nil)
+	complete.Status.CompletionTime = &metav1.Time{Time: start.Add(45 * time.Second)}
+	complete.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	if info := buildJobInfo(complete, now); info.Status != jobStatusComplete || info.DurationSeconds != 45 || info.CronJob != "" {
+		t.Errorf("complete job: %+v", info)
+	}
+
+	failed := newTestJob("failed", start, nil)
+	failed.Status.Conditions = []batchv1.JobCondition{{
+		Type: batchv1.JobFailed, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Time{Time: start.Add(10 * time.Minute)},
+	}}
+	if info := buildJobInfo(failed, now); info.Status != jobStatusFailed || info.DurationSeconds != 600 {
+		t.Errorf("failed job should measure to the failure: %+v", info)
+	}
+}
+
+func TestJobsHandler(t *testing.T) {
+	now := time.Now()
+	withJobClientset(t, fake.NewSimpleClientset(
+		newTestJob("older", now.Add(-2*time.Hour), nil),
+		newTestJob("newer", now.Add(-time.Hour), nil),
+	))
+
+	w := httptest.NewRecorder()
+	JobsHandler(w, httptest.NewRequest(http.MethodGet, "/api/jobs?ns=default", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var jobs []JobInfo
+	if err := json.NewDecoder(w.Body).Decode(&jobs); err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 || jobs[0].Name != "newer" {
+		t.Errorf("expected newest first, got %+v", jobs)
+	}
+}
+
+func TestJobDetailAndDelete(t *testing.T) {
+	job := newTestJob("report", time.Now(), nil)
+	cs := fake.NewSimpleClientset(job, newControlledPod("report-abc", "Job", job.UID))
+	withJobClientset(t, cs)
+
+	w := httptest.NewRecorder()
+	JobDetailHandler(w, httptest.NewRequest(http.MethodGet, "/api/jobs/default/report", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var detail JobDetail
+	if err := json.NewDecoder(w.Body).Decode(&detail); err != nil {
+		t.Fatal(err)
+	}
+	if len(detail.Pods) != 1 || detail.Images[0] != "batch:1" {
+		t.Errorf("unexpected detail: %+v", detail)
+	}
+
+	var propagation *metav1.DeletionPropagation
+	cs.PrependReactor("delete", "jobs", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		propagation = action.(clienttesting.DeleteAction).GetDeleteOptions().PropagationPolicy
+		return false, nil, nil
+	})
+	w = httptest.NewRecorder()
+	JobDetailHandler(w, httptest.NewRequest(http.MethodDelete, "/api/jobs/default/report", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if propagation == nil || *propagation != metav1.DeletePropagationBackground {
+		t.Errorf("delete must propagate to the job's pods, got %v", propagation)
+	}
+	if _, err := cs.BatchV1().Jobs("default").Get(context.Background(), "report", metav1.GetOptions{}); err == nil {
+		t.Error("job still exists after delete")
+	}
+
+	w = httptest.NewRecorder()
+	JobDetailHandler(w, httptest.NewRequest(http.MethodDelete, "/api/jobs/default/report", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second delete: expected 404, got %d", w.Code)
+	}
+}
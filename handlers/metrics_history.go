@@ -0,0 +1,572 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// Environment variables configuring the metrics history sampler.
+const (
+	metricsHistoryIntervalEnv     = "METRICS_HISTORY_INTERVAL"
+	metricsHistoryRetentionEnv    = "METRICS_HISTORY_RETENTION"
+	metricsHistoryPodRetentionEnv = "METRICS_HISTORY_POD_RETENTION"
+	metricsHistoryFileEnv         = "METRICS_HISTORY_FILE"
+)
+
+// Defaults for the sampler. Pod series are kept for less time than node series
+// because there are far more of them.
+const (
+	defaultHistoryInterval     = 30 * time.Second
+	defaultHistoryRetention    = 24 * time.Hour
+	defaultHistoryPodRetention = time.Hour
+	defaultHistoryRange        = time.Hour
+
+	// historyPersistEvery is how many samples are taken between writes of the history file.
+	historyPersistEvery = 10
+)
+
+// metricsSample is one point-in-time reading. CPU is in millicores, memory in bytes.
+// Capacities are zero for pod samples.
+type metricsSample struct {
+	Timestamp        time.Time `json:"t"`
+	CPUMilli         int64     `json:"c"`
+	CPUCapacityMilli int64     `json:"cc,omitempty"`
+	MemoryBytes      int64     `json:"m"`
+	MemoryCapacity   int64     `json:"mc,omitempty"`
+}
+
+// sampleRing is a fixed-capacity ring buffer of samples in chronological order.
+type sampleRing struct {
+	samples []metricsSample
+	start   int
+	size    int
+}
+
+func newSampleRing(capacity int) *sampleRing {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &sampleRing{samples: make([]metricsSample, capacity)}
+}
+
+// add appends a sample, overwriting the oldest one when full.
+func (r *sampleRing) add(s metricsSample) {
+	idx := (r.start + r.size) % len(r.samples)
+	r.samples[idx] = s
+	if r.size < len(r.samples) {
+		r.size++
+	} else {
+		r.start = (r.start + 1) % len(r.samples)
+	}
+}
+
+// since returns the samples taken at or after t, oldest first.
+func (r *sampleRing) since(t time.Time) []metricsSample {
+	out := make([]metricsSample, 0, r.size)
+	for i := 0; i < r.size; i++ {
+		s := r.samples[(r.start+i)%len(r.samples)]
+		if !s.Timestamp.Before(t) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// latest returns the newest sample, or the zero sample when empty.
+func (r *sampleRing) latest() metricsSample {
+	if r.size == 0 {
+		return metricsSample{}
+	}
+	return r.samples[(r.start+r.size-1)%len(r.samples)]
+}
+
+// metricsHistory holds the ring buffers for the cluster, each node and each pod.
+type metricsHistory struct {
+	mu           sync.RWMutex
+	interval     time.Duration
+	retention    time.Duration
+	podRetention time.Duration
+	cluster      *sampleRing
+	nodes        map[string]*sampleRing
+	pods         map[string]*sampleRing
+}
+
+func newMetricsHistory(interval, retention, podRetention time.Duration) *metricsHistory {
+	h := &metricsHistory{
+		interval:     interval,
+		retention:    retention,
+		podRetention: podRetention,
+		nodes:        make(map[string]*sampleRing),
+		pods:         make(map[string]*sampleRing),
+	}
+	h.cluster = newSampleRing(h.capacity(retention))
+	return h
+}
+
+func (h *metricsHistory) capacity(retention time.Duration) int {
+	return int(retention/h.interval) + 1
+}
+
+// record stores one round of samples and drops series that have not been
+// sampled within their retention window (deleted nodes and pods).
+func (h *metricsHistory) record(now time.Time, cluster metricsSample, nodes, pods map[string]metricsSample) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.cluster.add(cluster)
+	addSeries(h.nodes, nodes, h.capacity(h.retention))
+	addSeries(h.pods, pods, h.capacity(h.podRetention))
+	pruneSeries(h.nodes, now.Add(-h.retention))
+	pruneSeries(h.pods, now.Add(-h.podRetention))
+}
+
+func addSeries(series map[string]*sampleRing, samples map[string]metricsSample, capacity int) {
+	for key, s := range samples {
+		ring, ok := series[key]
+		if !ok {
+			ring = newSampleRing(capacity)
+			series[key] = ring
+		}
+		ring.add(s)
+	}
+}
+
+func pruneSeries(series map[string]*sampleRing, cutoff time.Time) {
+	for key, ring := range series {
+		if ring.latest().Timestamp.Before(cutoff) {
+			delete(series, key)
+		}
+	}
+}
+
+// HistoryPoint is one bucket of a usage time series.
+// Percentages are omitted for pods, which have no capacity.
+type HistoryPoint struct {
+	Timestamp     time.Time `json:"timestamp"`
+	CPUMillis     int64     `json:"cpuMillis"`
+	MemoryBytes   int64     `json:"memoryBytes"`
+	CPUPercent    *float64  `json:"cpuPercent,omitempty"`
+	MemoryPercent *float64  `json:"memoryPercent,omitempty"`
+}
+
+// HistoryResponse is a usage time series for a node, a pod or the whole cluster.
+type HistoryResponse struct {
+	Name       string         `json:"name"`
+	Range      string         `json:"range"`
+	Resolution string         `json:"resolution"`
+	Points     []HistoryPoint `json:"points"`
+}
+
+// query returns samples from series within [now-rng, now], averaged into
+// buckets of the given resolution.
+func (h *metricsHistory) query(ring *sampleRing, now time.Time, rng, resolution time.Duration) []HistoryPoint {
+	h.mu.RLock()
+	samples := ring.since(now.Add(-rng))
+	h.mu.RUnlock()
+	return downsample(samples, resolution)
+}
+
+// downsample averages samples into buckets aligned to multiples of resolution.
+func downsample(samples []metricsSample, resolution time.Duration) []HistoryPoint {
+	points := make([]HistoryPoint, 0)
+	var bucket time.Time
+	var acc metricsSample
+	n := int64(0)
+
+	flush := func() {
+		if n == 0 {
+			return
+		}
+		p := HistoryPoint{
+			Timestamp:   bucket,
+			CPUMillis:   acc.CPUMilli / n,
+			MemoryBytes: acc.MemoryBytes / n,
+		}
+		if acc.CPUCapacityMilli > 0 {
+			v := clamp(float64(acc.CPUMilli)/float64(acc.CPUCapacityMilli)*100, 0, 100)
+			p.CPUPercent = &v
+		}
+		if acc.MemoryCapacity > 0 {
+			v := clamp(float64(acc.MemoryBytes)/float64(acc.MemoryCapacity)*100, 0, 100)
+			p.MemoryPercent = &v
+		}
+		points = append(points, p)
+	}
+
+	for _, s := range samples {
+		b := s.Timestamp.Truncate(resolution)
+		if n > 0 && !b.Equal(bucket) {
+			flush()
+			acc, n = metricsSample{}, 0
+		}
+		bucket = b
+		acc.CPUMilli += s.CPUMilli
+		acc.CPUCapacityMilli += s.CPUCapacityMilli
+		acc.MemoryBytes += s.MemoryBytes
+		acc.MemoryCapacity += s.MemoryCapacity
+		n++
+	}
+	flush()
+	return points
+}
+
+// historySnapshot is the on-disk format of METRICS_HISTORY_FILE.
+type historySnapshot struct {
+	Cluster []metricsSample            `json:"cluster"`
+	Nodes   map[string][]metricsSample `json:"nodes"`
+	Pods    map[string][]metricsSample `json:"pods"`
+}
+
+func (h *metricsHistory) snapshot() historySnapshot {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	all := time.Time{}
+	snap := historySnapshot{
+		Cluster: h.cluster.since(all),
+		Nodes:   make(map[string][]metricsSample, len(h.nodes)),
+		Pods:    make(map[string][]metricsSample, len(h.pods)),
+	}
+	for k, r := range h.nodes {
+		snap.Nodes[k] = r.since(all)
+	}
+	for k, r := range h.pods {
+		snap.Pods[k] = r.since(all)
+	}
+	return snap
+}
+
+// restore loads samples newer than the retention windows from a snapshot.
+func (h *metricsHistory) restore(snap historySnapshot, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, s := range snap.Cluster {
+		if s.Timestamp.After(now.Add(-h.retention)) {
+			h.cluster.add(s)
+		}
+	}
+	restoreSeries(h.nodes, snap.Nodes, now.Add(-h.retention), h.capacity(h.retention))
+	restoreSeries(h.pods, snap.Pods, now.Add(-h.podRetention), h.capacity(h.podRetention))
+}
+
+func restoreSeries(series map[string]*sampleRing, saved map[string][]metricsSample, cutoff time.Time, capacity int) {
+	for key, samples := range saved {
+		for _, s := range samples {
+			if s.Timestamp.Before(cutoff) {
+				continue
+			}
+			ring, ok := series[key]
+			if !ok {
+				ring = newSampleRing(capacity)
+				series[key] = ring
+			}
+			ring.add(s)
+		}
+	}
+}
+
+// saveHistory writes the snapshot atomically so a crash never leaves a truncated file.
+func saveHistory(path string, h *metricsHistory) error {
+	data, err := json.Marshal(h.snapshot())
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".metrics-history-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func loadHistory(path string, h *metricsHistory, now time.Time) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var snap historySnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	h.restore(snap, now)
+	return nil
+}
+
+var (
+	// historyStore is nil until StartMetricsHistory runs, or when history is disabled.
+	historyStore   *metricsHistory
+	historyStoreMu sync.RWMutex
+)
+
+func getHistoryStore() *metricsHistory {
+	historyStoreMu.RLock()
+	defer historyStoreMu.RUnlock()
+	return historyStore
+}
+
+func setHistoryStore(h *metricsHistory) {
+	historyStoreMu.Lock()
+	historyStore = h
+	historyStoreMu.Unlock()
+}
+
+// getHistoryClientset is a package-level variable for obtaining the Kubernetes client
+// used by the sampler. Tests may override this.
+var getHistoryClientset func() (kubernetes.Interface, error) = func() (kubernetes.Interface, error) {
+	return getKubernetesClient()
+}
+
+// StartMetricsHistory starts the background sampler that records node and pod
+// usage from metrics-server. Setting METRICS_HISTORY_INTERVAL to 0 disables it.
+// The sampler stops when ctx is done; the returned channel is closed once it has
+// stopped and saved the history file.
+func StartMetricsHistory(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	interval := envDuration(metricsHistoryIntervalEnv, defaultHistoryInterval)
+	if interval <= 0 {
+		slog.Info("Metrics history disabled")
+		close(done)
+		return done
+	}
+	h := newMetricsHistory(interval,
+		envDuration(metricsHistoryRetentionEnv, defaultHistoryRetention),
+		envDuration(metricsHistoryPodRetentionEnv, defaultHistoryPodRetention))
+
+	path := os.Getenv(metricsHistoryFileEnv)
+	if path != "" {
+		if err := loadHistory(path, h, nowFunc()); err != nil && !os.IsNotExist(err) {
+			slog.Warn("Failed to load metrics history", "error", err, "path", path)
+		}
+	}
+	setHistoryStore(h)
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for taken := 1; ; taken++ {
+			sampleCtx, cancel := context.WithTimeout(ctx, apiTimeout)
+			sampleMetrics(sampleCtx, h)
+			cancel()
+
+			if path != "" && taken%historyPersistEvery == 0 {
+				if err := saveHistory(path, h); err != nil {
+					slog.Warn("Failed to save metrics history", "error", err, "path", path)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				if path != "" {
+					saveHistory(path, h) //nolint:errcheck
+				}
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return done
+}
+
+// sampleMetrics takes one round of node and pod samples. Only real metrics-server
+// readings are recorded; when it is unavailable the round is skipped.
+func sampleMetrics(ctx context.Context, h *metricsHistory) {
+	mc := getPodMetricsClient()
+	if mc == nil {
+		return
+	}
+	clientset, err := getHistoryClientset()
+	if err != nil {
+		slog.Warn("Metrics history: client unavailable", "error", err)
+		return
+	}
+	takeSample(ctx, h, clientset, mc, nowFunc())
+}
+
+func takeSample(ctx context.Context, h *metricsHistory, clientset kubernetes.Interface, mc metricsv.Interface, now time.Time) {
+	nodeMetrics := fetchNodeMetricsFrom(ctx, mc)
+	if nodeMetrics == nil {
+		return
+	}
+	nodeList, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Warn("Metrics history: failed to list nodes", "error", err)
+		return
+	}
+
+	cluster := metricsSample{Timestamp: now}
+	nodes := make(map[string]metricsSample, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		usage, ok := nodeMetrics[node.Name]
+		if !ok {
+			continue
+		}
+		s := metricsSample{
+			Timestamp:        now,
+			CPUMilli:         usage.cpuMillis,
+			CPUCapacityMilli: node.Status.Capacity.Cpu().MilliValue(),
+			MemoryBytes:      usage.memoryBytes,
+			MemoryCapacity:   node.Status.Capacity.Memory().Value(),
+		}
+		nodes[node.Name] = s
+		cluster.CPUMilli += s.CPUMilli
+		cluster.CPUCapacityMilli += s.CPUCapacityMilli
+		cluster.MemoryBytes += s.MemoryBytes
+		cluster.MemoryCapacity += s.MemoryCapacity
+	}
+
+	pods := make(map[string]metricsSample)
+	for key, containers := range fetchPodMetrics(ctx, mc, "") {
+		s := metricsSample{Timestamp: now}
+		for _, u := range containers {
+			s.CPUMilli += u.cpuMillis
+			s.MemoryBytes += u.memoryBytes
+		}
+		pods[key] = s
+	}
+
+	h.record(now, cluster, nodes, pods)
+}
+
+// parseHistoryParams reads range and resolution (Go durations such as 6h or 5m).
+// Resolution defaults to range/120 but never below the sampling interval.
+func parseHistoryParams(r *http.Request, h *metricsHistory, maxRange time.Duration) (time.Duration, time.Duration, error) {
+	rng := defaultHistoryRange
+	if v := r.URL.Query().Get("range"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return 0, 0, fmt.Errorf("range must be a positive duration such as 1h")
+		}
+		rng = d
+	}
+	if rng > maxRange {
+		rng = maxRange
+	}
+
+	resolution := rng / 120
+	if v := r.URL.Query().Get("resolution"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return 0, 0, fmt.Errorf("resolution must be a positive duration such as 1m")
+		}
+		resolution = d
+	}
+	if resolution < h.interval {
+		resolution = h.interval
+	}
+	return rng, resolution, nil
+}
+
+// serveHistory writes the series for ring, or the appropriate error.
+func serveHistory(w http.ResponseWriter, r *http.Request, h *metricsHistory, name string, ring *sampleRing, maxRange time.Duration) {
+	rng, resolution, err := parseHistoryParams(r, h, maxRange)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	points := []HistoryPoint{}
+	if ring != nil {
+		points = h.query(ring, nowFunc(), rng, resolution)
+	}
+	writeJSON(w, http.StatusOK, HistoryResponse{
+		Name:       name,
+		Range:      rng.String(),
+		Resolution: resolution.String(),
+		Points:     points,
+	})
+}
+
+// lookupSeries returns the ring for key under the read lock, or nil.
+func (h *metricsHistory) lookupSeries(series map[string]*sampleRing, key string) *sampleRing {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return series[key]
+}
+
+// historyAvailable writes 503 and returns nil when the sampler is not running.
+func historyAvailable(w http.ResponseWriter, r *http.Request) *metricsHistory {
+	if !requireMethod(w, r, http.MethodGet) {
+		return nil
+	}
+	h := getHistoryStore()
+	if h == nil {
+		writeError(w, http.StatusServiceUnavailable, errMsgHistoryDisabled)
+	}
+	return h
+}
+
+// OverviewHistoryHandler handles GET /api/overview/history?range=...&resolution=...
+// It returns cluster-wide CPU and memory usage over time.
+func OverviewHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	h := historyAvailable(w, r)
+	if h == nil {
+		return
+	}
+	serveHistory(w, r, h, "cluster", h.cluster, h.retention)
+}
+
+// handleNodeHistory handles GET /api/nodes/{name}/history?range=...&resolution=...
+func handleNodeHistory(w http.ResponseWriter, r *http.Request, nodeName string) {
+	h := historyAvailable(w, r)
+	if h == nil {
+		return
+	}
+	ring := h.lookupSeries(h.nodes, nodeName)
+	if ring == nil {
+		writeError(w, http.StatusNotFound, errMsgHistoryNotFound)
+		return
+	}
+	serveHistory(w, r, h, nodeName, ring, h.retention)
+}
+
+// PodHistoryHandler handles GET /api/pods/history/{namespace}/{name}?range=...&resolution=...
+func PodHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	h := historyAvailable(w, r)
+	if h == nil {
+		return
+	}
+	namespace, name, err := parseResourcePath(r.URL.Path, podHistoryPathPrefix, "")
+	if err != nil {
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("Invalid path format. Expected %s{namespace}/{name}", podHistoryPathPrefix))
+		return
+	}
+	ring := h.lookupSeries(h.pods, namespace+"/"+name)
+	if ring == nil {
+		writeError(w, http.StatusNotFound, errMsgHistoryNotFound)
+		return
+	}
+	serveHistory(w, r, h, namespace+"/"+name, ring, h.podRetention)
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		slog.Warn("Invalid duration, using default", "env", key, "value", v, "default", fallback)
+		return fallback
+	}
+	return d
+}
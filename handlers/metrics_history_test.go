@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	metricsapi "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// withHistoryStore installs h as the active history store for the test.
+func withHistoryStore(t *testing.T, h *metricsHistory) {
+	t.Helper()
+	old := getHistoryStore()
+	setHistoryStore(h)
+	t.Cleanup(func() { setHistoryStore(old) })
+}
+
+var historyEpoch = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestSampleRing(t *testing.T) {
+	r := newSampleRing(3)
+	for i := 0; i < 5; i++ {
+		r.add(metricsSample{Timestamp: historyEpoch.Add(time.Duration(i) * time.Minute), CPUMilli: int64(i)})
+	}
+	all := r.since(time.Time{})
+	if len(all) != 3 || all[0].CPUMilli != 2 || all[2].CPUMilli != 4 {
+		t.Errorf("ring should keep the newest 3 in order, got %+v", all)
+	}
+	if got := r.since(historyEpoch.Add(4 * time.Minute)); len(got) != 1 {
+		t.Errorf("since: got %d samples", len(got))
+	}
+	if r.latest().CPUMilli != 4 {
+		t.Errorf("latest = %+v", r.latest())
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	samples := []metricsSample{
+		{Timestamp: historyEpoch, CPUMilli: 100, CPUCapacityMilli: 1000, MemoryBytes: 10},
+		{Timestamp: historyEpoch.Add(30 * time.Second), CPUMilli: 300, CPUCapacityMilli: 1000, MemoryBytes: 30},
+		{Timestamp: historyEpoch.Add(time.Minute), CPUMilli: 500, CPUCapacityMilli: 1000, MemoryBytes: 50},
+	}
+	points := downsample(samples, time.Minute)
+	if len(points) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(points))
+	}
+	if points[0].CPUMillis != 200 || points[0].MemoryBytes != 20 || *points[0].CPUPercent != 20 {
+		t.Errorf("first bucket = %+v", points[0])
+	}
+	if points[0].MemoryPercent != nil {
+		t.Error("memory percent should be omitted without capacity")
+	}
+	if !points[1].Timestamp.Equal(historyEpoch.Add(time.Minute)) {
+		t.Errorf("second bucket at %v", points[1].Timestamp)
+	}
+}
+
+func TestMetricsHistory_RecordPrunesStaleSeries(t *testing.T) {
+	h := newMetricsHistory(time.Minute, time.Hour, 10*time.Minute)
+	h.record(historyEpoch, metricsSample{Timestamp: historyEpoch},
+		map[string]metricsSample{"node-1": {Timestamp: historyEpoch}},
+		map[string]metricsSample{"default/web": {Timestamp: historyEpoch}})
+
+	later := historyEpoch.Add(20 * time.Minute)
+	h.record(later, metricsSample{Timestamp: later},
+		map[string]metricsSample{"node-1": {Timestamp: later}}, nil)
+
+	if h.nodes["node-1"] == nil {
+		t.Error("node series within retention was dropped")
+	}
+	if h.pods["default/web"] != nil {
+		t.Error("pod series past pod retention should be dropped")
+	}
+}
+
+func TestTakeSample(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{Capacity: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2"),
+			corev1.ResourceMemory: resource.MustParse("4Gi"),
+		}},
+	}
+	mc := metricsfake.NewSimpleClientset()
+	mc.PrependReactor("list", "nodes", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsapi.NodeMetricsList{Items: []metricsapi.NodeMetrics{{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Usage: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		}}}, nil
+	})
+	mc.PrependReactor("list", "pods", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsapi.PodMetricsList{Items: []metricsapi.PodMetrics{
+			newPodSample("default", "web", map[string][2]string{"app": {"100m", "64Mi"}, "sidecar": {"20m", "16Mi"}}),
+		}}, nil
+	})
+
+	h := newMetricsHistory(time.Minute, time.Hour, time.Hour)
+	takeSample(context.Background(), h, fake.NewSimpleClientset(node), mc, historyEpoch)
+
+	c := h.cluster.latest()
+	if c.CPUMilli != 500 || c.CPUCapacityMilli != 2000 || c.MemoryBytes != 1<<30 {
+		t.Errorf("cluster sample = %+v", c)
+	}
+	if p := h.pods["default/web"].latest(); p.CPUMilli != 120 || p.MemoryBytes != 80<<20 {
+		t.Errorf("pod sample = %+v", p)
+	}
+}
+
+func TestHistoryHandlers(t *testing.T) {
+	withFixedNow(t, historyEpoch.Add(10*time.Minute))
+	h := newMetricsHistory(time.Minute, time.Hour, time.Hour)
+	for i := 0; i <= 10; i++ {
+		ts := historyEpoch.Add(time.Duration(i) * time.Minute)
+		h.record(ts,
+			metricsSample{Timestamp: ts, CPUMilli: 100, CPUCapacityMilli: 1000},
+			map[string]metricsSample{"node-1": {Timestamp: ts, CPUMilli: 50, CPUCapacityMilli: 500}},
+			nil)
+	}
+	withHistoryStore(t, h)
+
+	w := httptest.NewRecorder()
+	OverviewHistoryHandler(w, httptest.NewRequest(http.MethodGet, "/api/overview/history?range=5m&resolution=1m", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp HistoryResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Points) != 6 || resp.Resolution != "1m0s" || *resp.Points[0].CPUPercent != 10 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	w = httptest.NewRecorder()
+	NodeActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/nodes/node-1/history?range=1h&resolution=5m", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("node history: expected 200, got %d", w.Code)
+	}
+	json.NewDecoder(w.Body).Decode(&resp) //nolint:errcheck
+	if resp.Name != "node-1" || len(resp.Points) != 3 {
+		t.Errorf("node history = %+v", resp)
+	}
+
+	for path, want := range map[string]int{
+		"/api/nodes/node-2/history":           http.StatusNotFound,
+		"/api/nodes/node-1/history?range=abc": http.StatusBadRequest,
+	} {
+		w = httptest.NewRecorder()
+		NodeActionsHandler(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	PodHistoryHandler(w, httptest.NewRequest(http.MethodGet, "/api/pods/history/default/web", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("pod history: expected 404, got %d", w.Code)
+	}
+}
+
+func TestHistoryHandlers_Disabled(t *testing.T) {
+	withHistoryStore(t, nil)
+	w := httptest.NewRecorder()
+	OverviewHistoryHandler(w, httptest.NewRequest(http.MethodGet, "/api/overview/history", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", w.Code)
+	}
+}
+
+func TestHistoryPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	h := newMetricsHistory(time.Minute, time.Hour, time.Hour)
+	h.record(historyEpoch, metricsSample{Timestamp: historyEpoch, CPUMilli: 42},
+		map[string]metricsSample{"node-1": {Timestamp: historyEpoch, CPUMilli: 7}}, nil)
+	if err := saveHistory(path, h); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newMetricsHistory(time.Minute, time.Hour, time.Hour)
+	if err := loadHistory(path, restored, historyEpoch.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if restored.cluster.latest().CPUMilli != 42 || restored.nodes["node-1"].latest().CPUMilli != 7 {
+		t.Error("samples not restored")
+	}
+
+	expired := newMetricsHistory(time.Minute, time.Hour, time.Hour)
+	if err := loadHistory(path, expired, historyEpoch.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if expired.cluster.size != 0 || len(expired.nodes) != 0 {
+		t.Error("samples older than retention should not be restored")
+	}
+}
+
+func TestStartMetricsHistory_StopsAndSavesOnCancel(t *testing.T) {
+	oldMC := getPodMetricsClient
+	getPodMetricsClient = func() metricsv.Interface { return nil }
+	t.Cleanup(func() { getPodMetricsClient = oldMC })
+	withHistoryStore(t, nil)
+	path := filepath.Join(t.TempDir(), "history.json")
+	t.Setenv(metricsHistoryIntervalEnv, "1h")
+	t.Setenv(metricsHistoryFileEnv, path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := StartMetricsHistory(ctx)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sampler did not stop after cancel")
+	}
+	if err := loadHistory(path, newMetricsHistory(time.Hour, time.Hour, time.Hour), time.Now()); err != nil {
+		t.Errorf("history was not saved on shutdown: %v", err)
+	}
+
+	t.Setenv(metricsHistoryIntervalEnv, "0")
+	select {
+	case <-StartMetricsHistory(context.Background()):
+	default:
+		t.Error("disabled history should report done immediately")
+	}
+}
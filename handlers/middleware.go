@@ -120,6 +120,35 @@ func withParsedResource(w http.ResponseWriter, r *http.Request, pathPrefix, path
 	return &resourceContext{namespace: namespace, name: name, clientset: clientset}
 }
 
+// parseNamespacedAction parses {prefix}{ns}/{name}{suffix}, rejecting names that
+// contain a slash, and obtains a client from getClientset. It writes an error
+// response and returns a nil client on failure.
+func parseNamespacedAction(w http.ResponseWriter, r *http.Request, prefix, suffix string, getClientset func() (kubernetes.Interface, error)) (string, string, kubernetes.Interface) {
+	namespace, name, err := parseResourcePath(r.URL.Path, prefix, suffix)
+	if err != nil || strings.Contains(name, "/") {
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("Invalid path format. Expected %s{namespace}/{name}%s", prefix, suffix))
+		return "", "", nil
+	}
+
+	clientset, err := getClientset()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return "", "", nil
+	}
+	return namespace, name, clientset
+}
+
+// statusError is an error carrying the HTTP status code and message that
+// should be reported to the client.
+type statusError struct {
+	status  int
+	message string
+}
+
+func (e *statusError) Error() string { return e.message }
+
 // writeResourceError writes an appropriate error response for Kubernetes API errors,
 // handling NotFound as 404 and everything else as 500.
 func writeResourceError(w http.ResponseWriter, err error, notFoundMsg, internalMsg string) {
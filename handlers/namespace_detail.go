@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// getNamespaceClientset is a package-level variable for obtaining the Kubernetes client
+// used by the namespace detail endpoint. Tests may override this.
+var getNamespaceClientset func() (kubernetes.Interface, error) = func() (kubernetes.Interface, error) {
+	return getKubernetesClient()
+}
+
+// NamespacePodCounts counts a namespace's pods by phase.
+type NamespacePodCounts struct {
+	Total     int            `json:"total"`
+	Unhealthy int            `json:"unhealthy"`
+	ByPhase   map[string]int `json:"byPhase"`
+}
+
+// NamespaceResources sums the requests, limits and usage of a namespace's
+// non-terminated pods. Containers without a limit add nothing to Limit;
+// the Unlimited counts say how many pods have at least one such container.
+type NamespaceResources struct {
+	MetricsAvailable    bool            `json:"metricsAvailable"`
+	CPU                 ResourceAmounts `json:"cpu"`
+	Memory              ResourceAmounts `json:"memory"`
+	CPUUnlimitedPods    int             `json:"cpuUnlimitedPods"`
+	MemoryUnlimitedPods int             `json:"memoryUnlimitedPods"`
+}
+
+// QuotaResource compares one resource's quota usage with its hard limit.
+type QuotaResource struct {
+	Resource    string   `json:"resource"`
+	Hard        string   `json:"hard"`
+	Used        string   `json:"used"`
+	UsedPercent *float64 `json:"usedPercent,omitempty"`
+}
+
+// ResourceQuotaInfo is a ResourceQuota in the namespace.
+type ResourceQuotaInfo struct {
+	Name      string          `json:"name"`
+	Scopes    []string        `json:"scopes,omitempty"`
+	Resources []QuotaResource `json:"resources"`
+}
+
+// LimitRangeEntry is the constraint a LimitRange places on one resource of one object type.
+type LimitRangeEntry struct {
+	Type                 string `json:"type"`
+	Resource             string `json:"resource"`
+	Min                  string `json:"min,omitempty"`
+	Max                  string `json:"max,omitempty"`
+	Default              string `json:"default,omitempty"`
+	DefaultRequest       string `json:"defaultRequest,omitempty"`
+	MaxLimitRequestRatio string `json:"maxLimitRequestRatio,omitempty"`
+}
+
+// LimitRangeInfo is a LimitRange in the namespace.
+type LimitRangeInfo struct {
+	Name   string            `json:"name"`
+	Limits []LimitRangeEntry `json:"limits"`
+}
+
+// NamespaceDetailResponse is returned by GET /api/namespaces/{name}.
+type NamespaceDetailResponse struct {
+	Name        string              `json:"name"`
+	Status      string              `json:"status"`
+	Age         string              `json:"age"`
+	Labels      map[string]string   `json:"labels"`
+	Annotations map[string]string   `json:"annotations"`
+	Pods        NamespacePodCounts  `json:"pods"`
+	Workloads   map[string]int      `json:"workloads"`
+	Resources   NamespaceResources  `json:"resources"`
+	Quotas      []ResourceQuotaInfo `json:"quotas"`
+	LimitRanges []LimitRangeInfo    `json:"limitRanges"`
+}
+
+// NamespaceDetailHandler handles GET /api/namespaces/{name}.
+func NamespaceDetailHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	r = withTimeout(r)
+
+	name := strings.TrimPrefix(r.URL.Path, namespacesPathPrefix)
+	if name == "" || strings.Contains(name, "/") {
+		writeError(w, http.StatusBadRequest, "Invalid path format. Expected "+namespacesPathPrefix+"{name}")
+		return
+	}
+
+	clientset, err := getNamespaceClientset()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	detail, err := getNamespaceDetail(r.Context(), clientset, name)
+	if err != nil {
+		writeResourceError(w, err, errMsgNamespaceNotFound, errMsgNamespaceFetch)
+		return
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// getNamespaceDetail assembles the namespace summary from its pods, workloads,
+// quotas and limit ranges.
+func getNamespaceDetail(ctx context.Context, clientset kubernetes.Interface, name string) (*NamespaceDetailResponse, error) {
+	ns, err := clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	podList, err := clientset.CoreV1().Pods(name).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	workloads, err := countWorkloads(ctx, clientset, name)
+	if err != nil {
+		return nil, err
+	}
+
+	quotaList, err := clientset.CoreV1().ResourceQuotas(name).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	limitRangeList, err := clientset.CoreV1().LimitRanges(name).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &NamespaceDetailResponse{
+		Name:        ns.Name,
+		Status:      string(ns.Status.Phase),
+		Age:         formatPodAge(ns.CreationTimestamp.Time),
+		Labels:      ns.Labels,
+		Annotations: ns.Annotations,
+		Workloads:   workloads,
+		Quotas:      make([]ResourceQuotaInfo, 0, len(quotaList.Items)),
+		LimitRanges: make([]LimitRangeInfo, 0, len(limitRangeList.Items)),
+	}
+	if detail.Labels == nil {
+		detail.Labels = map[string]string{}
+	}
+	if detail.Annotations == nil {
+		detail.Annotations = map[string]string{}
+	}
+
+	metricsMap := fetchPodMetrics(ctx, getPodMetricsClient(), name)
+	detail.Pods, detail.Resources = summarizeNamespacePods(podList.Items, metricsMap)
+
+	for _, q := range quotaList.Items {
+		detail.Quotas = append(detail.Quotas, buildResourceQuotaInfo(q))
+	}
+	for _, lr := range limitRangeList.Items {
+		detail.LimitRanges = append(detail.LimitRanges, buildLimitRangeInfo(lr))
+	}
+
+	return detail, nil
+}
+
+// summarizeNamespacePods counts pods by phase and sums the resources of the
+// non-terminated ones. metricsMap may be nil when metrics-server is unavailable.
+func summarizeNamespacePods(pods []corev1.Pod, metricsMap map[string]podMetricsUsage) (NamespacePodCounts, NamespaceResources) {
+	counts := NamespacePodCounts{Total: len(pods), ByPhase: map[string]int{}}
+	resources := NamespaceResources{MetricsAvailable: metricsMap != nil}
+
+	for _, pod := range pods {
+		phase := string(pod.Status.Phase)
+		if phase == "" {
+			phase = string(corev1.PodUnknown)
+		}
+		counts.ByPhase[phase]++
+		if !isPodHealthy(pod) {
+			counts.Unhealthy++
+		}
+
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		res := buildPodResources(pod, metricsMap[pod.Namespace+"/"+pod.Name])
+		resources.CPU.Usage += res.CPU.Usage
+		resources.CPU.Request += res.CPU.Request
+		resources.Memory.Usage += res.Memory.Usage
+		resources.Memory.Request += res.Memory.Request
+		for _, c := range res.Containers {
+			resources.CPU.Limit += c.CPU.Limit
+			resources.Memory.Limit += c.Memory.Limit
+		}
+		if res.CPU.Limit == 0 {
+			resources.CPUUnlimitedPods++
+		}
+		if res.Memory.Limit == 0 {
+			resources.MemoryUnlimitedPods++
+		}
+	}
+	return counts, resources
+}
+
+// countWorkloads counts the namespace's workload controllers by kind.
+func countWorkloads(ctx context.Context, clientset kubernetes.Interface, namespace string) (map[string]int, error) {
+	opts := metav1.ListOptions{}
+	counts := make(map[string]int, 6)
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	counts["Deployment"] = len(deployments.Items)
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	counts["StatefulSet"] = len(statefulSets.Items)
+
+	daemonSets, err := clientset.AppsV1().DaemonSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	counts["DaemonSet"] = len(daemonSets.Items)
+
+	replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	counts["ReplicaSet"] = len(replicaSets.Items)
+
+	jobs, err := clientset.BatchV1().Jobs(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	counts["Job"] = len(jobs.Items)
+
+	cronJobs, err := clientset.BatchV1().CronJobs(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	counts["CronJob"] = len(cronJobs.Items)
+
+	return counts, nil
+}
+
+// buildResourceQuotaInfo pairs each hard limit of the quota with its current usage.
+func buildResourceQuotaInfo(q corev1.ResourceQuota) ResourceQuotaInfo {
+	hard := q.Status.Hard
+	if len(hard) == 0 {
+		hard = q.Spec.Hard
+	}
+
+	info := ResourceQuotaInfo{Name: q.Name, Resources: make([]QuotaResource, 0, len(hard))}
+	for _, scope := range q.Spec.Scopes {
+		info.Scopes = append(info.Scopes, string(scope))
+	}
+	for name, h := range hard {
+		used := q.Status.Used[name]
+		qr := QuotaResource{Resource: string(name), Hard: h.String(), Used: used.String()}
+		if hardValue := h.AsApproximateFloat64(); hardValue > 0 {
+			percent := used.AsApproximateFloat64() / hardValue * 100
+			qr.UsedPercent = &percent
+		}
+		info.Resources = append(info.Resources, qr)
+	}
+	sort.Slice(info.Resources, func(i, j int) bool { return info.Resources[i].Resource < info.Resources[j].Resource })
+	return info
+}
+
+// buildLimitRangeInfo flattens a LimitRange into one entry per type and resource.
+func buildLimitRangeInfo(lr corev1.LimitRange) LimitRangeInfo {
+	info := LimitRangeInfo{Name: lr.Name, Limits: []LimitRangeEntry{}}
+	for _, item := range lr.Spec.Limits {
+		names := map[corev1.ResourceName]bool{}
+		for _, list := range []corev1.ResourceList{item.Min, item.Max, item.Default, item.DefaultRequest, item.MaxLimitRequestRatio} {
+			for name := range list {
+				names[name] = true
+			}
+		}
+		sorted := make([]string, 0, len(names))
+		for name := range names {
+			sorted = append(sorted, string(name))
+		}
+		sort.Strings(sorted)
+
+		for _, name := range sorted {
+			rn := corev1.ResourceName(name)
+			info.Limits = append(info.Limits, LimitRangeEntry{
+				Type:                 string(item.Type),
+				Resource:             name,
+				Min:                  quantityString(item.Min, rn),
+				Max:                  quantityString(item.Max, rn),
+				Default:              quantityString(item.Default, rn),
+				DefaultRequest:       quantityString(item.DefaultRequest, rn),
+				MaxLimitRequestRatio: quantityString(item.MaxLimitRequestRatio, rn),
+			})
+		}
+	}
+	return info
+}
+
+// quantityString returns the quantity for name in list, or "" when it is not set.
+func quantityString(list corev1.ResourceList, name corev1.ResourceName) string {
+	q, ok := list[name]
+	if !ok {
+		return ""
+	}
+	return q.String()
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func withNamespaceClientset(t *testing.T, cs kubernetes.Interface) {
+	t.Helper()
+	old := getNamespaceClientset
+	getNamespaceClientset = func() (kubernetes.Interface, error) { return cs, nil }
+	t.Cleanup(func() { getNamespaceClientset = old })
+}
+
+func TestNamespaceDetailHandler(t *testing.T) {
+	web := newPodWithResources("team-a", "web", resourceContainer("app", "500m", "1", "256Mi", "512Mi"))
+	worker := newPodWithResources("team-a", "worker", resourceContainer("app", "250m", "", "128Mi", "256Mi"))
+	done := newPodWithResources("team-a", "migrate", resourceContainer("app", "2", "2", "1Gi", "1Gi"))
+	done.Status.Phase = corev1.PodSucceeded
+	pending := newPodWithResources("team-a", "queued", resourceContainer("app", "100m", "100m", "", ""))
+	pending.Status.Phase = corev1.PodPending
+
+	cs := fake.NewSimpleClientset(
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}, Annotations: map[string]string{"owner": "alice"}},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		},
+		web, worker, done, pending,
+		newPodWithResources("team-b", "other", resourceContainer("app", "4", "", "", "")),
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "web"}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "db"}},
+		&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "nightly"}},
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "compute"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("2"), corev1.ResourcePods: resource.MustParse("10")},
+				Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("850m"), corev1.ResourcePods: resource.MustParse("3")},
+			},
+		},
+		&corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "defaults"},
+			Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+				Type:           corev1.LimitTypeContainer,
+				Default:        corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+				DefaultRequest: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi"), corev1.ResourceCPU: resource.MustParse("100m")},
+			}}},
+		},
+	)
+	withNamespaceClientset(t, cs)
+	withPodMetricsFakes(t, cs, newPodSample("team-a", "web", map[string][2]string{"app": {"300m", "200Mi"}}))
+
+	w := httptest.NewRecorder()
+	NamespaceDetailHandler(w, httptest.NewRequest(http.MethodGet, "/api/namespaces/team-a", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var detail NamespaceDetailResponse
+	if err := json.NewDecoder(w.Body).Decode(&detail); err != nil {
+		t.Fatal(err)
+	}
+
+	if detail.Status != "Active" || detail.Labels["team"] != "a" || detail.Annotations["owner"] != "alice" {
+		t.Errorf("unexpected metadata %+v", detail)
+	}
+	if detail.Pods.Total != 4 || detail.Pods.ByPhase["Running"] != 2 || detail.Pods.ByPhase["Succeeded"] != 1 || detail.Pods.ByPhase["Pending"] != 1 {
+		t.Errorf("pod counts = %+v", detail.Pods)
+	}
+	if detail.Workloads["Deployment"] != 1 || detail.Workloads["StatefulSet"] != 1 || detail.Workloads["CronJob"] != 1 || detail.Workloads["Job"] != 0 {
+		t.Errorf("workloads = %v", detail.Workloads)
+	}
+
+	res := detail.Resources
+	if !res.MetricsAvailable || res.CPU.Usage != 300 || res.CPU.Request != 850 || res.CPU.Limit != 1100 || res.CPUUnlimitedPods != 1 {
+		t.Errorf("cpu should sum non-terminated pods only: %+v", res)
+	}
+	if res.Memory.Request != 384<<20 || res.MemoryUnlimitedPods != 1 {
+		t.Errorf("memory = %+v", res)
+	}
+
+	if len(detail.Quotas) != 1 || len(detail.Quotas[0].Resources) != 2 {
+		t.Fatalf("quotas = %+v", detail.Quotas)
+	}
+	pods := detail.Quotas[0].Resources[0]
+	cpu := detail.Quotas[0].Resources[1]
+	if pods.Resource != "pods" || cpu.Resource != "requests.cpu" || cpu.Used != "850m" || *cpu.UsedPercent != 42.5 {
+		t.Errorf("quota resources = %+v %+v", pods, cpu)
+	}
+
+	if len(detail.LimitRanges) != 1 || len(detail.LimitRanges[0].Limits) != 2 {
+		t.Fatalf("limit ranges = %+v", detail.LimitRanges)
+	}
+	mem := detail.LimitRanges[0].Limits[1]
+	if mem.Resource != "memory" || mem.Default != "512Mi" || mem.DefaultRequest != "256Mi" || mem.Max != "" {
+		t.Errorf("memory limit range = %+v", mem)
+	}
+}
+
+func TestNamespaceDetailHandler_Errors(t *testing.T) {
+	withNamespaceClientset(t, fake.NewSimpleClientset())
+	withPodMetricsFakes(t, fake.NewSimpleClientset())
+
+	for path, want := range map[string]int{
+		"/api/namespaces/missing": http.StatusNotFound,
+		"/api/namespaces/":        http.StatusBadRequest,
+		"/api/namespaces/a/b":     http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		NamespaceDetailHandler(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	NamespaceDetailHandler(w, httptest.NewRequest(http.MethodDelete, "/api/namespaces/team-a", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// nodeDebugImageEnv and nodeDebugNamespaceEnv override the defaults below.
+	nodeDebugImageEnv     = "NODE_DEBUG_IMAGE"
+	nodeDebugNamespaceEnv = "NODE_DEBUG_NAMESPACE"
+
+	defaultNodeDebugImage     = "busybox:1.36"
+	defaultNodeDebugNamespace = "default"
+
+	nodeDebugContainerName = "debugger"
+	nodeDebugHostMountPath = "/host"
+)
+
+var (
+	// defaultNodeDebugTTL and maxNodeDebugTTL bound how long a node debug pod may live.
+	defaultNodeDebugTTL = time.Hour
+	maxNodeDebugTTL     = 24 * time.Hour
+
+	// nodeDebugReapInterval is how often expired node debug pods are deleted.
+	nodeDebugReapInterval = time.Minute
+
+	// nodeDebugSessionGrace is how long a node debug pod outlives its last shell
+	// session, so a reloaded or reconnecting browser still finds it.
+	nodeDebugSessionGrace = 30 * time.Second
+)
+
+// nodeDebugPodSuffix returns the random part of a node debug pod's name. Tests
+// may override this.
+var nodeDebugPodSuffix = func() string {
+	return utilrand.String(5)
+}
+
+// nodeDebugSessions counts the open shell sessions per node debug pod, keyed by
+// namespace/name, and holds the pending deletion of pods with none left.
+var nodeDebugSessions = struct {
+	sync.Mutex
+	counts map[string]int
+	timers map[string]*time.Timer
+}{counts: make(map[string]int), timers: make(map[string]*time.Timer)}
+
+// nodeDebugRequest is the optional JSON body for POST /api/nodes/{name}/debug.
+type nodeDebugRequest struct {
+	Image      string `json:"image,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	TTLSeconds int64  `json:"ttlSeconds,omitempty"`
+}
+
+// nodeDebugResponse describes the created node debug pod and how to open a shell in it.
+type nodeDebugResponse struct {
+	Pod       string    `json:"pod"`
+	Namespace string    `json:"namespace"`
+	Container string    `json:"container"`
+	Node      string    `json:"node"`
+	Ready     bool      `json:"ready"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	ExecPath  string    `json:"execPath"`
+}
+
+// handleNodeDebug handles POST /api/nodes/{name}/debug.
+// It schedules a privileged pod on the node with the host's PID, network and IPC
+// namespaces and the root filesystem mounted at /host, like `kubectl debug node/...`.
+// The pod is deleted when its exec session ends or once its TTL passes.
+func handleNodeDebug(w http.ResponseWriter, r *http.Request, nodeName string) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+	r = withTimeout(r)
+
+	var req nodeDebugRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, errMsgPodDebugInvalidReq)
+			return
+		}
+	}
+
+	ttl := defaultNodeDebugTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl > maxNodeDebugTTL {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("ttlSeconds must not exceed %d", int64(maxNodeDebugTTL.Seconds())))
+		return
+	}
+	if req.Image == "" {
+		req.Image = envOrDefault(nodeDebugImageEnv, defaultNodeDebugImage)
+	}
+	if req.Namespace == "" {
+		req.Namespace = envOrDefault(nodeDebugNamespaceEnv, defaultNodeDebugNamespace)
+	}
+
+	profiles, err := getDebugProfiles()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, errMsgDebugProfilesLoad)
+		return
+	}
+	if !profiles.imageAllowed(debugProfile{}, req.Image) {
+		writeError(w, http.StatusForbidden, errMsgPodDebugImageDenied)
+		return
+	}
+
+	clientset, err := getDebugClientset()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	if _, err := clientset.CoreV1().Nodes().Get(r.Context(), nodeName, metav1.GetOptions{}); err != nil {
+		writeResourceError(w, err, errMsgNodeNotFound, errMsgNodeDebugFailed)
+		return
+	}
+
+	expiresAt := nowFunc().Add(ttl).UTC().Truncate(time.Second)
+	pod, err := clientset.CoreV1().Pods(req.Namespace).Create(r.Context(),
+		buildNodeDebugPod(nodeName, req, ttl, expiresAt), metav1.CreateOptions{})
+	if err != nil {
+		slog.Error("Failed to create node debug pod", "error", err, "node", nodeName)
+		if k8serrors.IsForbidden(err) {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("%s: %s", errMsgNodeDebugFailed, err.Error()))
+			return
+		}
+		if k8serrors.IsAlreadyExists(err) {
+			writeError(w, http.StatusConflict, fmt.Sprintf("%s: %s", errMsgNodeDebugFailed, err.Error()))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, errMsgNodeDebugFailed)
+		return
+	}
+
+	ready, err := waitForContainerRunning(r.Context(), clientset, pod.Namespace, pod.Name, nodeDebugContainerName,
+		func(p *corev1.Pod) []corev1.ContainerStatus { return p.Status.ContainerStatuses },
+		errMsgNodeDebugFailed, errMsgNodeDebugNotReady)
+	if err != nil {
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+		defer cancel()
+		if delErr := deletePod(cleanupCtx, clientset, pod.Namespace, pod.Name); delErr != nil && !k8serrors.IsNotFound(delErr) {
+			slog.Error("Failed to clean up node debug pod", "error", delErr, "namespace", pod.Namespace, "pod", pod.Name)
+		}
+
+		var dErr *debugError
+		if errors.As(err, &dErr) {
+			writeError(w, dErr.status, dErr.message)
+			return
+		}
+		slog.Error("Failed to wait for node debug pod", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgNodeDebugFailed)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, nodeDebugResponse{
+		Pod:       pod.Name,
+		Namespace: pod.Namespace,
+		Container: nodeDebugContainerName,
+		Node:      nodeName,
+		Ready:     ready,
+		ExpiresAt: expiresAt,
+		ExecPath:  fmt.Sprintf("%s%s/%s?container=%s", podExecPathPrefix, pod.Namespace, pod.Name, nodeDebugContainerName),
+	})
+}
+
+// buildNodeDebugPod returns the privileged pod spec pinned to nodeName.
+// activeDeadlineSeconds stops the pod at its TTL even if the dashboard is not running.
+func buildNodeDebugPod(nodeName string, req nodeDebugRequest, ttl time.Duration, expiresAt time.Time) *corev1.Pod {
+	privileged := true
+	deadline := int64(ttl.Seconds())
+	hostPathType := corev1.HostPathDirectory
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nodeDebugPodName(nodeName, nodeDebugPodSuffix()),
+			Namespace: req.Namespace,
+			Labels:    map[string]string{labelNodeDebug: "true"},
+			Annotations: map[string]string{
+				annotationNodeDebugNode:      nodeName,
+				annotationNodeDebugExpiresAt: expiresAt.Format(time.RFC3339),
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:              nodeName,
+			HostPID:               true,
+			HostNetwork:           true,
+			HostIPC:               true,
+			RestartPolicy:         corev1.RestartPolicyNever,
+			ActiveDeadlineSeconds: &deadline,
+			// Tolerate everything so tainted and unschedulable nodes can be debugged too.
+			Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:            nodeDebugContainerName,
+				Image:           req.Image,
+				Command:         []string{"sleep", fmt.Sprintf("%d", deadline)},
+				Stdin:           true,
+				TTY:             true,
+				SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+				VolumeMounts:    []corev1.VolumeMount{{Name: "host-root", MountPath: nodeDebugHostMountPath}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "host-root",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{Path: "/", Type: &hostPathType},
+				},
+			}},
+		},
+	}
+}
+
+// nodeDebugPodName returns "node-debugger-<node>-<suffix>", truncating the node
+// name (which may be a long FQDN) to keep the result a valid pod hostname.
+func nodeDebugPodName(nodeName, suffix string) string {
+	name := strings.Trim(strings.ReplaceAll(nodeName, ".", "-"), "-")
+	suffix = "-" + suffix
+	prefix := "node-debugger-"
+	if max := maxPodCopyNameLength - len(prefix) - len(suffix); len(name) > max {
+		name = strings.TrimRight(name[:max], "-")
+	}
+	return prefix + name + suffix
+}
+
+// isNodeDebugPod reports whether the pod was created by handleNodeDebug.
+func isNodeDebugPod(pod *corev1.Pod) bool {
+	_, ok := pod.Labels[labelNodeDebug]
+	return ok
+}
+
+// trackNodeDebugSession registers a shell session on a node debug pod and returns
+// the function that ends it. The pod is deleted once its last session has ended
+// and no new one starts within nodeDebugSessionGrace; the reaper and
+// activeDeadlineSeconds still bound its life if the dashboard stops first.
+func trackNodeDebugSession(clientset kubernetes.Interface, namespace, name string) func() {
+	key := namespace + "/" + name
+	s := &nodeDebugSessions
+	s.Lock()
+	s.counts[key]++
+	if timer, ok := s.timers[key]; ok {
+		timer.Stop()
+		delete(s.timers, key)
+	}
+	s.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.Lock()
+			defer s.Unlock()
+			if s.counts[key]--; s.counts[key] > 0 {
+				return
+			}
+			delete(s.counts, key)
+			var timer *time.Timer
+			timer = time.AfterFunc(nodeDebugSessionGrace, func() {
+				s.Lock()
+				current := s.timers[key] == timer
+				if current {
+					delete(s.timers, key)
+				}
+				s.Unlock()
+				if current {
+					cleanupNodeDebugPod(clientset, namespace, name)
+				}
+			})
+			s.timers[key] = timer
+		})
+	}
+}
+
+// cleanupNodeDebugPod deletes a node debug pod after its last exec session has ended.
+func cleanupNodeDebugPod(clientset kubernetes.Interface, namespace, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	if err := deletePod(ctx, clientset, namespace, name); err != nil && !k8serrors.IsNotFound(err) {
+		slog.Error("Failed to delete node debug pod", "error", err, "namespace", namespace, "pod", name)
+		return
+	}
+	slog.Info("Deleted node debug pod after session ended", "namespace", namespace, "pod", name)
+}
+
+// StartNodeDebugReaper periodically deletes node debug pods that have expired or
+// finished, so pods outlive neither their TTL nor a dashboard restart.
+func StartNodeDebugReaper(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(nodeDebugReapInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			clientset, err := getDebugClientset()
+			if err != nil {
+				slog.Error("Failed to create Kubernetes client", "error", err)
+				continue
+			}
+			reapCtx, cancel := context.WithTimeout(ctx, apiTimeout)
+			reapNodeDebugPods(reapCtx, clientset, nowFunc())
+			cancel()
+		}
+	}()
+}
+
+// reapNodeDebugPods deletes node debug pods past their expiry or no longer running.
+func reapNodeDebugPods(ctx context.Context, clientset kubernetes.Interface, now time.Time) {
+	podList, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{LabelSelector: labelNodeDebug})
+	if err != nil {
+		slog.Error("Failed to list node debug pods", "error", err)
+		return
+	}
+	for _, pod := range podList.Items {
+		expired := pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+		if expiresAt, err := time.Parse(time.RFC3339, pod.Annotations[annotationNodeDebugExpiresAt]); err == nil && now.After(expiresAt) {
+			expired = true
+		}
+		if !expired || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if err := deletePod(ctx, clientset, pod.Namespace, pod.Name); err != nil && !k8serrors.IsNotFound(err) {
+			slog.Error("Failed to delete expired node debug pod", "error", err, "namespace", pod.Namespace, "pod", pod.Name)
+			continue
+		}
+		slog.Info("Deleted expired node debug pod", "namespace", pod.Namespace, "pod", pod.Name)
+	}
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newNodeDebugRequest(t *testing.T, node string, body any) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return httptest.NewRequest(http.MethodPost, "/api/nodes/"+node+"/debug", &buf)
+}
+
+// withNodeDebugPodSuffix pins nodeDebugPodSuffix so node debug pod names are predictable.
+func withNodeDebugPodSuffix(t *testing.T, suffix string) {
+	t.Helper()
+	old := nodeDebugPodSuffix
+	nodeDebugPodSuffix = func() string { return suffix }
+	t.Cleanup(func() { nodeDebugPodSuffix = old })
+}
+
+func newTestNode(name string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func TestNodeActionsHandler_Validation(t *testing.T) {
+	withDebugClientset(t, fake.NewSimpleClientset(newTestNode("node-1")))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		want   int
+	}{
+		{"unknown action", http.MethodPost, "/api/nodes/node-1/reboot", nil, http.StatusNotFound},
+		{"missing node name", http.MethodPost, "/api/nodes//debug", nil, http.StatusBadRequest},
+		{"wrong method", http.MethodGet, "/api/nodes/node-1/debug", nil, http.StatusMethodNotAllowed},
+		{"node not found", http.MethodPost, "/api/nodes/missing/debug", nil, http.StatusNotFound},
+		{"ttl too long", http.MethodPost, "/api/nodes/node-1/debug", nodeDebugRequest{TTLSeconds: 7 * 24 * 3600}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if tt.body != nil {
+				json.NewEncoder(&buf).Encode(tt.body) //nolint:errcheck
+			}
+			w := httptest.NewRecorder()
+			NodeActionsHandler(w, httptest.NewRequest(tt.method, tt.path, &buf))
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestNodeDebug_ImageAllowlist(t *testing.T) {
+	withDebugProfiles(t, debugProfilesConfig{AllowedImages: []string{"registry.internal/*"}})
+	withDebugClientset(t, fake.NewSimpleClientset(newTestNode("node-1")))
+
+	w := httptest.NewRecorder()
+	NodeActionsHandler(w, newNodeDebugRequest(t, "node-1", nodeDebugRequest{Image: "busybox:1.36"}))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestNodeDebug_DeniesUnlistedImageByDefault(t *testing.T) {
+	withDebugProfiles(t, debugProfilesConfig{})
+	withDebugClientset(t, fake.NewSimpleClientset(newTestNode("node-1")))
+
+	w := httptest.NewRecorder()
+	NodeActionsHandler(w, newNodeDebugRequest(t, "node-1", nodeDebugRequest{Image: "evil/shell:latest"}))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestNodeDebug_CreatesPrivilegedPod(t *testing.T) {
+	cs := fake.NewSimpleClientset(newTestNode("node-1"))
+	withDebugClientset(t, cs)
+	withFastPolling(t, time.Second, 5*time.Millisecond)
+	withFixedNow(t, time.Unix(1700000000, 0))
+	withNodeDebugPodSuffix(t, "x7k2q")
+	podName := "node-debugger-node-1-x7k2q"
+
+	go setContainerRunning(t, cs, "ops", podName, nodeDebugContainerName)
+
+	w := httptest.NewRecorder()
+	NodeActionsHandler(w, newNodeDebugRequest(t, "node-1", nodeDebugRequest{Namespace: "ops", TTLSeconds: 600}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp nodeDebugResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Pod != podName || !resp.Ready || resp.Node != "node-1" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if resp.ExecPath != "/api/pods/exec/ops/"+podName+"?container=debugger" {
+		t.Errorf("exec path = %q", resp.ExecPath)
+	}
+	if !resp.ExpiresAt.Equal(time.Unix(1700000600, 0)) {
+		t.Errorf("expiresAt = %v", resp.ExpiresAt)
+	}
+
+	pod, err := cs.CoreV1().Pods("ops").Get(context.Background(), podName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := pod.Spec
+	if spec.NodeName != "node-1" || !spec.HostPID || !spec.HostNetwork {
+		t.Errorf("pod not pinned to node with host namespaces: %+v", spec)
+	}
+	if spec.ActiveDeadlineSeconds == nil || *spec.ActiveDeadlineSeconds != 600 {
+		t.Errorf("activeDeadlineSeconds = %v", spec.ActiveDeadlineSeconds)
+	}
+	c := spec.Containers[0]
+	if c.Image != defaultNodeDebugImage || c.SecurityContext == nil || c.SecurityContext.Privileged == nil || !*c.SecurityContext.Privileged {
+		t.Errorf("unexpected container %+v", c)
+	}
+	if len(c.VolumeMounts) != 1 || c.VolumeMounts[0].MountPath != "/host" || spec.Volumes[0].HostPath.Path != "/" {
+		t.Errorf("host filesystem not mounted at /host")
+	}
+	if !isNodeDebugPod(pod) {
+		t.Error("pod missing node-debug label")
+	}
+}
+
+func TestNodeDebug_CleansUpOnTimeout(t *testing.T) {
+	cs := fake.NewSimpleClientset(newTestNode("node-1"))
+	withDebugClientset(t, cs)
+	withFastPolling(t, 30*time.Millisecond, 5*time.Millisecond)
+
+	w := httptest.NewRecorder()
+	NodeActionsHandler(w, newNodeDebugRequest(t, "node-1", nil))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d: %s", w.Code, w.Body.String())
+	}
+	pods, _ := cs.CoreV1().Pods("").List(context.Background(), metav1.ListOptions{})
+	if len(pods.Items) != 0 {
+		t.Errorf("expected node debug pod to be deleted, %d remain", len(pods.Items))
+	}
+}
+
+func TestTrackNodeDebugSession(t *testing.T) {
+	old := nodeDebugSessionGrace
+	nodeDebugSessionGrace = 20 * time.Millisecond
+	t.Cleanup(func() { nodeDebugSessionGrace = old })
+	cs := fake.NewSimpleClientset(newRunningPod("ops", "node-debugger-node-1"))
+	exists := func() bool {
+		_, err := cs.CoreV1().Pods("ops").Get(context.Background(), "node-debugger-node-1", metav1.GetOptions{})
+		return err == nil
+	}
+
+	first := trackNodeDebugSession(cs, "ops", "node-debugger-node-1")
+	second := trackNodeDebugSession(cs, "ops", "node-debugger-node-1")
+	first()
+	first()
+	time.Sleep(60 * time.Millisecond)
+	if !exists() {
+		t.Fatal("pod deleted while a session was still open")
+	}
+
+	// A reconnect within the grace period keeps the pod.
+	second()
+	reconnected := trackNodeDebugSession(cs, "ops", "node-debugger-node-1")
+	time.Sleep(60 * time.Millisecond)
+	if !exists() {
+		t.Fatal("pod deleted although a session reconnected within the grace period")
+	}
+
+	reconnected()
+	deadline := time.Now().Add(time.Second)
+	for exists() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if exists() {
+		t.Error("pod not deleted after its last session ended")
+	}
+}
+
+func TestReapNodeDebugPods(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	newDebugPod := func(name string, expiresAt time.Time, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Labels:      map[string]string{labelNodeDebug: "true"},
+				Annotations: map[string]string{annotationNodeDebugExpiresAt: expiresAt.Format(time.RFC3339)},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	unlabeled := newRunningPod("default", "app")
+	cs := fake.NewSimpleClientset(
+		newDebugPod("expired", now.Add(-time.Minute), corev1.PodRunning),
+		newDebugPod("finished", now.Add(time.Hour), corev1.PodFailed),
+		newDebugPod("active", now.Add(time.Hour), corev1.PodRunning),
+		unlabeled,
+	)
+
+	reapNodeDebugPods(context.Background(), cs, now)
+
+	pods, _ := cs.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+	var names []string
+	for _, p := range pods.Items {
+		names = append(names, p.Name)
+	}
+	if strings.Join(names, ",") != "active,app" {
+		t.Errorf("remaining pods = %v", names)
+	}
+}
+
+func TestNodeDebugPodName(t *testing.T) {
+	if got := nodeDebugPodName("ip-10-0-0-1.ec2.internal", "x7k2q"); got != "node-debugger-ip-10-0-0-1-ec2-internal-x7k2q" {
+		t.Errorf("got %q", got)
+	}
+	if got := nodeDebugPodName(strings.Repeat("n", 100), "x7k2q"); len(got) > maxPodCopyNameLength {
+		t.Errorf("name too long: %q", got)
+	}
+	if a, b := nodeDebugPodSuffix(), nodeDebugPodSuffix(); a == b || len(a) != 5 {
+		t.Errorf("suffixes should be random, got %q and %q", a, b)
+	}
+}
+
+func TestNodeDebug_NameTaken(t *testing.T) {
+	withNodeDebugPodSuffix(t, "x7k2q")
+	withDebugClientset(t, fake.NewSimpleClientset(newTestNode("node-1"), newRunningPod("default", "node-debugger-node-1-x7k2q")))
+
+	w := httptest.NewRecorder()
+	NodeActionsHandler(w, newNodeDebugRequest(t, "node-1", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
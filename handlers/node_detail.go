@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxNodeEvents caps how many recent events the node detail returns.
+const maxNodeEvents = 50
+
+// getNodeClientset is a package-level variable for obtaining the Kubernetes client
+// used by node detail and node maintenance endpoints. Tests may override this.
+var getNodeClientset func() (kubernetes.Interface, error) = func() (kubernetes.Interface, error) {
+	return getKubernetesClient()
+}
+
+// NodeCondition is a node status condition such as MemoryPressure.
+type NodeCondition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+}
+
+// NodeTaint is a taint on a node.
+type NodeTaint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+// NodeAddress is one of a node's addresses (InternalIP, Hostname, ...).
+type NodeAddress struct {
+	Type    string `json:"type"`
+	Address string `json:"address"`
+}
+
+// NodeSystemInfo holds the versions reported by the kubelet.
+type NodeSystemInfo struct {
+	KubeletVersion          string `json:"kubeletVersion"`
+	KubeProxyVersion        string `json:"kubeProxyVersion"`
+	KernelVersion           string `json:"kernelVersion"`
+	OSImage                 string `json:"osImage"`
+	OperatingSystem         string `json:"operatingSystem"`
+	Architecture            string `json:"architecture"`
+	ContainerRuntimeVersion string `json:"containerRuntimeVersion"`
+}
+
+// NodeResources is a set of node resource quantities. CPU is in millicores,
+// memory and ephemeral storage in bytes.
+type NodeResources struct {
+	CPUMillis             int64 `json:"cpuMillis"`
+	MemoryBytes           int64 `json:"memoryBytes"`
+	Pods                  int64 `json:"pods"`
+	EphemeralStorageBytes int64 `json:"ephemeralStorageBytes"`
+}
+
+// NodeAllocation is the sum of requests and limits of the non-terminated pods
+// on a node, with each sum also given as a percentage of allocatable.
+type NodeAllocation struct {
+	CPURequestsMillis     int64   `json:"cpuRequestsMillis"`
+	CPULimitsMillis       int64   `json:"cpuLimitsMillis"`
+	MemoryRequestsBytes   int64   `json:"memoryRequestsBytes"`
+	MemoryLimitsBytes     int64   `json:"memoryLimitsBytes"`
+	CPURequestsPercent    float64 `json:"cpuRequestsPercent"`
+	CPULimitsPercent      float64 `json:"cpuLimitsPercent"`
+	MemoryRequestsPercent float64 `json:"memoryRequestsPercent"`
+	MemoryLimitsPercent   float64 `json:"memoryLimitsPercent"`
+	PodCount              int     `json:"podCount"`
+}
+
+// ResourceEvent is an event recorded against an object.
+type ResourceEvent struct {
+	Type     string    `json:"type"`
+	Reason   string    `json:"reason"`
+	Message  string    `json:"message"`
+	Count    int32     `json:"count"`
+	Source   string    `json:"source,omitempty"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// NodeDetailResponse is returned by GET /api/nodes/{name}.
+type NodeDetailResponse struct {
+	NodeInfo
+	Unschedulable bool              `json:"unschedulable"`
+	Age           string            `json:"age"`
+	Pressures     []string          `json:"pressures"`
+	Conditions    []NodeCondition   `json:"conditions"`
+	Taints        []NodeTaint       `json:"taints"`
+	Labels        map[string]string `json:"labels"`
+	Addresses     []NodeAddress     `json:"addresses"`
+	PodCIDR       string            `json:"podCIDR,omitempty"`
+	ProviderID    string            `json:"providerID,omitempty"`
+	SystemInfo    NodeSystemInfo    `json:"systemInfo"`
+	Capacity      NodeResources     `json:"capacity"`
+	Allocatable   NodeResources     `json:"allocatable"`
+	Pods          []PodDetails      `json:"pods"`
+	Events        []ResourceEvent   `json:"events"`
+}
+
+// handleNodeDetail handles GET /api/nodes/{name}.
+func handleNodeDetail(w http.ResponseWriter, r *http.Request, nodeName string) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	r = withTimeout(r)
+
+	clientset, err := getNodeClientset()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	detail, err := getNodeDetail(r.Context(), clientset, nodeName)
+	if err != nil {
+		writeResourceError(w, err, errMsgNodeNotFound, errMsgNodeDetailFetch)
+		return
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// getNodeDetail assembles the node detail from the node, its pods and its events.
+func getNodeDetail(ctx context.Context, clientset kubernetes.Interface, nodeName string) (*NodeDetailResponse, error) {
+	node, err := clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	podList, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metricsMap := fetchNodeMetricsFrom(ctx, getPodMetricsClient())
+
+	detail := &NodeDetailResponse{
+		NodeInfo:      buildNodeInfo(*node, metricsMap, podList.Items),
+		Unschedulable: node.Spec.Unschedulable,
+		Age:           formatPodAge(node.CreationTimestamp.Time),
+		Pressures:     []string{},
+		Conditions:    make([]NodeCondition, 0, len(node.Status.Conditions)),
+		Taints:        make([]NodeTaint, 0, len(node.Spec.Taints)),
+		Labels:        node.Labels,
+		Addresses:     make([]NodeAddress, 0, len(node.Status.Addresses)),
+		PodCIDR:       node.Spec.PodCIDR,
+		ProviderID:    node.Spec.ProviderID,
+		SystemInfo: NodeSystemInfo{
+			KubeletVersion:          node.Status.NodeInfo.KubeletVersion,
+			KubeProxyVersion:        node.Status.NodeInfo.KubeProxyVersion,
+			KernelVersion:           node.Status.NodeInfo.KernelVersion,
+			OSImage:                 node.Status.NodeInfo.OSImage,
+			OperatingSystem:         node.Status.NodeInfo.OperatingSystem,
+			Architecture:            node.Status.NodeInfo.Architecture,
+			ContainerRuntimeVersion: node.Status.NodeInfo.ContainerRuntimeVersion,
+		},
+		Capacity:    nodeResourcesFrom(node.Status.Capacity),
+		Allocatable: nodeResourcesFrom(node.Status.Allocatable),
+		Pods:        make([]PodDetails, 0, len(podList.Items)),
+	}
+	if detail.Labels == nil {
+		detail.Labels = map[string]string{}
+	}
+
+	for _, c := range node.Status.Conditions {
+		detail.Conditions = append(detail.Conditions, NodeCondition{
+			Type:               string(c.Type),
+			Status:             string(c.Status),
+			Reason:             c.Reason,
+			Message:            c.Message,
+			LastTransitionTime: c.LastTransitionTime.Time,
+		})
+		if c.Type != corev1.NodeReady && c.Status == corev1.ConditionTrue {
+			detail.Pressures = append(detail.Pressures, string(c.Type))
+		}
+	}
+	for _, t := range node.Spec.Taints {
+		detail.Taints = append(detail.Taints, NodeTaint{Key: t.Key, Value: t.Value, Effect: string(t.Effect)})
+	}
+	for _, a := range node.Status.Addresses {
+		detail.Addresses = append(detail.Addresses, NodeAddress{Type: string(a.Type), Address: a.Address})
+	}
+
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName != nodeName {
+			continue
+		}
+		detail.Pods = append(detail.Pods, buildPodDetails(pod, nil))
+	}
+
+	events, err := listNodeEvents(ctx, clientset, nodeName)
+	if err != nil {
+		slog.Warn("Failed to list node events", "error", err, "node", nodeName)
+		events = []ResourceEvent{}
+	}
+	detail.Events = events
+
+	return detail, nil
+}
+
+func nodeResourcesFrom(list corev1.ResourceList) NodeResources {
+	return NodeResources{
+		CPUMillis:             list.Cpu().MilliValue(),
+		MemoryBytes:           list.Memory().Value(),
+		Pods:                  list.Pods().Value(),
+		EphemeralStorageBytes: list.StorageEphemeral().Value(),
+	}
+}
+
+// calculateNodeAllocation sums requests and limits of the non-terminated pods on
+// the node, as `kubectl describe node` does. Containers without a limit add nothing
+// to the limit totals.
+func calculateNodeAllocation(pods []corev1.Pod, nodeName string, allocatable NodeResources) NodeAllocation {
+	var alloc NodeAllocation
+	for _, pod := range pods {
+		if pod.Spec.NodeName != nodeName || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		alloc.PodCount++
+
+		res := buildPodResources(pod, nil)
+		alloc.CPURequestsMillis += res.CPU.Request
+		alloc.MemoryRequestsBytes += res.Memory.Request
+		for _, c := range res.Containers {
+			alloc.CPULimitsMillis += c.CPU.Limit
+			alloc.MemoryLimitsBytes += c.Memory.Limit
+		}
+	}
+
+	percent := func(v, total int64) float64 {
+		if total <= 0 {
+			return 0
+		}
+		return float64(v) / float64(total) * 100
+	}
+	alloc.CPURequestsPercent = percent(alloc.CPURequestsMillis, allocatable.CPUMillis)
+	alloc.CPULimitsPercent = percent(alloc.CPULimitsMillis, allocatable.CPUMillis)
+	alloc.MemoryRequestsPercent = percent(alloc.MemoryRequestsBytes, allocatable.MemoryBytes)
+	alloc.MemoryLimitsPercent = percent(alloc.MemoryLimitsBytes, allocatable.MemoryBytes)
+	return alloc
+}
+
+// listNodeEvents returns the node's most recent events, newest first.
+func listNodeEvents(ctx context.Context, clientset kubernetes.Interface, nodeName string) ([]ResourceEvent, error) {
+	eventList, err := clientset.CoreV1().Events("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.Set{"involvedObject.kind": "Node", "involvedObject.name": nodeName}.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	matching := make([]corev1.Event, 0, len(eventList.Items))
+	for _, ev := range eventList.Items {
+		if ev.InvolvedObject.Kind == "Node" && ev.InvolvedObject.Name == nodeName {
+			matching = append(matching, ev)
+		}
+	}
+	sort.SliceStable(matching, func(i, j int) bool { return eventTime(matching[i]).After(eventTime(matching[j])) })
+	if len(matching) > maxNodeEvents {
+		matching = matching[:maxNodeEvents]
+	}
+
+	events := make([]ResourceEvent, 0, len(matching))
+	for _, ev := range matching {
+		events = append(events, buildResourceEvent(ev))
+	}
+	return events, nil
+}
+
+func buildResourceEvent(ev corev1.Event) ResourceEvent {
+	source := ev.Source.Component
+	if source == "" {
+		source = ev.ReportingController
+	}
+	return ResourceEvent{
+		Type:     ev.Type,
+		Reason:   ev.Reason,
+		Message:  ev.Message,
+		Count:    eventCount(ev),
+		Source:   source,
+		LastSeen: eventTime(ev),
+	}
+}
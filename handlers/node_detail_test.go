@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// withNodeClientset injects a fake clientset for node endpoints and disables metrics.
+func withNodeClientset(t *testing.T, cs kubernetes.Interface) {
+	t.Helper()
+	oldCS, oldMC := getNodeClientset, getPodMetricsClient
+	getNodeClientset = func() (kubernetes.Interface, error) { return cs, nil }
+	getPodMetricsClient = func() metricsv.Interface { return nil }
+	t.Cleanup(func() {
+		getNodeClientset = oldCS
+		getPodMetricsClient = oldMC
+	})
+}
+
+func newDetailedNode() *corev1.Node {
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("8Gi"),
+		corev1.ResourcePods:   resource.MustParse("110"),
+	}
+	allocatable := resources.DeepCopy()
+	allocatable[corev1.ResourceCPU] = resource.MustParse("2")
+	allocatable[corev1.ResourceMemory] = resource.MustParse("4Gi")
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"node-role.kubernetes.io/worker": ""}},
+		Spec: corev1.NodeSpec{
+			Unschedulable: true,
+			Taints:        []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+		},
+		Status: corev1.NodeStatus{
+			Capacity:    resources,
+			Allocatable: allocatable,
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue, Reason: "KubeletHasInsufficientMemory"},
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse},
+			},
+			Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.1"}},
+			NodeInfo:  corev1.NodeSystemInfo{KubeletVersion: "v1.29.1", KernelVersion: "6.1.0", ContainerRuntimeVersion: "containerd://1.7.0"},
+		},
+	}
+}
+
+func TestNodeDetailHandler(t *testing.T) {
+	running := newPodWithResources("default", "web", resourceContainer("app", "500m", "1", "1Gi", "2Gi"))
+	running.Spec.NodeName = "node-1"
+	finished := newPodWithResources("default", "job", resourceContainer("app", "1", "", "", ""))
+	finished.Spec.NodeName = "node-1"
+	finished.Status.Phase = corev1.PodSucceeded
+	elsewhere := newPodWithResources("default", "other", resourceContainer("app", "1", "", "", ""))
+	elsewhere.Spec.NodeName = "node-2"
+
+	now := time.Now()
+	cs := fake.NewSimpleClientset(newDetailedNode(), running, finished, elsewhere,
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "node-1.a", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+			Type:           corev1.EventTypeWarning, Reason: "OldEvent", LastTimestamp: metav1.NewTime(now.Add(-time.Hour)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "node-1.b", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+			Type:           corev1.EventTypeNormal, Reason: "NodeNotSchedulable", Count: 2, LastTimestamp: metav1.NewTime(now),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "pod.c", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "node-1"},
+			Reason:         "PodEvent",
+		},
+	)
+	withNodeClientset(t, cs)
+
+	w := httptest.NewRecorder()
+	NodeActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/nodes/node-1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var detail NodeDetailResponse
+	if err := json.NewDecoder(w.Body).Decode(&detail); err != nil {
+		t.Fatal(err)
+	}
+
+	if detail.Name != "node-1" || detail.Role != "worker" || !detail.Unschedulable || detail.Status != nodeStatusReadyScheduleDisabled {
+		t.Errorf("unexpected summary %+v", detail.NodeInfo)
+	}
+	if len(detail.Pressures) != 1 || detail.Pressures[0] != "MemoryPressure" {
+		t.Errorf("pressures = %v", detail.Pressures)
+	}
+	if len(detail.Taints) != 1 || detail.Taints[0].Effect != "NoSchedule" {
+		t.Errorf("taints = %+v", detail.Taints)
+	}
+	if detail.SystemInfo.KubeletVersion != "v1.29.1" || detail.Addresses[0].Address != "10.0.0.1" {
+		t.Errorf("system info/addresses missing: %+v %+v", detail.SystemInfo, detail.Addresses)
+	}
+	if detail.Capacity.CPUMillis != 4000 || detail.Allocatable.CPUMillis != 2000 || detail.Allocatable.Pods != 110 {
+		t.Errorf("capacity/allocatable = %+v / %+v", detail.Capacity, detail.Allocatable)
+	}
+
+	alloc := detail.Allocation
+	if alloc.PodCount != 1 || alloc.CPURequestsMillis != 500 || alloc.CPURequestsPercent != 25 ||
+		alloc.CPULimitsPercent != 50 || alloc.MemoryLimitsPercent != 50 {
+		t.Errorf("allocation should only count the running pod: %+v", alloc)
+	}
+	if len(detail.Pods) != 2 {
+		t.Errorf("expected both pods on the node, got %d", len(detail.Pods))
+	}
+	if len(detail.Events) != 2 || detail.Events[0].Reason != "NodeNotSchedulable" || detail.Events[0].Count != 2 {
+		t.Errorf("events should be node-only and newest first: %+v", detail.Events)
+	}
+}
+
+func TestNodeDetailHandler_Errors(t *testing.T) {
+	withNodeClientset(t, fake.NewSimpleClientset())
+
+	w := httptest.NewRecorder()
+	NodeActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/nodes/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	NodeActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/nodes/missing", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
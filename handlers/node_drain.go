@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// annotationMirrorPod marks static pods mirrored into the API by the kubelet.
+const annotationMirrorPod = "kubernetes.io/config.mirror"
+
+var (
+	// defaultDrainTimeout bounds a drain that sets no timeoutSeconds.
+	defaultDrainTimeout = 5 * time.Minute
+	// drainRetryInterval is how long to wait before retrying an eviction blocked by a PodDisruptionBudget.
+	drainRetryInterval = 5 * time.Second
+	// drainPollInterval is how often drain checks whether evicted pods are gone.
+	drainPollInterval = 2 * time.Second
+)
+
+// Drain progress event types.
+const (
+	drainEventStarted   = "started"
+	drainEventSkipped   = "skipped"
+	drainEventEvicted   = "evicted"
+	drainEventBlocked   = "blocked"
+	drainEventFailed    = "failed"
+	drainEventDeleted   = "deleted"
+	drainEventCompleted = "completed"
+	drainEventCancelled = "cancelled"
+)
+
+// drainRequest is the optional JSON body for POST /api/nodes/{name}/drain.
+type drainRequest struct {
+	// DeleteEmptyDirData allows evicting pods with emptyDir volumes, whose data is lost.
+	DeleteEmptyDirData bool `json:"deleteEmptyDirData"`
+	// Force allows evicting pods not managed by a controller; they are not recreated.
+	Force bool `json:"force"`
+	// GracePeriodSeconds overrides each pod's terminationGracePeriodSeconds when set.
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds,omitempty"`
+	TimeoutSeconds     int64  `json:"timeoutSeconds,omitempty"`
+}
+
+// DrainEvent is one progress update streamed while a node drains.
+type DrainEvent struct {
+	Type      string `json:"type"`
+	Namespace string `json:"namespace,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// activeDrains tracks running drains by node so they can be cancelled.
+var (
+	activeDrains   = make(map[string]context.CancelFunc)
+	activeDrainsMu sync.Mutex
+)
+
+// handleNodeCordon handles POST /api/nodes/{name}/cordon.
+func handleNodeCordon(w http.ResponseWriter, r *http.Request, nodeName string) {
+	setNodeSchedulable(w, r, nodeName, true)
+}
+
+// handleNodeUncordon handles POST /api/nodes/{name}/uncordon.
+func handleNodeUncordon(w http.ResponseWriter, r *http.Request, nodeName string) {
+	setNodeSchedulable(w, r, nodeName, false)
+}
+
+func setNodeSchedulable(w http.ResponseWriter, r *http.Request, nodeName string, unschedulable bool) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+	r = withTimeout(r)
+
+	clientset, err := getNodeClientset()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	if err := patchNodeUnschedulable(r.Context(), clientset, nodeName, unschedulable); err != nil {
+		writeResourceError(w, err, errMsgNodeNotFound, errMsgNodeCordon)
+		return
+	}
+
+	action := "uncordoned"
+	if unschedulable {
+		action = "cordoned"
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":       fmt.Sprintf("Node %s %s", nodeName, action),
+		"unschedulable": unschedulable,
+	})
+}
+
+func patchNodeUnschedulable(ctx context.Context, clientset kubernetes.Interface, nodeName string, unschedulable bool) error {
+	patch := fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable)
+	_, err := clientset.CoreV1().Nodes().Patch(ctx, nodeName, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+// handleNodeDrain handles POST /api/nodes/{name}/drain, which cordons the node and
+// evicts its pods while streaming DrainEvents as server-sent events, and
+// DELETE /api/nodes/{name}/drain, which cancels a running drain.
+// Closing the stream also cancels the drain; pods already evicted stay evicted.
+func handleNodeDrain(w http.ResponseWriter, r *http.Request, nodeName string) {
+	switch r.Method {
+	case http.MethodPost:
+		startNodeDrain(w, r, nodeName)
+	case http.MethodDelete:
+		activeDrainsMu.Lock()
+		cancel, ok := activeDrains[nodeName]
+		activeDrainsMu.Unlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, errMsgNodeDrainNotRunning)
+			return
+		}
+		cancel()
+		writeJSON(w, http.StatusOK, map[string]string{"message": "Drain cancelled"})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func startNodeDrain(w http.ResponseWriter, r *http.Request, nodeName string) {
+	var req drainRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, errMsgNodeDrainInvalidReq)
+			return
+		}
+	}
+	if req.GracePeriodSeconds != nil && *req.GracePeriodSeconds < 0 {
+		writeError(w, http.StatusBadRequest, errMsgNodeDrainInvalidReq)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	clientset, err := getNodeClientset()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+	if _, err := clientset.CoreV1().Nodes().Get(r.Context(), nodeName, metav1.GetOptions{}); err != nil {
+		writeResourceError(w, err, errMsgNodeNotFound, errMsgNodeDrain)
+		return
+	}
+
+	timeout := defaultDrainTimeout
+	if req.TimeoutSeconds > 0 {
+		timeout = time.Duration(req.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	activeDrainsMu.Lock()
+	if _, running := activeDrains[nodeName]; running {
+		activeDrainsMu.Unlock()
+		writeError(w, http.StatusConflict, errMsgNodeDrainRunning)
+		return
+	}
+	activeDrains[nodeName] = cancel
+	activeDrainsMu.Unlock()
+	defer func() {
+		activeDrainsMu.Lock()
+		delete(activeDrains, nodeName)
+		activeDrainsMu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	var mu sync.Mutex
+	emit := func(ev DrainEvent) {
+		data, _ := json.Marshal(ev)
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w, "data: %s\n\n", data) //nolint:errcheck
+		flusher.Flush()
+	}
+
+	drainNode(ctx, clientset, nodeName, req, emit)
+}
+
+// drainNode cordons the node and evicts its pods, reporting progress through emit.
+// Like `kubectl drain`, it refuses to start when a pod would lose data or not be
+// recreated unless the matching option is set.
+func drainNode(ctx context.Context, clientset kubernetes.Interface, nodeName string, req drainRequest, emit func(DrainEvent)) {
+	if err := patchNodeUnschedulable(ctx, clientset, nodeName, true); err != nil {
+		emit(DrainEvent{Type: drainEventFailed, Message: fmt.Sprintf("cordon failed: %v", err)})
+		return
+	}
+
+	podList, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		emit(DrainEvent{Type: drainEventFailed, Message: fmt.Sprintf("listing pods failed: %v", err)})
+		return
+	}
+
+	var toEvict []corev1.Pod
+	var refused []DrainEvent
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName != nodeName {
+			continue
+		}
+		if skip, reason := drainSkipReason(pod); skip {
+			emit(DrainEvent{Type: drainEventSkipped, Namespace: pod.Namespace, Pod: pod.Name, Message: reason})
+			continue
+		}
+		if reason := drainRefusalReason(pod, req); reason != "" {
+			refused = append(refused, DrainEvent{Type: drainEventFailed, Namespace: pod.Namespace, Pod: pod.Name, Message: reason})
+			continue
+		}
+		toEvict = append(toEvict, pod)
+	}
+	if len(refused) > 0 {
+		for _, ev := range refused {
+			emit(ev)
+		}
+		emit(DrainEvent{Type: drainEventFailed, Message: "Drain aborted before evicting any pods; the node stays cordoned"})
+		return
+	}
+
+	emit(DrainEvent{Type: drainEventStarted, Message: fmt.Sprintf("Evicting %d pod(s)", len(toEvict))})
+
+	var wg sync.WaitGroup
+	results := make(chan bool, len(toEvict))
+	for _, pod := range toEvict {
+		wg.Add(1)
+		go func(pod corev1.Pod) {
+			defer wg.Done()
+			results <- evictAndWait(ctx, clientset, pod, req.GracePeriodSeconds, emit)
+		}(pod)
+	}
+	wg.Wait()
+	close(results)
+
+	failed := 0
+	for ok := range results {
+		if !ok {
+			failed++
+		}
+	}
+
+	switch {
+	case ctx.Err() == context.Canceled:
+		emit(DrainEvent{Type: drainEventCancelled, Message: "Drain cancelled; the node stays cordoned"})
+	case ctx.Err() == context.DeadlineExceeded:
+		emit(DrainEvent{Type: drainEventFailed, Message: "Drain timed out; the node stays cordoned"})
+	case failed > 0:
+		emit(DrainEvent{Type: drainEventFailed, Message: fmt.Sprintf("%d pod(s) could not be evicted", failed)})
+	default:
+		emit(DrainEvent{Type: drainEventCompleted, Message: fmt.Sprintf("Node %s drained", nodeName)})
+	}
+}
+
+// drainSkipReason reports pods drain leaves in place: DaemonSet pods, which
+// would be recreated on the node anyway, mirror pods and finished pods.
+func drainSkipReason(pod corev1.Pod) (bool, string) {
+	if _, ok := pod.Annotations[annotationMirrorPod]; ok {
+		return true, "static pod managed by the kubelet"
+	}
+	if owner := metav1.GetControllerOf(&pod); owner != nil && owner.Kind == "DaemonSet" {
+		return true, "managed by DaemonSet " + owner.Name
+	}
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return true, "pod has already finished"
+	}
+	return false, ""
+}
+
+// drainRefusalReason explains why a pod cannot be evicted with the given options.
+func drainRefusalReason(pod corev1.Pod, req drainRequest) string {
+	if !req.Force && metav1.GetControllerOf(&pod) == nil {
+		return "pod is not managed by a controller and will not be recreated (set force to evict it)"
+	}
+	if !req.DeleteEmptyDirData {
+		for _, v := range pod.Spec.Volumes {
+			if v.EmptyDir != nil {
+				return fmt.Sprintf("pod uses emptyDir volume %q whose data would be lost (set deleteEmptyDirData to evict it)", v.Name)
+			}
+		}
+	}
+	return ""
+}
+
+// evictAndWait evicts a pod, retrying while a PodDisruptionBudget blocks it, then
+// waits for the pod to be deleted. It reports whether the pod is gone.
+func evictAndWait(ctx context.Context, clientset kubernetes.Interface, pod corev1.Pod, gracePeriod *int64, emit func(DrainEvent)) bool {
+	eviction := &policyv1.Eviction{
+		ObjectMeta:    metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		DeleteOptions: &metav1.DeleteOptions{GracePeriodSeconds: gracePeriod},
+	}
+
+	blockedReported := false
+	for {
+		err := clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+		if err == nil || k8serrors.IsNotFound(err) {
+			emit(DrainEvent{Type: drainEventEvicted, Namespace: pod.Namespace, Pod: pod.Name})
+			break
+		}
+		if !k8serrors.IsTooManyRequests(err) {
+			if ctx.Err() != nil {
+				return false
+			}
+			emit(DrainEvent{Type: drainEventFailed, Namespace: pod.Namespace, Pod: pod.Name, Message: err.Error()})
+			return false
+		}
+		// 429 means a PodDisruptionBudget does not allow the disruption yet.
+		if !blockedReported {
+			emit(DrainEvent{Type: drainEventBlocked, Namespace: pod.Namespace, Pod: pod.Name, Message: err.Error()})
+			blockedReported = true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(drainRetryInterval):
+		}
+	}
+
+	for {
+		current, err := clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) || (err == nil && current.UID != pod.UID) {
+			emit(DrainEvent{Type: drainEventDeleted, Namespace: pod.Namespace, Pod: pod.Name})
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func withFastDrain(t *testing.T) {
+	t.Helper()
+	oldRetry, oldPoll := drainRetryInterval, drainPollInterval
+	drainRetryInterval, drainPollInterval = 5*time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() { drainRetryInterval, drainPollInterval = oldRetry, oldPoll })
+}
+
+func newNodePod(name, node, ownerKind string) *corev1.Pod {
+	pod := newRunningPod("default", name, "app")
+	pod.Spec.NodeName = node
+	if ownerKind != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: name + "-owner", Controller: &controller}}
+	}
+	return pod
+}
+
+// withEvictionReactor evicts pods by deleting them, answering 429 for the pods in
+// blocked until they have been attempted the given number of times (-1 = always).
+func withEvictionReactor(cs *fake.Clientset, blocked map[string]int) *[]string {
+	var evicted []string
+	cs.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(clienttesting.CreateAction).GetObject().(interface{ GetName() string })
+		name := eviction.GetName()
+		if n, ok := blocked[name]; ok && n != 0 {
+			blocked[name] = n - 1
+			return true, nil, k8serrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 1)
+		}
+		evicted = append(evicted, name)
+		gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+		return true, nil, cs.Tracker().Delete(gvr, action.GetNamespace(), name)
+	})
+	return &evicted
+}
+
+func readDrainEvents(t *testing.T, body string) []DrainEvent {
+	t.Helper()
+	var events []DrainEvent
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var ev DrainEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			t.Fatalf("bad event %q: %v", data, err)
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func eventTypesFor(events []DrainEvent, pod string) []string {
+	var types []string
+	for _, ev := range events {
+		if ev.Pod == pod {
+			types = append(types, ev.Type)
+		}
+	}
+	return types
+}
+
+func TestNodeCordonUncordon(t *testing.T) {
+	cs := fake.NewSimpleClientset(newTestNode("node-1"))
+	withNodeClientset(t, cs)
+
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"/api/nodes/node-1/cordon", true},
+		{"/api/nodes/node-1/uncordon", false},
+	} {
+		w := httptest.NewRecorder()
+		NodeActionsHandler(w, httptest.NewRequest(http.MethodPost, tc.path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", tc.path, w.Code, w.Body.String())
+		}
+		node, _ := cs.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+		if node.Spec.Unschedulable != tc.want {
+			t.Errorf("%s: unschedulable = %v", tc.path, node.Spec.Unschedulable)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	NodeActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/nodes/missing/cordon", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing node: expected 404, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	NodeActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/nodes/node-1/cordon", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: expected 405, got %d", w.Code)
+	}
+}
+
+func TestNodeDrain(t *testing.T) {
+	withFastDrain(t)
+	mirror := newNodePod("kube-proxy", "node-1", "")
+	mirror.Annotations = map[string]string{annotationMirrorPod: "abc"}
+	cs := fake.NewSimpleClientset(newTestNode("node-1"),
+		newNodePod("web", "node-1", "ReplicaSet"),
+		newNodePod("guarded", "node-1", "ReplicaSet"),
+		newNodePod("fluentd", "node-1", "DaemonSet"),
+		mirror,
+		newNodePod("other", "node-2", "ReplicaSet"),
+	)
+	evicted := withEvictionReactor(cs, map[string]int{"guarded": 2})
+	withNodeClientset(t, cs)
+
+	w := httptest.NewRecorder()
+	NodeActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/nodes/node-1/drain", strings.NewReader(`{"gracePeriodSeconds":10}`)))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected event stream, got %d: %s", w.Code, w.Body.String())
+	}
+	events := readDrainEvents(t, w.Body.String())
+
+	if last := events[len(events)-1]; last.Type != drainEventCompleted {
+		t.Errorf("expected drain to complete, got %+v", events)
+	}
+	if got := eventTypesFor(events, "guarded"); strings.Join(got, ",") != "blocked,evicted,deleted" {
+		t.Errorf("guarded pod events = %v", got)
+	}
+	if got := eventTypesFor(events, "web"); strings.Join(got, ",") != "evicted,deleted" {
+		t.Errorf("web pod events = %v", got)
+	}
+	for _, pod := range []string{"fluentd", "kube-proxy"} {
+		if got := eventTypesFor(events, pod); len(got) != 1 || got[0] != drainEventSkipped {
+			t.Errorf("%s should be skipped, got %v", pod, got)
+		}
+	}
+	if len(*evicted) != 2 {
+		t.Errorf("expected 2 evictions, got %v", *evicted)
+	}
+	node, _ := cs.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	if !node.Spec.Unschedulable {
+		t.Error("drain should cordon the node")
+	}
+}
+
+func TestNodeDrain_RefusesUnsafePods(t *testing.T) {
+	withFastDrain(t)
+	scratch := newNodePod("scratch", "node-1", "ReplicaSet")
+	scratch.Spec.Volumes = []corev1.Volume{{Name: "cache", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+	cs := fake.NewSimpleClientset(newTestNode("node-1"), scratch, newNodePod("bare", "node-1", ""))
+	evicted := withEvictionReactor(cs, nil)
+	withNodeClientset(t, cs)
+
+	w := httptest.NewRecorder()
+	NodeActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/nodes/node-1/drain", nil))
+	events := readDrainEvents(t, w.Body.String())
+	if len(*evicted) != 0 {
+		t.Errorf("no pod should be evicted, got %v", *evicted)
+	}
+	for _, pod := range []string{"scratch", "bare"} {
+		if got := eventTypesFor(events, pod); len(got) != 1 || got[0] != drainEventFailed {
+			t.Errorf("%s should be refused, got %v", pod, got)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	NodeActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/nodes/node-1/drain",
+		strings.NewReader(`{"deleteEmptyDirData":true,"force":true}`)))
+	events = readDrainEvents(t, w.Body.String())
+	if last := events[len(events)-1]; last.Type != drainEventCompleted || len(*evicted) != 2 {
+		t.Errorf("options should allow the drain, got %+v", events)
+	}
+}
+
+func TestNodeDrain_Cancel(t *testing.T) {
+	withFastDrain(t)
+	cs := fake.NewSimpleClientset(newTestNode("node-1"), newNodePod("guarded", "node-1", "ReplicaSet"))
+	withEvictionReactor(cs, map[string]int{"guarded": -1})
+	withNodeClientset(t, cs)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		w := httptest.NewRecorder()
+		NodeActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/nodes/node-1/drain", nil))
+		done <- w
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		activeDrainsMu.Lock()
+		_, running := activeDrains["node-1"]
+		activeDrainsMu.Unlock()
+		if running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("drain did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	w := httptest.NewRecorder()
+	NodeActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/nodes/node-1/drain", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("second drain: expected 409, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	NodeActionsHandler(w, httptest.NewRequest(http.MethodDelete, "/api/nodes/node-1/drain", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("cancel: expected 200, got %d", w.Code)
+	}
+
+	select {
+	case w = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("drain was not cancelled")
+	}
+	events := readDrainEvents(t, w.Body.String())
+	if last := events[len(events)-1]; last.Type != drainEventCancelled {
+		t.Errorf("expected cancelled event, got %+v", events)
+	}
+
+	w = httptest.NewRecorder()
+	NodeActionsHandler(w, httptest.NewRequest(http.MethodDelete, "/api/nodes/node-1/drain", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("cancel without drain: expected 404, got %d", w.Code)
+	}
+}
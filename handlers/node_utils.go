@@ -74,12 +74,20 @@ func fetchNodeMetrics(ctx context.Context, metricsClient *metricsv.Clientset) ma
 	if metricsClient == nil {
 		return nil
 	}
+	return fetchNodeMetricsFrom(ctx, metricsClient)
+}
+
+// fetchNodeMetricsFrom is fetchNodeMetrics for any metrics client implementation.
+func fetchNodeMetricsFrom(ctx context.Context, metricsClient metricsv.Interface) map[string]nodeMetricsUsage {
+	if metricsClient == nil {
+		return nil
+	}
 
 	nodeMetricsList, err := metricsClient.MetricsV1beta1().NodeMetricses().List(
 		ctx, metav1.ListOptions{},
 	)
 	if err != nil {
-		slog.Warn("metrics-server unavailable, falling back to capacity-allocatable", "error", err)
+		slog.Warn("metrics-server unavailable, usage will not be reported", "error", err)
 		return nil
 	}
 
@@ -106,41 +114,32 @@ func clamp(val, min, max float64) float64 {
 	return val
 }
 
-// getNodeResourceUsage resolves the raw CPU and memory usage for a single node.
-// Uses real metrics from metrics-server when available, falls back to capacity minus allocatable.
-func getNodeResourceUsage(node corev1.Node, metricsMap map[string]nodeMetricsUsage) nodeResourceUsage {
-	cpuCapacity := node.Status.Capacity[corev1.ResourceCPU]
-	memCapacity := node.Status.Capacity[corev1.ResourceMemory]
-
-	var cpuUsedMilli int64
-	var memUsedBytes int64
-
-	if usage, ok := metricsMap[node.Name]; metricsMap != nil && ok {
-		cpuUsedMilli = usage.cpuMillis
-		memUsedBytes = usage.memoryBytes
-	} else {
-		cpuAllocatable := node.Status.Allocatable[corev1.ResourceCPU]
-		memAllocatable := node.Status.Allocatable[corev1.ResourceMemory]
-		cpuUsed := cpuCapacity.DeepCopy()
-		cpuUsed.Sub(cpuAllocatable)
-		cpuUsedMilli = cpuUsed.MilliValue()
-		memUsed := memCapacity.DeepCopy()
-		memUsed.Sub(memAllocatable)
-		memUsedBytes = memUsed.Value()
+// getNodeResourceUsage resolves the raw CPU and memory usage for a single node from
+// metrics-server. It reports false when no metrics are available for the node;
+// capacity minus allocatable is reserved overhead, not usage, so there is no fallback.
+func getNodeResourceUsage(node corev1.Node, metricsMap map[string]nodeMetricsUsage) (nodeResourceUsage, bool) {
+	usage, ok := metricsMap[node.Name]
+	if !ok {
+		return nodeResourceUsage{}, false
 	}
 
+	cpuCapacity := node.Status.Capacity[corev1.ResourceCPU]
+	memCapacity := node.Status.Capacity[corev1.ResourceMemory]
 	return nodeResourceUsage{
-		cpuUsedMilli:     cpuUsedMilli,
+		cpuUsedMilli:     usage.cpuMillis,
 		cpuCapacityMilli: cpuCapacity.MilliValue(),
-		memUsedBytes:     memUsedBytes,
+		memUsedBytes:     usage.memoryBytes,
 		memCapacityBytes: memCapacity.Value(),
-	}
+	}, true
 }
 
 // calculateNodeResourceUsage calculates CPU and memory usage percentages for a single node.
-// Uses real metrics from metrics-server when available, falls back to capacity-allocatable.
+// Both are 0 when metrics-server has no data for the node.
 func calculateNodeResourceUsage(node corev1.Node, metricsMap map[string]nodeMetricsUsage) (float64, float64) {
-	usage := getNodeResourceUsage(node, metricsMap)
+	usage, ok := getNodeResourceUsage(node, metricsMap)
+	if !ok {
+		return 0, 0
+	}
 
 	var cpuPercent, memoryPercent float64
 	if usage.cpuCapacityMilli > 0 {
@@ -153,21 +152,36 @@ func calculateNodeResourceUsage(node corev1.Node, metricsMap map[string]nodeMetr
 	return clamp(cpuPercent, 0, 100), clamp(memoryPercent, 0, 100)
 }
 
-// buildNodeInfo constructs a NodeInfo from a Kubernetes node and its metrics.
-func buildNodeInfo(node corev1.Node, metricsMap map[string]nodeMetricsUsage) NodeInfo {
+// buildNodeInfo constructs a NodeInfo from a Kubernetes node, its metrics and the
+// pods scheduled to it.
+func buildNodeInfo(node corev1.Node, metricsMap map[string]nodeMetricsUsage, pods []corev1.Pod) NodeInfo {
 	cpuPercent, memoryPercent := calculateNodeResourceUsage(node, metricsMap)
+	_, metricsAvailable := metricsMap[node.Name]
 	return NodeInfo{
-		Name:          node.Name,
-		Status:        nodeStatusString(node),
-		Role:          getNodeRole(node),
-		CpuPercent:    cpuPercent,
-		MemoryPercent: memoryPercent,
+		Name:             node.Name,
+		Status:           nodeStatusString(node),
+		Role:             getNodeRole(node),
+		MetricsAvailable: metricsAvailable,
+		CpuPercent:       cpuPercent,
+		MemoryPercent:    memoryPercent,
+		Allocation:       calculateNodeAllocation(pods, node.Name, nodeResourcesFrom(node.Status.Allocatable)),
+	}
+}
+
+// groupPodsByNode indexes pods by the node they are scheduled to.
+func groupPodsByNode(pods []corev1.Pod) map[string][]corev1.Pod {
+	byNode := make(map[string][]corev1.Pod)
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" {
+			byNode[pod.Spec.NodeName] = append(byNode[pod.Spec.NodeName], pod)
+		}
 	}
+	return byNode
 }
 
-// calculateResourceUsage calculates average CPU and memory usage across all nodes,
-// weighted by each node's capacity. Uses real metrics from metrics-server when
-// available, falls back to capacity-allocatable.
+// calculateResourceUsage calculates average CPU and memory usage across the nodes
+// reported by metrics-server, weighted by each node's capacity. Nodes without
+// metrics are left out of both the usage and the capacity totals.
 func calculateResourceUsage(nodes []corev1.Node, metricsMap map[string]nodeMetricsUsage) (float64, float64) {
 	if len(nodes) == 0 {
 		return 0, 0
@@ -177,7 +191,10 @@ func calculateResourceUsage(nodes []corev1.Node, metricsMap map[string]nodeMetri
 	var totalMemUsedBytes, totalMemCapacityBytes int64
 
 	for _, node := range nodes {
-		usage := getNodeResourceUsage(node, metricsMap)
+		usage, ok := getNodeResourceUsage(node, metricsMap)
+		if !ok {
+			continue
+		}
 		totalCpuUsedMilli += usage.cpuUsedMilli
 		totalCpuCapacityMilli += usage.cpuCapacityMilli
 		totalMemUsedBytes += usage.memUsedBytes
@@ -194,3 +211,36 @@ func calculateResourceUsage(nodes []corev1.Node, metricsMap map[string]nodeMetri
 
 	return clamp(cpuPercent, 0, 100), clamp(memoryPercent, 0, 100)
 }
+
+// calculateClusterAllocation sums the requests and limits of the non-terminated
+// pods on every node and relates them to the cluster's total allocatable.
+func calculateClusterAllocation(nodes []corev1.Node, podsByNode map[string][]corev1.Pod) ClusterAllocation {
+	var cluster ClusterAllocation
+	for _, node := range nodes {
+		allocatable := nodeResourcesFrom(node.Status.Allocatable)
+		alloc := calculateNodeAllocation(podsByNode[node.Name], node.Name, allocatable)
+		cluster.CPUAllocatableMillis += allocatable.CPUMillis
+		cluster.MemoryAllocatableBytes += allocatable.MemoryBytes
+		cluster.CPURequestsMillis += alloc.CPURequestsMillis
+		cluster.CPULimitsMillis += alloc.CPULimitsMillis
+		cluster.MemoryRequestsBytes += alloc.MemoryRequestsBytes
+		cluster.MemoryLimitsBytes += alloc.MemoryLimitsBytes
+		cluster.PodCount += alloc.PodCount
+	}
+
+	ratio := func(v, total int64) float64 {
+		if total <= 0 {
+			return 0
+		}
+		return float64(v) / float64(total)
+	}
+	cluster.CPUCommittedRatio = ratio(cluster.CPURequestsMillis, cluster.CPUAllocatableMillis)
+	cluster.MemoryCommittedRatio = ratio(cluster.MemoryRequestsBytes, cluster.MemoryAllocatableBytes)
+	cluster.CPUOvercommitRatio = ratio(cluster.CPULimitsMillis, cluster.CPUAllocatableMillis)
+	cluster.MemoryOvercommitRatio = ratio(cluster.MemoryLimitsBytes, cluster.MemoryAllocatableBytes)
+	cluster.CPURequestsPercent = cluster.CPUCommittedRatio * 100
+	cluster.MemoryRequestsPercent = cluster.MemoryCommittedRatio * 100
+	cluster.CPULimitsPercent = cluster.CPUOvercommitRatio * 100
+	cluster.MemoryLimitsPercent = cluster.MemoryOvercommitRatio * 100
+	return cluster
+}
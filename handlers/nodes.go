@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -37,22 +39,49 @@ func getNodesData(ctx context.Context, clientset *kubernetes.Clientset, metricsC
 		return nil, err
 	}
 
-	nodePodCount := make(map[string]int)
-	for _, pod := range podList.Items {
-		if pod.Spec.NodeName != "" {
-			nodePodCount[pod.Spec.NodeName]++
-		}
-	}
+	podsByNode := groupPodsByNode(podList.Items)
 
 	metricsMap := fetchNodeMetrics(ctx, metricsClient)
 
 	nodesData := make([]NodeDetailInfo, 0, len(nodeList.Items))
 	for _, node := range nodeList.Items {
 		nodesData = append(nodesData, NodeDetailInfo{
-			NodeInfo: buildNodeInfo(node, metricsMap),
-			PodCount: nodePodCount[node.Name],
+			NodeInfo: buildNodeInfo(node, metricsMap, podsByNode[node.Name]),
+			PodCount: len(podsByNode[node.Name]),
 		})
 	}
 
 	return nodesData, nil
 }
+
+// NodeActionsHandler handles GET /api/nodes/{name} and the /api/nodes/{name}/{action} endpoints.
+func NodeActionsHandler(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, nodesPathPrefix)
+	for _, action := range []struct {
+		suffix  string
+		handler func(http.ResponseWriter, *http.Request, string)
+	}{
+		{debugPathSuffix, handleNodeDebug},
+		{historyPathSuffix, handleNodeHistory},
+		{cordonPathSuffix, handleNodeCordon},
+		{uncordonPathSuffix, handleNodeUncordon},
+		{drainPathSuffix, handleNodeDrain},
+	} {
+		if !strings.HasSuffix(rest, action.suffix) {
+			continue
+		}
+		name := strings.TrimSuffix(rest, action.suffix)
+		if name == "" || strings.Contains(name, "/") {
+			writeError(w, http.StatusBadRequest,
+				fmt.Sprintf("Invalid path format. Expected %s{name}%s", nodesPathPrefix, action.suffix))
+			return
+		}
+		action.handler(w, r, name)
+		return
+	}
+	if rest != "" && !strings.Contains(rest, "/") {
+		handleNodeDetail(w, r, rest)
+		return
+	}
+	writeError(w, http.StatusNotFound, "Unknown node action")
+}
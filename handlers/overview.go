@@ -23,22 +23,44 @@ type UnhealthyPodInfo struct {
 	Status    string `json:"status"`
 }
 
-// NodeInfo represents detailed information about a node
+// NodeInfo represents detailed information about a node.
+// CpuPercent and MemoryPercent are actual usage from metrics-server and are 0 when
+// MetricsAvailable is false; Allocation is what the scheduler has committed.
 type NodeInfo struct {
-	Name          string  `json:"name"`
-	Status        string  `json:"status"`
-	Role          string  `json:"role"`
-	CpuPercent    float64 `json:"cpuPercent"`
-	MemoryPercent float64 `json:"memoryPercent"`
+	Name             string         `json:"name"`
+	Status           string         `json:"status"`
+	Role             string         `json:"role"`
+	MetricsAvailable bool           `json:"metricsAvailable"`
+	CpuPercent       float64        `json:"cpuPercent"`
+	MemoryPercent    float64        `json:"memoryPercent"`
+	Allocation       NodeAllocation `json:"allocation"`
 }
 
-// OverviewResponse represents the overview data
+// ClusterAllocation is the sum of pod requests and limits across all nodes.
+// The committed ratios are requests over allocatable; the overcommit ratios are
+// limits over allocatable, so a value above 1 means limits exceed what the
+// cluster can provide.
+type ClusterAllocation struct {
+	NodeAllocation
+	CPUAllocatableMillis   int64   `json:"cpuAllocatableMillis"`
+	MemoryAllocatableBytes int64   `json:"memoryAllocatableBytes"`
+	CPUCommittedRatio      float64 `json:"cpuCommittedRatio"`
+	MemoryCommittedRatio   float64 `json:"memoryCommittedRatio"`
+	CPUOvercommitRatio     float64 `json:"cpuOvercommitRatio"`
+	MemoryOvercommitRatio  float64 `json:"memoryOvercommitRatio"`
+}
+
+// OverviewResponse represents the overview data.
+// AvgCpuPercent and AvgMemoryPercent are actual usage over the nodes reported by
+// metrics-server; Allocation is always cluster-wide, even when filtered by namespace.
 type OverviewResponse struct {
 	Nodes             NodesResponse      `json:"nodes"`
 	UnhealthyPods     int                `json:"unhealthyPods"`
 	UnhealthyPodsList []UnhealthyPodInfo `json:"unhealthyPodsList,omitempty"`
+	MetricsAvailable  bool               `json:"metricsAvailable"`
 	AvgCpuPercent     float64            `json:"avgCpuPercent"`
 	AvgMemoryPercent  float64            `json:"avgMemoryPercent"`
+	Allocation        ClusterAllocation  `json:"allocation"`
 	NodesList         []NodeInfo         `json:"nodesList,omitempty"`
 }
 
@@ -87,9 +109,19 @@ func getOverviewData(ctx context.Context, clientset *kubernetes.Clientset, metri
 		}
 	}
 
+	allPods := podList.Items
+	if namespace != "" {
+		clusterPods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		allPods = clusterPods.Items
+	}
+	podsByNode := groupPodsByNode(allPods)
+
 	metricsMap := fetchNodeMetrics(ctx, metricsClient)
 	avgCpu, avgMemory := calculateResourceUsage(nodeList.Items, metricsMap)
-	nodesList := buildNodesList(nodeList.Items, metricsMap)
+	nodesList := buildNodesList(nodeList.Items, metricsMap, podsByNode)
 
 	return &OverviewResponse{
 		Nodes: NodesResponse{
@@ -98,17 +130,19 @@ func getOverviewData(ctx context.Context, clientset *kubernetes.Clientset, metri
 		},
 		UnhealthyPods:     unhealthyPods,
 		UnhealthyPodsList: unhealthyPodsList,
+		MetricsAvailable:  len(metricsMap) > 0,
 		AvgCpuPercent:     avgCpu,
 		AvgMemoryPercent:  avgMemory,
+		Allocation:        calculateClusterAllocation(nodeList.Items, podsByNode),
 		NodesList:         nodesList,
 	}, nil
 }
 
 // buildNodesList creates a list of NodeInfo from Kubernetes nodes.
-func buildNodesList(nodes []corev1.Node, metricsMap map[string]nodeMetricsUsage) []NodeInfo {
+func buildNodesList(nodes []corev1.Node, metricsMap map[string]nodeMetricsUsage, podsByNode map[string][]corev1.Pod) []NodeInfo {
 	nodesList := make([]NodeInfo, 0, len(nodes))
 	for _, node := range nodes {
-		nodesList = append(nodesList, buildNodeInfo(node, metricsMap))
+		nodesList = append(nodesList, buildNodeInfo(node, metricsMap, podsByNode[node.Name]))
 	}
 	return nodesList
 }
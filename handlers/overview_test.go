@@ -444,8 +444,8 @@ func TestOverviewHandlerResponseFormat(t *testing.T) {
 	})
 }
 
-// TestCalculateResourceUsageFallback tests metric calculation with and without metrics-server data
-func TestCalculateResourceUsageFallback(t *testing.T) {
+// TestCalculateResourceUsage tests metric calculation with and without metrics-server data
+func TestCalculateResourceUsage(t *testing.T) {
 	// Helper to create a test node with given capacity and allocatable
 	makeNode := func(name string, cpuCapacity, cpuAllocatable, memCapacity, memAllocatable string) corev1.Node {
 		return corev1.Node{
@@ -470,17 +470,13 @@ func TestCalculateResourceUsageFallback(t *testing.T) {
 		}
 	})
 
-	t.Run("should fall back to capacity-allocatable when metricsMap is nil", func(t *testing.T) {
+	t.Run("should not report reserved overhead as usage when metricsMap is nil", func(t *testing.T) {
 		nodes := []corev1.Node{
 			makeNode("node-1", "4000m", "3800m", "8Gi", "7Gi"),
 		}
 		cpu, mem := calculateResourceUsage(nodes, nil)
-		// capacity - allocatable: 200m / 4000m = 5%, 1Gi / 8Gi = 12.5%
-		if cpu < 4.9 || cpu > 5.1 {
-			t.Errorf("expected ~5%% CPU fallback, got %f", cpu)
-		}
-		if mem < 12.0 || mem > 13.0 {
-			t.Errorf("expected ~12.5%% memory fallback, got %f", mem)
+		if cpu != 0 || mem != 0 {
+			t.Errorf("expected 0/0 without metrics, got %f/%f", cpu, mem)
 		}
 	})
 
@@ -503,12 +499,12 @@ func TestCalculateResourceUsageFallback(t *testing.T) {
 		}
 	})
 
-	t.Run("should fall back for nodes not in metricsMap", func(t *testing.T) {
+	t.Run("should leave nodes without metrics out of the average", func(t *testing.T) {
 		nodes := []corev1.Node{
 			makeNode("node-1", "4000m", "4000m", "8Gi", "8Gi"),
 			makeNode("node-2", "4000m", "3800m", "8Gi", "7Gi"),
 		}
-		// Only node-1 has metrics; node-2 should fall back
+		// Only node-1 has metrics; node-2 must not dilute or inflate the average
 		metricsMap := map[string]nodeMetricsUsage{
 			"node-1": {
 				cpuMillis:   2000,
@@ -516,13 +512,11 @@ func TestCalculateResourceUsageFallback(t *testing.T) {
 			},
 		}
 		cpu, mem := calculateResourceUsage(nodes, metricsMap)
-		// node-1: 2000m/4000m, node-2 fallback: 200m/4000m
-		// total: 2200m/8000m = 27.5%
-		if cpu < 27.0 || cpu > 28.0 {
-			t.Errorf("expected ~27.5%% CPU mixed mode, got %f", cpu)
+		if cpu < 49.9 || cpu > 50.1 {
+			t.Errorf("expected ~50%% CPU from node-1 only, got %f", cpu)
 		}
-		if mem < 1 {
-			t.Errorf("expected positive memory mixed mode, got %f", mem)
+		if mem < 49.9 || mem > 50.1 {
+			t.Errorf("expected ~50%% memory from node-1 only, got %f", mem)
 		}
 	})
 
@@ -546,8 +540,8 @@ func TestCalculateResourceUsageFallback(t *testing.T) {
 	})
 }
 
-// TestCalculateNodeResourceUsageFallback tests per-node metric calculation
-func TestCalculateNodeResourceUsageFallback(t *testing.T) {
+// TestCalculateNodeResourceUsage tests per-node metric calculation
+func TestCalculateNodeResourceUsage(t *testing.T) {
 	node := corev1.Node{
 		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
 		Status: corev1.NodeStatus{
@@ -562,10 +556,10 @@ func TestCalculateNodeResourceUsageFallback(t *testing.T) {
 		},
 	}
 
-	t.Run("should return 0 when no metrics and capacity equals allocatable", func(t *testing.T) {
+	t.Run("should return 0 when no metrics are available", func(t *testing.T) {
 		cpu, mem := calculateNodeResourceUsage(node, nil)
 		if cpu != 0 || mem != 0 {
-			t.Errorf("expected 0/0 with nil metrics and equal capacity/allocatable, got %f/%f", cpu, mem)
+			t.Errorf("expected 0/0 with nil metrics, got %f/%f", cpu, mem)
 		}
 	})
 
@@ -585,3 +579,30 @@ func TestCalculateNodeResourceUsageFallback(t *testing.T) {
 		}
 	})
 }
+
+// TestCalculateClusterAllocation tests that allocation comes from pod requests and limits, not usage
+func TestCalculateClusterAllocation(t *testing.T) {
+	nodes := []corev1.Node{*newDetailedNode()}
+	nodes[0].Status.Allocatable[corev1.ResourceCPU] = resource.MustParse("1")
+	web := newPodWithResources("default", "web", resourceContainer("app", "500m", "2", "1Gi", "2Gi"))
+	web.Spec.NodeName = "node-1"
+	unscheduled := newPodWithResources("default", "pending", resourceContainer("app", "1", "", "", ""))
+	unscheduled.Spec.NodeName = ""
+
+	podsByNode := groupPodsByNode([]corev1.Pod{*web, *unscheduled})
+	alloc := calculateClusterAllocation(nodes, podsByNode)
+	if alloc.PodCount != 1 || alloc.CPURequestsMillis != 500 || alloc.CPUAllocatableMillis != 1000 {
+		t.Errorf("unexpected sums %+v", alloc)
+	}
+	if alloc.CPUCommittedRatio != 0.5 || alloc.CPUOvercommitRatio != 2 || alloc.MemoryOvercommitRatio != 0.5 {
+		t.Errorf("unexpected ratios %+v", alloc)
+	}
+
+	info := buildNodeInfo(nodes[0], nil, podsByNode["node-1"])
+	if info.MetricsAvailable || info.CpuPercent != 0 {
+		t.Errorf("usage must not be reported without metrics: %+v", info)
+	}
+	if info.Allocation.CPURequestsPercent != 50 {
+		t.Errorf("node allocation = %+v", info.Allocation)
+	}
+}
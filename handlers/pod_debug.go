@@ -27,14 +27,46 @@ type debugPodRequest struct {
 	// AllowSysAdmin grants CAP_SYS_ADMIN so the debug shell can perform
 	// privileged administrative operations (e.g. mount, setns).
 	AllowSysAdmin bool `json:"allowSysAdmin,omitempty"`
+	// Profile names a debug profile (see debug_profiles.go). Empty selects the
+	// server's default profile.
+	Profile string `json:"profile,omitempty"`
+	// Command overrides the image entrypoint. When both Command and Args are
+	// empty the container runs /bin/sh.
+	Command    []string      `json:"command,omitempty"`
+	Args       []string      `json:"args,omitempty"`
+	Env        []debugEnvVar `json:"env,omitempty"`
+	WorkingDir string        `json:"workingDir,omitempty"`
+	// Mode selects how the debug container is attached: "ephemeral" (default)
+	// injects it into the running pod, "copy" creates a modified copy of the pod.
+	Mode string `json:"mode,omitempty"`
+	// CopyCommand replaces the target container's command in copy mode.
+	// Defaults to sleep infinity so a crash-looping container stays up.
+	CopyCommand []string `json:"copyCommand,omitempty"`
+	// CopyImage optionally replaces the target container's image in copy mode,
+	// e.g. to swap a distroless image for a debug variant.
+	CopyImage string `json:"copyImage,omitempty"`
+}
+
+// debugEnvVar is a literal environment variable for the debug container.
+type debugEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
 // debugPodResponse is returned to the client when an ephemeral container has been created.
 type debugPodResponse struct {
 	Container string `json:"container"`
 	Ready     bool   `json:"ready"`
+	// Pod is the name of the debug copy in copy mode; exec into Container there.
+	Pod string `json:"pod,omitempty"`
 }
 
+// Debug modes accepted in debugPodRequest.Mode.
+const (
+	debugModeEphemeral = "ephemeral"
+	debugModeCopy      = "copy"
+)
+
 // getDebugClientset returns the Kubernetes client used by PodDebugHandler.
 // Tests may override this to inject a fake clientset.
 var getDebugClientset func() (kubernetes.Interface, error) = func() (kubernetes.Interface, error) {
@@ -73,8 +105,9 @@ func PodDebugHandler(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, errMsgPodDebugInvalidReq)
 		return
 	}
-	if req.Image == "" {
-		writeError(w, http.StatusBadRequest, "Image is required")
+
+	profile, ok := resolveDebugProfile(w, &req)
+	if !ok {
 		return
 	}
 
@@ -90,7 +123,12 @@ func PodDebugHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := addEphemeralContainer(r.Context(), clientset, namespace, name, containerName, req); err != nil {
+	if req.Mode == debugModeCopy {
+		handleDebugCopy(w, r, clientset, namespace, name, containerName, req, profile)
+		return
+	}
+
+	if err := addEphemeralContainer(r.Context(), clientset, namespace, name, containerName, req, profile); err != nil {
 		var dErr *debugError
 		if errors.As(err, &dErr) {
 			writeError(w, dErr.status, dErr.message)
@@ -124,6 +162,90 @@ type debugError struct {
 
 func (e *debugError) Error() string { return e.message }
 
+// resolveDebugProfile looks up the requested profile, fills in its default image
+// and enforces the image allowlist and request validation. It writes an error
+// response and returns false when the request must be rejected.
+func resolveDebugProfile(w http.ResponseWriter, req *debugPodRequest) (debugProfile, bool) {
+	profiles, err := getDebugProfiles()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, errMsgDebugProfilesLoad)
+		return debugProfile{}, false
+	}
+
+	profile, ok := profiles.lookup(req.Profile)
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown debug profile %q", req.Profile))
+		return debugProfile{}, false
+	}
+
+	if req.Image == "" {
+		req.Image = profile.DefaultImage
+	}
+	if req.Image == "" {
+		writeError(w, http.StatusBadRequest, "Image is required")
+		return debugProfile{}, false
+	}
+	if !profiles.imageAllowed(profile, req.Image) {
+		writeError(w, http.StatusForbidden,
+			fmt.Sprintf("%s: %q is not allowed for profile %q", errMsgPodDebugImageDenied, req.Image, profile.Name))
+		return debugProfile{}, false
+	}
+	if profile.NoEscalation && (req.AllowPtrace || req.AllowSysAdmin) {
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("Profile %q does not allow additional capabilities", profile.Name))
+		return debugProfile{}, false
+	}
+	if req.Mode != "" && req.Mode != debugModeEphemeral && req.Mode != debugModeCopy {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown debug mode %q", req.Mode))
+		return debugProfile{}, false
+	}
+	if req.CopyImage != "" && !profiles.imageAllowed(profile, req.CopyImage) {
+		writeError(w, http.StatusForbidden,
+			fmt.Sprintf("%s: %q is not allowed for profile %q", errMsgPodDebugImageDenied, req.CopyImage, profile.Name))
+		return debugProfile{}, false
+	}
+	for _, env := range req.Env {
+		if env.Name == "" || strings.ContainsAny(env.Name, "= ") {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid environment variable name %q", env.Name))
+			return debugProfile{}, false
+		}
+	}
+
+	return profile, true
+}
+
+// buildDebugContainer assembles the debug container from the request and profile.
+// The result converts directly to a corev1.Container for pod-copy debugging.
+func buildDebugContainer(containerName string, req debugPodRequest, profile debugProfile) corev1.EphemeralContainerCommon {
+	c := corev1.EphemeralContainerCommon{
+		Name:                     containerName,
+		Image:                    req.Image,
+		Command:                  req.Command,
+		Args:                     req.Args,
+		WorkingDir:               req.WorkingDir,
+		ImagePullPolicy:          corev1.PullIfNotPresent,
+		Stdin:                    true,
+		TTY:                      true,
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+	}
+	if len(c.Command) == 0 && len(c.Args) == 0 {
+		c.Command = []string{"/bin/sh"}
+	}
+	for _, env := range req.Env {
+		c.Env = append(c.Env, corev1.EnvVar{Name: env.Name, Value: env.Value})
+	}
+
+	var caps []corev1.Capability
+	if req.AllowPtrace {
+		caps = append(caps, "SYS_PTRACE")
+	}
+	if req.AllowSysAdmin {
+		caps = append(caps, "SYS_ADMIN")
+	}
+	c.SecurityContext = profile.securityContext(caps)
+	return c
+}
+
 // addEphemeralContainer appends an EphemeralContainer to the pod spec and calls
 // UpdateEphemeralContainers. On resourceVersion conflict it retries once.
 func addEphemeralContainer(
@@ -131,6 +253,7 @@ func addEphemeralContainer(
 	clientset kubernetes.Interface,
 	namespace, podName, containerName string,
 	req debugPodRequest,
+	profile debugProfile,
 ) error {
 	const maxAttempts = 2
 	for attempt := 0; attempt < maxAttempts; attempt++ {
@@ -144,30 +267,8 @@ func addEphemeralContainer(
 		}
 
 		ec := corev1.EphemeralContainer{
-			EphemeralContainerCommon: corev1.EphemeralContainerCommon{
-				Name:                     containerName,
-				Image:                    req.Image,
-				Command:                  []string{"/bin/sh"},
-				ImagePullPolicy:          corev1.PullIfNotPresent,
-				Stdin:                    true,
-				TTY:                      true,
-				TerminationMessagePolicy: corev1.TerminationMessageReadFile,
-			},
-		}
-		if req.TargetContainer != "" {
-			ec.TargetContainerName = req.TargetContainer
-		}
-		var caps []corev1.Capability
-		if req.AllowPtrace {
-			caps = append(caps, "SYS_PTRACE")
-		}
-		if req.AllowSysAdmin {
-			caps = append(caps, "SYS_ADMIN")
-		}
-		if len(caps) > 0 {
-			ec.SecurityContext = &corev1.SecurityContext{
-				Capabilities: &corev1.Capabilities{Add: caps},
-			}
+			EphemeralContainerCommon: buildDebugContainer(containerName, req, profile),
+			TargetContainerName:      req.TargetContainer,
 		}
 
 		pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, ec)
@@ -217,6 +318,21 @@ func waitForEphemeralContainerReady(
 	ctx context.Context,
 	clientset kubernetes.Interface,
 	namespace, podName, containerName string,
+) (bool, error) {
+	return waitForContainerRunning(ctx, clientset, namespace, podName, containerName,
+		func(pod *corev1.Pod) []corev1.ContainerStatus { return pod.Status.EphemeralContainerStatuses },
+		errMsgPodDebugFailed, errMsgPodDebugNotReady)
+}
+
+// waitForContainerRunning implements waitForEphemeralContainerReady for any set
+// of container statuses selected by statuses. failMsg prefixes termination errors
+// and notReadyMsg is reported on timeout.
+func waitForContainerRunning(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	namespace, podName, containerName string,
+	statuses func(*corev1.Pod) []corev1.ContainerStatus,
+	failMsg, notReadyMsg string,
 ) (bool, error) {
 	deadline := time.Now().Add(debugReadyTimeout)
 	for {
@@ -225,7 +341,7 @@ func waitForEphemeralContainerReady(
 			return false, err
 		}
 
-		for _, cs := range pod.Status.EphemeralContainerStatuses {
+		for _, cs := range statuses(pod) {
 			if cs.Name != containerName {
 				continue
 			}
@@ -235,7 +351,7 @@ func waitForEphemeralContainerReady(
 			if cs.State.Terminated != nil {
 				return false, &debugError{
 					status:  http.StatusInternalServerError,
-					message: fmt.Sprintf("%s: %s", errMsgPodDebugFailed, cs.State.Terminated.Reason),
+					message: fmt.Sprintf("%s: %s", failMsg, cs.State.Terminated.Reason),
 				}
 			}
 			if cs.State.Waiting != nil && isFatalWaitingReason(cs.State.Waiting.Reason) {
@@ -249,7 +365,7 @@ func waitForEphemeralContainerReady(
 		}
 
 		if time.Now().After(deadline) {
-			return false, &debugError{status: http.StatusGatewayTimeout, message: errMsgPodDebugNotReady}
+			return false, &debugError{status: http.StatusGatewayTimeout, message: notReadyMsg}
 		}
 
 		select {
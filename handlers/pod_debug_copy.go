@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+)
+
+// defaultCopyCommand keeps the target container alive in a debug copy so a
+// crash-looping process can be started by hand from the debug shell.
+var defaultCopyCommand = []string{"sleep", "infinity"}
+
+// maxPodCopyNameLength keeps generated copy names usable as hostnames.
+const maxPodCopyNameLength = 63
+
+// debugCopySuffix returns the random part of a debug copy's name, the same kind
+// of suffix the API server appends for generateName. Tests may override this.
+var debugCopySuffix = func() string {
+	return utilrand.String(5)
+}
+
+// DebugCopyInfo describes a pod created by debug copy mode.
+type DebugCopyInfo struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Source    string `json:"source"`
+	Status    string `json:"status"`
+	Node      string `json:"node"`
+	Age       string `json:"age"`
+}
+
+// handleDebugCopy creates a debug copy of the pod and waits for its debug container to run.
+// The copy is deleted again if it never becomes ready, so failed attempts leave nothing behind.
+func handleDebugCopy(
+	w http.ResponseWriter, r *http.Request,
+	clientset kubernetes.Interface,
+	namespace, podName, containerName string,
+	req debugPodRequest,
+	profile debugProfile,
+) {
+	copyPod, err := createDebugCopy(r.Context(), clientset, namespace, podName, containerName, req, profile)
+	if err != nil {
+		var dErr *debugError
+		if errors.As(err, &dErr) {
+			writeError(w, dErr.status, dErr.message)
+			return
+		}
+		slog.Error("Failed to create debug copy", "error", err, "namespace", namespace, "pod", podName)
+		writeResourceError(w, err, errMsgPodNotFound, errMsgPodDebugCopyFailed)
+		return
+	}
+
+	ready, err := waitForContainerRunning(r.Context(), clientset, namespace, copyPod.Name, containerName,
+		func(pod *corev1.Pod) []corev1.ContainerStatus { return pod.Status.ContainerStatuses },
+		errMsgPodDebugCopyFailed, errMsgPodDebugCopyNotReady)
+	if err != nil {
+		// Use a fresh context: the request context may be the reason we failed.
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+		defer cancel()
+		if delErr := deletePod(cleanupCtx, clientset, namespace, copyPod.Name); delErr != nil && !k8serrors.IsNotFound(delErr) {
+			slog.Error("Failed to clean up debug copy", "error", delErr, "namespace", namespace, "pod", copyPod.Name)
+		}
+
+		var dErr *debugError
+		if errors.As(err, &dErr) {
+			writeError(w, dErr.status, dErr.message)
+			return
+		}
+		slog.Error("Failed to wait for debug copy", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgPodDebugCopyFailed)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, debugPodResponse{Container: containerName, Ready: ready, Pod: copyPod.Name})
+}
+
+// createDebugCopy creates a copy of the source pod in the spirit of `kubectl debug --copy-to`:
+// the target container's command (and optionally image) is replaced, probes are removed so
+// the kubelet does not restart it, and the debug container is added with a shared process
+// namespace. Labels and owner references are dropped so Services and controllers ignore the copy.
+func createDebugCopy(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	namespace, podName, containerName string,
+	req debugPodRequest,
+	profile debugProfile,
+) (*corev1.Pod, error) {
+	source, err := clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if nameCollides(source, containerName) {
+		return nil, &debugError{status: http.StatusConflict, message: errMsgPodDebugNameTaken}
+	}
+
+	spec := source.Spec.DeepCopy()
+	target := req.TargetContainer
+	if target == "" && len(spec.Containers) > 0 {
+		target = spec.Containers[0].Name
+	}
+
+	found := false
+	for i := range spec.Containers {
+		c := &spec.Containers[i]
+		c.LivenessProbe = nil
+		c.ReadinessProbe = nil
+		c.StartupProbe = nil
+		if c.Name != target {
+			continue
+		}
+		found = true
+		c.Command = defaultCopyCommand
+		if len(req.CopyCommand) > 0 {
+			c.Command = req.CopyCommand
+		}
+		c.Args = nil
+		if req.CopyImage != "" {
+			c.Image = req.CopyImage
+		}
+	}
+	if !found {
+		return nil, &debugError{
+			status:  http.StatusBadRequest,
+			message: fmt.Sprintf("Container %q not found in pod %s/%s", target, namespace, podName),
+		}
+	}
+
+	shareProcessNamespace := true
+	spec.ShareProcessNamespace = &shareProcessNamespace
+	spec.NodeName = ""
+	spec.EphemeralContainers = nil
+	spec.Containers = append(spec.Containers, corev1.Container(buildDebugContainer(containerName, req, profile)))
+
+	copyPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        debugCopyName(podName, debugCopySuffix()),
+			Namespace:   namespace,
+			Labels:      map[string]string{labelDebugCopy: "true"},
+			Annotations: map[string]string{annotationDebugCopyOf: podName},
+		},
+		Spec: *spec,
+	}
+
+	created, err := clientset.CoreV1().Pods(namespace).Create(ctx, copyPod, metav1.CreateOptions{})
+	if err != nil {
+		if k8serrors.IsForbidden(err) {
+			return nil, &debugError{status: http.StatusForbidden, message: fmt.Sprintf("%s: %s", errMsgPodDebugCopyFailed, err.Error())}
+		}
+		if k8serrors.IsInvalid(err) {
+			return nil, &debugError{status: http.StatusBadRequest, message: fmt.Sprintf("%s: %s", errMsgPodDebugCopyFailed, err.Error())}
+		}
+		if k8serrors.IsAlreadyExists(err) {
+			return nil, &debugError{status: http.StatusConflict, message: fmt.Sprintf("%s: %s", errMsgPodDebugCopyFailed, err.Error())}
+		}
+		return nil, err
+	}
+	return created, nil
+}
+
+// debugCopyName returns "<pod>-debug-<suffix>", truncating the pod name to keep it a valid hostname.
+func debugCopyName(podName, suffix string) string {
+	suffix = "-debug-" + suffix
+	if len(podName)+len(suffix) > maxPodCopyNameLength {
+		podName = strings.TrimRight(podName[:maxPodCopyNameLength-len(suffix)], "-.")
+	}
+	return podName + suffix
+}
+
+// DebugCopiesHandler handles GET /api/pods/debug-copies?ns=... to list debug copies.
+var DebugCopiesHandler = handleGet(errMsgPodDebugCopyList, func(r *http.Request) (interface{}, error) {
+	clientset, err := getDebugClientset()
+	if err != nil {
+		return nil, err
+	}
+	return listDebugCopies(r.Context(), clientset, r.URL.Query().Get("ns"))
+})
+
+// DebugCopyDeleteHandler handles DELETE /api/pods/debug-copies/{namespace}/{name}.
+// Only pods carrying the debug-copy label can be deleted through this endpoint.
+func DebugCopyDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodDelete) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, err := parseResourcePath(r.URL.Path, debugCopiesPathPrefix, "")
+	if err != nil {
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("Invalid path format. Expected %s{namespace}/{name}", debugCopiesPathPrefix))
+		return
+	}
+
+	clientset, err := getDebugClientset()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(r.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		writeResourceError(w, err, errMsgPodDebugCopyNotFound, errMsgPodDebugCopyDelete)
+		return
+	}
+	if _, ok := pod.Labels[labelDebugCopy]; !ok {
+		writeError(w, http.StatusNotFound, errMsgPodDebugCopyNotFound)
+		return
+	}
+
+	if err := deletePod(r.Context(), clientset, namespace, name); err != nil {
+		writeResourceError(w, err, errMsgPodDebugCopyNotFound, errMsgPodDebugCopyDelete)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Debug copy deleted successfully"})
+}
+
+// listDebugCopies returns all pods created by debug copy mode in the namespace (all namespaces when empty).
+func listDebugCopies(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]DebugCopyInfo, error) {
+	podList, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelDebugCopy})
+	if err != nil {
+		return nil, err
+	}
+
+	copies := make([]DebugCopyInfo, 0, len(podList.Items))
+	for _, pod := range podList.Items {
+		node := pod.Spec.NodeName
+		if node == "" {
+			node = podNodePending
+		}
+		copies = append(copies, DebugCopyInfo{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			Source:    pod.Annotations[annotationDebugCopyOf],
+			Status:    getPodStatus(pod),
+			Node:      node,
+			Age:       formatPodAge(pod.CreationTimestamp.Time),
+		})
+	}
+	return copies, nil
+}
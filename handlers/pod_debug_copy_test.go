@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// withFixedNow pins nowFunc so generated names are predictable.
+func withFixedNow(t *testing.T, now time.Time) {
+	t.Helper()
+	old := nowFunc
+	nowFunc = func() time.Time { return now }
+	t.Cleanup(func() { nowFunc = old })
+}
+
+// withDebugCopySuffix pins debugCopySuffix so generated copy names are predictable.
+func withDebugCopySuffix(t *testing.T, suffix string) {
+	t.Helper()
+	old := debugCopySuffix
+	debugCopySuffix = func() string { return suffix }
+	t.Cleanup(func() { debugCopySuffix = old })
+}
+
+// setContainerRunning marks a regular container of the pod as Running in the tracker.
+func setContainerRunning(t *testing.T, cs *fake.Clientset, namespace, name, containerName string) {
+	t.Helper()
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	for i := 0; i < 100; i++ {
+		obj, err := cs.Tracker().Get(gvr, namespace, name)
+		if err != nil {
+			time.Sleep(2 * time.Millisecond)
+			continue
+		}
+		pod := obj.(*corev1.Pod)
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  containerName,
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.Now()}},
+		}}
+		if err := cs.Tracker().Update(gvr, pod, namespace); err != nil {
+			t.Errorf("tracker update: %v", err)
+		}
+		return
+	}
+	t.Errorf("pod %s/%s was never created", namespace, name)
+}
+
+func newSourcePod() *corev1.Pod {
+	pod := newRunningPod("default", "my-pod", "app", "sidecar")
+	pod.Labels = map[string]string{"app": "web"}
+	pod.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-abc"}}
+	probe := &corev1.Probe{ProbeHandler: corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"true"}}}}
+	for i := range pod.Spec.Containers {
+		pod.Spec.Containers[i].Image = "web:1.0"
+		pod.Spec.Containers[i].Command = []string{"/app"}
+		pod.Spec.Containers[i].Args = []string{"--serve"}
+		pod.Spec.Containers[i].LivenessProbe = probe
+		pod.Spec.Containers[i].ReadinessProbe = probe
+	}
+	return pod
+}
+
+func TestPodDebugHandler_CopyMode(t *testing.T) {
+	withAnyDebugImage(t)
+	cs := fake.NewSimpleClientset(newSourcePod())
+	withDebugClientset(t, cs)
+	withFastPolling(t, time.Second, 5*time.Millisecond)
+	withDebugCopySuffix(t, "x7k2q")
+	copyName := "my-pod-debug-x7k2q"
+
+	go setContainerRunning(t, cs, "default", copyName, "dbg")
+
+	w := httptest.NewRecorder()
+	PodDebugHandler(w, newDebugRequest(t, "default", "my-pod", debugPodRequest{
+		Image:     "busybox:1.36",
+		Name:      "dbg",
+		Mode:      debugModeCopy,
+		CopyImage: "web:1.0-debug",
+	}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp debugPodResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Pod != copyName || resp.Container != "dbg" || !resp.Ready {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	copyPod, err := cs.CoreV1().Pods("default").Get(context.Background(), copyName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copyPod.Labels[labelDebugCopy] != "true" || copyPod.Labels["app"] != "" || len(copyPod.OwnerReferences) != 0 {
+		t.Errorf("copy should only carry the debug-copy label, got %v / %v", copyPod.Labels, copyPod.OwnerReferences)
+	}
+	if copyPod.Annotations[annotationDebugCopyOf] != "my-pod" {
+		t.Errorf("source annotation = %q", copyPod.Annotations[annotationDebugCopyOf])
+	}
+	if sp := copyPod.Spec.ShareProcessNamespace; sp == nil || !*sp {
+		t.Error("expected shared process namespace")
+	}
+	if copyPod.Spec.NodeName != "" {
+		t.Errorf("copy should be rescheduled, got node %q", copyPod.Spec.NodeName)
+	}
+	containers := copyPod.Spec.Containers
+	if len(containers) != 3 || containers[2].Name != "dbg" || containers[2].Image != "busybox:1.36" {
+		t.Fatalf("expected debug container appended, got %+v", containers)
+	}
+	app, sidecar := containers[0], containers[1]
+	if strings.Join(app.Command, " ") != "sleep infinity" || app.Args != nil || app.Image != "web:1.0-debug" {
+		t.Errorf("target container not rewritten: %v %v %q", app.Command, app.Args, app.Image)
+	}
+	if sidecar.Image != "web:1.0" || strings.Join(sidecar.Command, " ") != "/app" {
+		t.Errorf("non-target container should be untouched: %+v", sidecar)
+	}
+	for _, c := range containers {
+		if c.LivenessProbe != nil || c.ReadinessProbe != nil {
+			t.Errorf("probes not removed from %s", c.Name)
+		}
+	}
+}
+
+func TestPodDebugHandler_CopyModeCleansUpOnTimeout(t *testing.T) {
+	cs := fake.NewSimpleClientset(newSourcePod())
+	withDebugClientset(t, cs)
+	withFastPolling(t, 30*time.Millisecond, 5*time.Millisecond)
+
+	w := httptest.NewRecorder()
+	PodDebugHandler(w, newDebugRequest(t, "default", "my-pod", debugPodRequest{
+		Image: "busybox:1.36",
+		Name:  "dbg",
+		Mode:  debugModeCopy,
+	}))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d: %s", w.Code, w.Body.String())
+	}
+	pods, _ := cs.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+	if len(pods.Items) != 1 {
+		t.Errorf("expected copy to be deleted, %d pods remain", len(pods.Items))
+	}
+}
+
+func TestPodDebugHandler_CopyModeUnknownTarget(t *testing.T) {
+	withDebugClientset(t, fake.NewSimpleClientset(newSourcePod()))
+
+	w := httptest.NewRecorder()
+	PodDebugHandler(w, newDebugRequest(t, "default", "my-pod", debugPodRequest{
+		Image:           "busybox:1.36",
+		Mode:            debugModeCopy,
+		TargetContainer: "missing",
+	}))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDebugCopyName(t *testing.T) {
+	if got := debugCopyName("web", "x7k2q"); got != "web-debug-x7k2q" {
+		t.Errorf("got %q", got)
+	}
+	long := strings.Repeat("a", 80)
+	if got := debugCopyName(long, "x7k2q"); len(got) != maxPodCopyNameLength || !strings.HasSuffix(got, "-debug-x7k2q") {
+		t.Errorf("expected truncated name, got %q", got)
+	}
+	dotted := strings.Repeat("a", 49) + ".-b" + strings.Repeat("c", 20)
+	if got := debugCopyName(dotted, "x7k2q"); got != strings.Repeat("a", 49)+"-debug-x7k2q" {
+		t.Errorf("expected trailing separators trimmed, got %q", got)
+	}
+	if a, b := debugCopySuffix(), debugCopySuffix(); a == b || len(a) != 5 {
+		t.Errorf("suffixes should be random, got %q and %q", a, b)
+	}
+}
+
+func TestPodDebugHandler_CopyModeNameTaken(t *testing.T) {
+	withAnyDebugImage(t)
+	withDebugCopySuffix(t, "x7k2q")
+	withDebugClientset(t, fake.NewSimpleClientset(newSourcePod(), newRunningPod("default", "my-pod-debug-x7k2q")))
+
+	w := httptest.NewRecorder()
+	PodDebugHandler(w, newDebugRequest(t, "default", "my-pod", debugPodRequest{
+		Image: "busybox:1.36",
+		Name:  "dbg",
+		Mode:  debugModeCopy,
+	}))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDebugCopies_ListAndDelete(t *testing.T) {
+	copyPod := newRunningPod("default", "my-pod-debug-1")
+	copyPod.Labels = map[string]string{labelDebugCopy: "true"}
+	copyPod.Annotations = map[string]string{annotationDebugCopyOf: "my-pod"}
+	cs := fake.NewSimpleClientset(newRunningPod("default", "my-pod"), copyPod)
+	withDebugClientset(t, cs)
+
+	w := httptest.NewRecorder()
+	DebugCopiesHandler(w, httptest.NewRequest(http.MethodGet, "/api/pods/debug-copies?ns=default", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: expected 200, got %d", w.Code)
+	}
+	var copies []DebugCopyInfo
+	if err := json.NewDecoder(w.Body).Decode(&copies); err != nil {
+		t.Fatal(err)
+	}
+	if len(copies) != 1 || copies[0].Name != "my-pod-debug-1" || copies[0].Source != "my-pod" {
+		t.Errorf("unexpected copies %+v", copies)
+	}
+
+	w = httptest.NewRecorder()
+	DebugCopyDeleteHandler(w, httptest.NewRequest(http.MethodDelete, "/api/pods/debug-copies/default/my-pod", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("deleting a regular pod: expected 404, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	DebugCopyDeleteHandler(w, httptest.NewRequest(http.MethodDelete, "/api/pods/debug-copies/default/my-pod-debug-1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := cs.CoreV1().Pods("default").Get(context.Background(), "my-pod-debug-1", metav1.GetOptions{}); err == nil {
+		t.Error("debug copy still exists")
+	}
+}
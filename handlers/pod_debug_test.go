@@ -326,6 +326,7 @@ func TestPodDebugHandler_GeneratesNameWhenOmitted(t *testing.T) {
 }
 
 func TestPodDebugHandler_ImagePullFailure(t *testing.T) {
+	withAnyDebugImage(t)
 	pod := newRunningPod("default", "my-pod")
 	cs := fake.NewSimpleClientset(pod)
 	withDebugClientset(t, cs)
@@ -0,0 +1,671 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Finding severities, most severe first.
+const (
+	severityCritical = "critical"
+	severityWarning  = "warning"
+	severityInfo     = "info"
+)
+
+// Finding categories group related causes in the UI.
+const (
+	categoryScheduling = "Scheduling"
+	categoryImage      = "Image"
+	categoryCrash      = "Crash"
+	categoryMemory     = "Memory"
+	categoryProbe      = "Probe"
+	categoryVolume     = "Volume"
+	categoryConfig     = "Configuration"
+	categoryEviction   = "Eviction"
+)
+
+// highRestartThreshold is the restart count above which restarts alone are reported.
+const highRestartThreshold = 5
+
+var severityRank = map[string]int{severityCritical: 0, severityWarning: 1, severityInfo: 2}
+
+// DiagnosisFinding is one probable cause of a pod being unhealthy.
+type DiagnosisFinding struct {
+	Severity    string   `json:"severity"`
+	Category    string   `json:"category"`
+	Reason      string   `json:"reason"`
+	Container   string   `json:"container,omitempty"`
+	Explanation string   `json:"explanation"`
+	Suggestions []string `json:"suggestions"`
+	// confidence orders findings of equal severity; higher is more likely the root cause.
+	confidence int
+}
+
+// diagnosisContext holds the cluster state a diagnosis draws on beyond the pod itself.
+type diagnosisContext struct {
+	// events are the pod's events, newest first.
+	events []corev1.Event
+	// pvcs maps claim name to claim in the pod's namespace; nil when unavailable.
+	pvcs map[string]*corev1.PersistentVolumeClaim
+}
+
+// Fragments of the scheduler's FailedScheduling / Unschedulable message.
+var (
+	reInsufficient     = regexp.MustCompile(`Insufficient ([a-zA-Z0-9./-]*[a-zA-Z0-9])`)
+	reUntoleratedTaint = regexp.MustCompile(`untolerated taint(?:\(s\))? ?(\{[^}]*\})?`)
+)
+
+// diagnosePod examines a pod's status, events and related objects and returns
+// ranked findings explaining why it is unhealthy, most likely root cause first.
+func diagnosePod(pod corev1.Pod, dc diagnosisContext) []DiagnosisFinding {
+	var findings []DiagnosisFinding
+	add := func(f DiagnosisFinding) { findings = append(findings, f) }
+
+	diagnoseEviction(pod, add)
+	diagnoseScheduling(pod, dc, add)
+	diagnoseVolumes(pod, dc, add)
+
+	for _, cs := range pod.Status.InitContainerStatuses {
+		diagnoseContainer(pod, cs, true, add)
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		diagnoseContainer(pod, cs, false, add)
+	}
+
+	diagnoseProbes(pod, dc, add)
+	diagnoseVolumeEvents(dc, add)
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if si, sj := severityRank[findings[i].Severity], severityRank[findings[j].Severity]; si != sj {
+			return si < sj
+		}
+		return findings[i].confidence > findings[j].confidence
+	})
+	return dedupeFindings(findings)
+}
+
+// dedupeFindings drops repeated findings with the same reason and container, keeping the first.
+func dedupeFindings(findings []DiagnosisFinding) []DiagnosisFinding {
+	seen := make(map[string]bool, len(findings))
+	out := findings[:0]
+	for _, f := range findings {
+		key := f.Reason + "/" + f.Container
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, f)
+	}
+	return out
+}
+
+func diagnoseEviction(pod corev1.Pod, add func(DiagnosisFinding)) {
+	if pod.Status.Phase != corev1.PodFailed || pod.Status.Reason != "Evicted" {
+		return
+	}
+	add(DiagnosisFinding{
+		Severity:    severityCritical,
+		Category:    categoryEviction,
+		Reason:      "Evicted",
+		Explanation: fmt.Sprintf("The kubelet evicted the pod: %s", pod.Status.Message),
+		Suggestions: []string{
+			"Check the node for memory, disk or PID pressure",
+			"Set resource requests so the pod is not among the first evicted",
+			"Delete the evicted pod; its controller has already replaced it",
+		},
+		confidence: 90,
+	})
+}
+
+func diagnoseScheduling(pod corev1.Pod, dc diagnosisContext, add func(DiagnosisFinding)) {
+	if pod.Spec.NodeName != "" {
+		return
+	}
+
+	message := ""
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse {
+			message = c.Message
+		}
+	}
+	if message == "" {
+		if ev := findEvent(dc.events, "FailedScheduling"); ev != nil {
+			message = ev.Message
+		}
+	}
+	if message == "" {
+		return
+	}
+
+	matched := false
+	for _, m := range reInsufficient.FindAllStringSubmatch(message, -1) {
+		matched = true
+		add(DiagnosisFinding{
+			Severity: severityCritical,
+			Category: categoryScheduling,
+			Reason:   "Insufficient " + m[1],
+			Explanation: fmt.Sprintf("No node has enough allocatable %s left for the pod's requests. Scheduler: %s",
+				m[1], message),
+			Suggestions: []string{
+				fmt.Sprintf("Lower the pod's %s request if it is over-provisioned", m[1]),
+				"Add nodes or enable the cluster autoscaler",
+				"Remove or scale down workloads that reserve capacity they do not use",
+			},
+			confidence: 90,
+		})
+	}
+	if m := reUntoleratedTaint.FindStringSubmatch(message); m != nil {
+		matched = true
+		taint := strings.Trim(m[1], "{}")
+		explanation := "Candidate nodes carry taints the pod does not tolerate."
+		if taint != "" {
+			explanation = fmt.Sprintf("Candidate nodes carry the taint %s, which the pod does not tolerate.", taint)
+		}
+		add(DiagnosisFinding{
+			Severity:    severityCritical,
+			Category:    categoryScheduling,
+			Reason:      "UntoleratedTaint",
+			Explanation: explanation,
+			Suggestions: []string{
+				"Add a matching toleration to the pod template",
+				"Remove the taint if the node should accept general workloads",
+			},
+			confidence: 80,
+		})
+	}
+	if strings.Contains(message, "node affinity") || strings.Contains(message, "node selector") {
+		matched = true
+		add(DiagnosisFinding{
+			Severity:    severityCritical,
+			Category:    categoryScheduling,
+			Reason:      "NodeAffinityMismatch",
+			Explanation: "No node matches the pod's nodeSelector or node affinity rules.",
+			Suggestions: []string{
+				"Compare the pod's nodeSelector/affinity with the labels on your nodes",
+				"Label a node to match, or relax the affinity rule",
+			},
+			confidence: 75,
+		})
+	}
+	if strings.Contains(message, "unbound immediate PersistentVolumeClaims") || strings.Contains(message, "volume node affinity conflict") {
+		matched = true
+		add(DiagnosisFinding{
+			Severity:    severityCritical,
+			Category:    categoryVolume,
+			Reason:      "VolumeScheduling",
+			Explanation: fmt.Sprintf("The pod's volumes prevent scheduling. Scheduler: %s", message),
+			Suggestions: []string{
+				"Check that each PersistentVolumeClaim is Bound",
+				"Make sure the volume's zone matches a node the pod can run on",
+			},
+			confidence: 85,
+		})
+	}
+	if !matched {
+		add(DiagnosisFinding{
+			Severity:    severityCritical,
+			Category:    categoryScheduling,
+			Reason:      "Unschedulable",
+			Explanation: fmt.Sprintf("The scheduler could not place the pod: %s", message),
+			Suggestions: []string{"Review the scheduler message and the pod's requests, affinity and tolerations"},
+			confidence:  60,
+		})
+	}
+}
+
+func diagnoseVolumes(pod corev1.Pod, dc diagnosisContext, add func(DiagnosisFinding)) {
+	if dc.pvcs == nil {
+		return
+	}
+	for _, v := range pod.Spec.Volumes {
+		if v.PersistentVolumeClaim == nil {
+			continue
+		}
+		claim := v.PersistentVolumeClaim.ClaimName
+		pvc, ok := dc.pvcs[claim]
+		switch {
+		case !ok:
+			add(DiagnosisFinding{
+				Severity:    severityCritical,
+				Category:    categoryVolume,
+				Reason:      "PVCNotFound",
+				Explanation: fmt.Sprintf("PersistentVolumeClaim %q referenced by volume %q does not exist.", claim, v.Name),
+				Suggestions: []string{"Create the claim, or fix the claimName in the pod template"},
+				confidence:  95,
+			})
+		case pvc.Status.Phase == corev1.ClaimPending:
+			storageClass := "<default>"
+			if pvc.Spec.StorageClassName != nil {
+				storageClass = *pvc.Spec.StorageClassName
+			}
+			add(DiagnosisFinding{
+				Severity:    severityCritical,
+				Category:    categoryVolume,
+				Reason:      "PVCUnbound",
+				Explanation: fmt.Sprintf("PersistentVolumeClaim %q (storage class %s) is still Pending and not bound to a volume.", claim, storageClass),
+				Suggestions: []string{
+					"Check that the storage class exists and its provisioner is running",
+					"Look at the claim's events for provisioning errors",
+				},
+				confidence: 90,
+			})
+		case pvc.Status.Phase == corev1.ClaimLost:
+			add(DiagnosisFinding{
+				Severity:    severityCritical,
+				Category:    categoryVolume,
+				Reason:      "PVCLost",
+				Explanation: fmt.Sprintf("PersistentVolumeClaim %q lost its underlying volume.", claim),
+				Suggestions: []string{"Restore or recreate the PersistentVolume, then rebind the claim"},
+				confidence:  90,
+			})
+		}
+	}
+}
+
+func diagnoseContainer(pod corev1.Pod, cs corev1.ContainerStatus, init bool, add func(DiagnosisFinding)) {
+	kind := "Container"
+	if init {
+		kind = "Init container"
+	}
+
+	if w := cs.State.Waiting; w != nil {
+		switch w.Reason {
+		case "ImagePullBackOff", "ErrImagePull", "InvalidImageName", "ErrImageNeverPull":
+			add(imagePullFinding(cs, w))
+		case "CreateContainerConfigError":
+			add(DiagnosisFinding{
+				Severity:    severityCritical,
+				Category:    categoryConfig,
+				Reason:      w.Reason,
+				Container:   cs.Name,
+				Explanation: fmt.Sprintf("%s %q cannot be created from its configuration: %s", kind, cs.Name, w.Message),
+				Suggestions: []string{
+					"Check that every referenced ConfigMap, Secret and key exists in the namespace",
+				},
+				confidence: 90,
+			})
+		case "CreateContainerError", "RunContainerError":
+			add(DiagnosisFinding{
+				Severity:    severityCritical,
+				Category:    categoryConfig,
+				Reason:      w.Reason,
+				Container:   cs.Name,
+				Explanation: fmt.Sprintf("The container runtime failed to start %q: %s", cs.Name, w.Message),
+				Suggestions: []string{
+					"Check the command, working directory and volume mounts of the container",
+				},
+				confidence: 80,
+			})
+		case "CrashLoopBackOff":
+			add(crashFinding(pod, cs, kind))
+		}
+	}
+
+	if t := cs.State.Terminated; t != nil && t.ExitCode != 0 && t.Reason != "OOMKilled" {
+		add(terminationFinding(cs.Name, kind, t, 70))
+	}
+
+	if oom := lastOOMKill(cs); oom {
+		add(DiagnosisFinding{
+			Severity:    severityCritical,
+			Category:    categoryMemory,
+			Reason:      "OOMKilled",
+			Container:   cs.Name,
+			Explanation: fmt.Sprintf("%s %q was killed for exceeding its memory limit%s.", kind, cs.Name, memoryLimitSuffix(pod, cs.Name)),
+			Suggestions: []string{
+				"Raise the container's memory limit",
+				"Check the application for memory leaks or unbounded caches",
+				"For JVM or similar runtimes, cap the heap below the container limit",
+			},
+			confidence: 95,
+		})
+	}
+
+	if cs.RestartCount > highRestartThreshold && cs.State.Waiting == nil {
+		add(DiagnosisFinding{
+			Severity:    severityWarning,
+			Category:    categoryCrash,
+			Reason:      "FrequentRestarts",
+			Container:   cs.Name,
+			Explanation: fmt.Sprintf("%s %q has restarted %d times.", kind, cs.Name, cs.RestartCount),
+			Suggestions: []string{"Check the logs of the previous instance (logs with previous=true)"},
+			confidence:  40,
+		})
+	}
+
+	if !init && cs.State.Running != nil && !cs.Ready && pod.Status.Phase == corev1.PodRunning {
+		add(DiagnosisFinding{
+			Severity:    severityWarning,
+			Category:    categoryProbe,
+			Reason:      "NotReady",
+			Container:   cs.Name,
+			Explanation: fmt.Sprintf("Container %q is running but not ready, so it receives no Service traffic.", cs.Name),
+			Suggestions: []string{"Check the readiness probe configuration and the endpoint it calls"},
+			confidence:  30,
+		})
+	}
+}
+
+func imagePullFinding(cs corev1.ContainerStatus, w *corev1.ContainerStateWaiting) DiagnosisFinding {
+	lower := strings.ToLower(w.Message)
+	f := DiagnosisFinding{
+		Severity:    severityCritical,
+		Category:    categoryImage,
+		Reason:      w.Reason,
+		Container:   cs.Name,
+		Explanation: fmt.Sprintf("Image %q for container %q cannot be pulled: %s", cs.Image, cs.Name, w.Message),
+		confidence:  90,
+	}
+	switch {
+	case w.Reason == "InvalidImageName":
+		f.Suggestions = []string{"Fix the image reference; it is not a valid name"}
+	case strings.Contains(lower, "unauthorized") || strings.Contains(lower, "authentication required") ||
+		strings.Contains(lower, "pull access denied") || strings.Contains(lower, "forbidden"):
+		f.Suggestions = []string{
+			"Add or fix imagePullSecrets for the private registry",
+			"Check that the registry credentials have not expired",
+		}
+	case strings.Contains(lower, "not found") || strings.Contains(lower, "manifest unknown"):
+		f.Suggestions = []string{
+			"Check the image name and tag for typos",
+			"Confirm the tag was pushed to the registry",
+		}
+	case strings.Contains(lower, "timeout") || strings.Contains(lower, "no such host") || strings.Contains(lower, "connection refused"):
+		f.Suggestions = []string{"Check that the node can reach the registry (DNS, proxy, firewall)"}
+	default:
+		f.Suggestions = []string{"Check the image name, tag and registry credentials"}
+	}
+	return f
+}
+
+func crashFinding(pod corev1.Pod, cs corev1.ContainerStatus, kind string) DiagnosisFinding {
+	if t := cs.LastTerminationState.Terminated; t != nil && t.Reason != "OOMKilled" {
+		f := terminationFinding(cs.Name, kind, t, 85)
+		f.Reason = "CrashLoopBackOff"
+		f.Explanation = fmt.Sprintf("%s %q keeps crashing (%d restarts). %s", kind, cs.Name, cs.RestartCount, f.Explanation)
+		return f
+	}
+	if t := cs.LastTerminationState.Terminated; t != nil && t.Reason == "OOMKilled" {
+		// Reported by the OOMKilled finding; this one only adds the crash loop context.
+		return DiagnosisFinding{
+			Severity:    severityCritical,
+			Category:    categoryCrash,
+			Reason:      "CrashLoopBackOff",
+			Container:   cs.Name,
+			Explanation: fmt.Sprintf("%s %q keeps being OOM killed and restarted (%d restarts).", kind, cs.Name, cs.RestartCount),
+			Suggestions: []string{"See the OOMKilled finding"},
+			confidence:  60,
+		}
+	}
+	return DiagnosisFinding{
+		Severity:    severityCritical,
+		Category:    categoryCrash,
+		Reason:      "CrashLoopBackOff",
+		Container:   cs.Name,
+		Explanation: fmt.Sprintf("%s %q keeps crashing (%d restarts).", kind, cs.Name, cs.RestartCount),
+		Suggestions: []string{"Check the logs of the previous instance (logs with previous=true)"},
+		confidence:  70,
+	}
+}
+
+// terminationFinding explains a non-zero exit using well-known exit codes.
+func terminationFinding(container, kind string, t *corev1.ContainerStateTerminated, confidence int) DiagnosisFinding {
+	f := DiagnosisFinding{
+		Severity:   severityCritical,
+		Category:   categoryCrash,
+		Reason:     fmt.Sprintf("ExitCode%d", t.ExitCode),
+		Container:  container,
+		confidence: confidence,
+	}
+	detail := ""
+	if t.Message != "" {
+		detail = ": " + t.Message
+	}
+	switch t.ExitCode {
+	case 126, 127:
+		f.Category = categoryConfig
+		f.Explanation = fmt.Sprintf("%s %q exited with code %d: the command was not found or is not executable%s.", kind, container, t.ExitCode, detail)
+		f.Suggestions = []string{"Check the container's command and args against the image's contents"}
+	case 137:
+		f.Explanation = fmt.Sprintf("%s %q was killed with SIGKILL (exit 137)%s.", kind, container, detail)
+		f.Suggestions = []string{
+			"Check whether a liveness probe is killing the container",
+			"Check node memory pressure and the container's memory limit",
+		}
+	case 143:
+		f.Explanation = fmt.Sprintf("%s %q was terminated with SIGTERM (exit 143)%s.", kind, container, detail)
+		f.Suggestions = []string{"Check for failing liveness probes or external restarts"}
+	default:
+		f.Explanation = fmt.Sprintf("%s %q exited with code %d (%s)%s.", kind, container, t.ExitCode, t.Reason, detail)
+		f.Suggestions = []string{
+			"Check the logs of the previous instance (logs with previous=true)",
+			"Verify required configuration, environment variables and dependencies",
+		}
+	}
+	return f
+}
+
+func lastOOMKill(cs corev1.ContainerStatus) bool {
+	if t := cs.State.Terminated; t != nil && t.Reason == "OOMKilled" {
+		return true
+	}
+	if t := cs.LastTerminationState.Terminated; t != nil && t.Reason == "OOMKilled" {
+		return true
+	}
+	return false
+}
+
+func memoryLimitSuffix(pod corev1.Pod, container string) string {
+	for _, c := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		if c.Name != container {
+			continue
+		}
+		if limit, ok := c.Resources.Limits[corev1.ResourceMemory]; ok {
+			return fmt.Sprintf(" (%s)", limit.String())
+		}
+		return " (no limit set, so the node ran out of memory)"
+	}
+	return ""
+}
+
+func diagnoseProbes(pod corev1.Pod, dc diagnosisContext, add func(DiagnosisFinding)) {
+	for _, probe := range []string{"Liveness", "Readiness", "Startup"} {
+		var count int32
+		var latest *corev1.Event
+		for i := range dc.events {
+			ev := &dc.events[i]
+			if ev.Reason != "Unhealthy" || !strings.HasPrefix(ev.Message, probe+" probe failed") {
+				continue
+			}
+			count += eventCount(*ev)
+			if latest == nil {
+				latest = ev
+			}
+		}
+		if latest == nil {
+			continue
+		}
+
+		f := DiagnosisFinding{
+			Severity:  severityWarning,
+			Category:  categoryProbe,
+			Reason:    probe + "ProbeFailed",
+			Container: eventContainer(*latest),
+			Explanation: fmt.Sprintf("The %s probe failed %d time(s); latest: %s",
+				strings.ToLower(probe), count, latest.Message),
+			confidence: 50,
+		}
+		switch probe {
+		case "Liveness":
+			f.Severity = severityCritical
+			f.confidence = 75
+			f.Suggestions = []string{
+				"Liveness failures restart the container; make sure the probe path and port are correct",
+				"Increase initialDelaySeconds or timeoutSeconds if the app is slow to respond",
+			}
+		case "Startup":
+			f.Suggestions = []string{"Raise failureThreshold × periodSeconds to cover the application's start time"}
+		default:
+			f.Suggestions = []string{"Check the readiness endpoint and its dependencies (databases, downstream services)"}
+		}
+		add(f)
+	}
+}
+
+func diagnoseVolumeEvents(dc diagnosisContext, add func(DiagnosisFinding)) {
+	for _, reason := range []string{"FailedMount", "FailedAttachVolume"} {
+		if ev := findEvent(dc.events, reason); ev != nil {
+			add(DiagnosisFinding{
+				Severity:    severityCritical,
+				Category:    categoryVolume,
+				Reason:      reason,
+				Explanation: fmt.Sprintf("A volume could not be mounted: %s", ev.Message),
+				Suggestions: []string{
+					"Check that referenced ConfigMaps, Secrets and claims exist",
+					"For block storage, make sure the volume is not still attached to another node",
+				},
+				confidence: 85,
+			})
+		}
+	}
+}
+
+// findEvent returns the newest event with the given reason.
+func findEvent(events []corev1.Event, reason string) *corev1.Event {
+	for i := range events {
+		if events[i].Reason == reason {
+			return &events[i]
+		}
+	}
+	return nil
+}
+
+func eventCount(ev corev1.Event) int32 {
+	if ev.Series != nil && ev.Series.Count > 0 {
+		return ev.Series.Count
+	}
+	if ev.Count > 0 {
+		return ev.Count
+	}
+	return 1
+}
+
+// eventContainer extracts the container name from an event's fieldPath (spec.containers{name}).
+func eventContainer(ev corev1.Event) string {
+	fp := ev.InvolvedObject.FieldPath
+	if i := strings.Index(fp, "{"); i >= 0 && strings.HasSuffix(fp, "}") {
+		return fp[i+1 : len(fp)-1]
+	}
+	return ""
+}
+
+// listUnhealthyPods returns unhealthy pods with usage and a diagnosis attached.
+// Events and claims are fetched once per call; if either is unavailable the
+// diagnosis is built from the pod status alone.
+func listUnhealthyPods(ctx context.Context, clientset kubernetes.Interface, namespace string, metricsMap map[string]podMetricsUsage) ([]PodDetails, error) {
+	podList, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	unhealthy := make([]corev1.Pod, 0)
+	for _, pod := range podList.Items {
+		if !isPodHealthy(pod) {
+			unhealthy = append(unhealthy, pod)
+		}
+	}
+
+	pods := make([]PodDetails, 0, len(unhealthy))
+	if len(unhealthy) == 0 {
+		return pods, nil
+	}
+
+	eventsByPod := make(map[string][]corev1.Event)
+	if eventList, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Pod",
+	}); err != nil {
+		slog.Warn("Failed to list events for diagnosis", "error", err)
+	} else {
+		for _, ev := range eventList.Items {
+			if ev.InvolvedObject.Kind != "Pod" {
+				continue
+			}
+			key := podEventKey(ev.InvolvedObject.UID, ev.InvolvedObject.Namespace, ev.InvolvedObject.Name)
+			eventsByPod[key] = append(eventsByPod[key], ev)
+		}
+	}
+
+	var pvcsByNamespace map[string]map[string]*corev1.PersistentVolumeClaim
+	if pvcList, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		slog.Warn("Failed to list PersistentVolumeClaims for diagnosis", "error", err)
+	} else {
+		pvcsByNamespace = make(map[string]map[string]*corev1.PersistentVolumeClaim)
+		for i := range pvcList.Items {
+			pvc := &pvcList.Items[i]
+			if pvcsByNamespace[pvc.Namespace] == nil {
+				pvcsByNamespace[pvc.Namespace] = make(map[string]*corev1.PersistentVolumeClaim)
+			}
+			pvcsByNamespace[pvc.Namespace][pvc.Name] = pvc
+		}
+	}
+
+	for _, pod := range unhealthy {
+		dc := diagnosisContext{events: podEvents(eventsByPod, pod)}
+		if pvcsByNamespace != nil {
+			dc.pvcs = pvcsByNamespace[pod.Namespace]
+			if dc.pvcs == nil {
+				dc.pvcs = map[string]*corev1.PersistentVolumeClaim{}
+			}
+		}
+		details := buildPodDetails(pod, metricsMap[pod.Namespace+"/"+pod.Name])
+		details.Diagnosis = diagnosePod(pod, dc)
+		pods = append(pods, details)
+	}
+	return pods, nil
+}
+
+// podEventKey keys a pod's events by UID, so a pod recreated under the same
+// name does not inherit its predecessor's events. Events without a UID fall
+// back to namespace/name.
+func podEventKey(uid types.UID, namespace, name string) string {
+	if uid != "" {
+		return "uid:" + string(uid)
+	}
+	return namespace + "/" + name
+}
+
+// podEvents returns the events recorded for the pod, newest first: those for its
+// UID plus any that carry no UID.
+func podEvents(eventsByPod map[string][]corev1.Event, pod corev1.Pod) []corev1.Event {
+	byName := podEventKey("", pod.Namespace, pod.Name)
+	events := append([]corev1.Event{}, eventsByPod[byName]...)
+	if pod.UID != "" {
+		events = append(events, eventsByPod[podEventKey(pod.UID, pod.Namespace, pod.Name)]...)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return eventTime(events[i]).After(eventTime(events[j])) })
+	return events
+}
+
+// eventTime returns the most recent timestamp recorded on an event.
+func eventTime(ev corev1.Event) time.Time {
+	switch {
+	case ev.Series != nil && !ev.Series.LastObservedTime.IsZero():
+		return ev.Series.LastObservedTime.Time
+	case !ev.LastTimestamp.IsZero():
+		return ev.LastTimestamp.Time
+	case !ev.EventTime.IsZero():
+		return ev.EventTime.Time
+	default:
+		return ev.CreationTimestamp.Time
+	}
+}
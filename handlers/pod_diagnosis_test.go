@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func findingReasons(findings []DiagnosisFinding) []string {
+	reasons := make([]string, 0, len(findings))
+	for _, f := range findings {
+		reasons = append(reasons, f.Reason)
+	}
+	return reasons
+}
+
+func newPendingPod(message string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pending"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable", Message: message,
+			}},
+		},
+	}
+}
+
+func TestDiagnosePod_Scheduling(t *testing.T) {
+	pod := newPendingPod("0/3 nodes are available: 1 Insufficient cpu, 1 Insufficient memory, " +
+		"1 node(s) had untolerated taint {dedicated: gpu}. preemption: 0/3 nodes are available.")
+	findings := diagnosePod(pod, diagnosisContext{})
+
+	got := strings.Join(findingReasons(findings), ",")
+	if got != "Insufficient cpu,Insufficient memory,UntoleratedTaint" {
+		t.Fatalf("findings = %s", got)
+	}
+	if !strings.Contains(findings[2].Explanation, "dedicated: gpu") {
+		t.Errorf("taint not named: %q", findings[2].Explanation)
+	}
+	for _, f := range findings {
+		if len(f.Suggestions) == 0 {
+			t.Errorf("%s has no suggestions", f.Reason)
+		}
+	}
+
+	unknown := diagnosePod(newPendingPod("0/1 nodes are available: something new."), diagnosisContext{})
+	if len(unknown) != 1 || unknown[0].Reason != "Unschedulable" {
+		t.Errorf("expected generic finding, got %v", findingReasons(unknown))
+	}
+}
+
+func TestDiagnosePod_UnboundPVC(t *testing.T) {
+	pod := newPendingPod("")
+	pod.Spec.Volumes = []corev1.Volume{
+		{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}},
+		{Name: "cache", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "missing"}}},
+	}
+	pvcs := map[string]*corev1.PersistentVolumeClaim{
+		"data": {Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending}},
+	}
+
+	got := strings.Join(findingReasons(diagnosePod(pod, diagnosisContext{pvcs: pvcs})), ",")
+	if got != "PVCNotFound,PVCUnbound" {
+		t.Errorf("findings = %s", got)
+	}
+	if findings := diagnosePod(pod, diagnosisContext{}); len(findings) != 0 {
+		t.Errorf("claims unknown: expected no volume findings, got %v", findingReasons(findings))
+	}
+}
+
+func TestDiagnosePod_ContainerStates(t *testing.T) {
+	memLimit := resource.MustParse("256Mi")
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "broken"},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Containers: []corev1.Container{
+				{Name: "app", Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: memLimit}}},
+				{Name: "sidecar"},
+				{Name: "worker"},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:                 "app",
+					RestartCount:         4,
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+				},
+				{
+					Name:  "sidecar",
+					Image: "registry.internal/sidecar:v9",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "ImagePullBackOff",
+						Message: `Back-off pulling image "registry.internal/sidecar:v9": manifest unknown`,
+					}},
+				},
+				{
+					Name:                 "worker",
+					RestartCount:         2,
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 127}},
+				},
+			},
+		},
+	}
+
+	findings := diagnosePod(pod, diagnosisContext{})
+	if findings[0].Reason != "OOMKilled" || findings[0].Container != "app" {
+		t.Fatalf("OOMKilled should rank first, got %v", findingReasons(findings))
+	}
+	if !strings.Contains(findings[0].Explanation, "256Mi") {
+		t.Errorf("OOM explanation should mention the limit: %q", findings[0].Explanation)
+	}
+
+	var pull, worker *DiagnosisFinding
+	for i := range findings {
+		switch findings[i].Container {
+		case "sidecar":
+			pull = &findings[i]
+		case "worker":
+			worker = &findings[i]
+		}
+	}
+	if pull == nil || pull.Reason != "ImagePullBackOff" || !strings.Contains(pull.Suggestions[0], "tag") {
+		t.Errorf("unexpected image finding %+v", pull)
+	}
+	if worker == nil || worker.Category != categoryConfig || !strings.Contains(worker.Explanation, "not found") {
+		t.Errorf("exit 127 should point at the command, got %+v", worker)
+	}
+}
+
+func TestDiagnosePod_ProbeEvents(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec:       corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{Name: "app"}}},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "app",
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}},
+		},
+	}
+	events := []corev1.Event{
+		{
+			Reason:         "Unhealthy",
+			Message:        "Readiness probe failed: HTTP probe failed with statuscode: 503",
+			Count:          12,
+			InvolvedObject: corev1.ObjectReference{FieldPath: "spec.containers{app}"},
+		},
+		{Reason: "Unhealthy", Message: "Readiness probe failed: connection refused", Count: 3},
+	}
+
+	findings := diagnosePod(pod, diagnosisContext{events: events})
+	var probe *DiagnosisFinding
+	for i := range findings {
+		if findings[i].Reason == "ReadinessProbeFailed" {
+			probe = &findings[i]
+		}
+	}
+	if probe == nil {
+		t.Fatalf("expected readiness finding, got %v", findingReasons(findings))
+	}
+	if probe.Container != "app" || !strings.Contains(probe.Explanation, "15 time(s)") || !strings.Contains(probe.Explanation, "503") {
+		t.Errorf("unexpected probe finding %+v", probe)
+	}
+}
+
+func TestListUnhealthyPods_AttachesDiagnosis(t *testing.T) {
+	healthy := newRunningPod("default", "healthy")
+	pending := newPendingPod("")
+	now := time.Now()
+	cs := fake.NewSimpleClientset(healthy, &pending,
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: "pending.1"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "pending"},
+			Reason:         "FailedScheduling",
+			Message:        "0/2 nodes are available: 2 Insufficient memory.",
+			LastTimestamp:  metav1.NewTime(now),
+		},
+	)
+
+	pods, err := listUnhealthyPods(context.Background(), cs, "default", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 1 || pods[0].Name != "pending" {
+		t.Fatalf("expected only the pending pod, got %+v", pods)
+	}
+	if got := findingReasons(pods[0].Diagnosis); len(got) != 1 || got[0] != "Insufficient memory" {
+		t.Errorf("diagnosis = %v", got)
+	}
+}
+
+func TestListUnhealthyPods_IgnoresEventsOfPredecessor(t *testing.T) {
+	pending := newPendingPod("")
+	pending.UID = "new-uid"
+	now := time.Now()
+	event := func(name, uid, message string) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: name},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "pending", UID: types.UID(uid)},
+			Reason:         "FailedScheduling",
+			Message:        message,
+			LastTimestamp:  metav1.NewTime(now),
+		}
+	}
+	cs := fake.NewSimpleClientset(&pending,
+		event("pending.old", "old-uid", "0/2 nodes are available: 2 Insufficient memory."),
+		event("pending.new", "new-uid", "0/2 nodes are available: 2 Insufficient cpu."),
+	)
+
+	pods, err := listUnhealthyPods(context.Background(), cs, "default", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := findingReasons(pods[0].Diagnosis); len(got) != 1 || got[0] != "Insufficient cpu" {
+		t.Errorf("expected only the current pod's events, got %v", got)
+	}
+}
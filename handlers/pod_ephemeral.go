@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EphemeralContainerInfo describes an ephemeral debug container and its current state.
+type EphemeralContainerInfo struct {
+	Name            string     `json:"name"`
+	Image           string     `json:"image"`
+	TargetContainer string     `json:"targetContainer,omitempty"`
+	Command         []string   `json:"command,omitempty"`
+	State           string     `json:"state"`
+	Reason          string     `json:"reason,omitempty"`
+	StartedAt       *time.Time `json:"startedAt,omitempty"`
+	Capabilities    []string   `json:"capabilities"`
+	Privileged      bool       `json:"privileged"`
+	// Attachable reports whether the container is running with a TTY and stdin,
+	// so the exec WebSocket can reattach to it with mode=attach.
+	Attachable bool `json:"attachable"`
+}
+
+// PodEphemeralContainersHandler handles GET /api/pods/ephemeral/{namespace}/{name}.
+// It lists the pod's ephemeral containers with their image, target, state and capabilities.
+func PodEphemeralContainersHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, err := parseResourcePath(r.URL.Path, podEphemeralPathPrefix, "")
+	if err != nil {
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("Invalid path format. Expected %s{namespace}/{name}", podEphemeralPathPrefix))
+		return
+	}
+
+	clientset, err := getDebugClientset()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(r.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		writeResourceError(w, err, errMsgPodNotFound, errMsgPodEphemeralList)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, listEphemeralContainers(pod))
+}
+
+// listEphemeralContainers joins the pod's ephemeral container specs with their statuses.
+func listEphemeralContainers(pod *corev1.Pod) []EphemeralContainerInfo {
+	statuses := make(map[string]corev1.ContainerStatus, len(pod.Status.EphemeralContainerStatuses))
+	for _, s := range pod.Status.EphemeralContainerStatuses {
+		statuses[s.Name] = s
+	}
+
+	containers := make([]EphemeralContainerInfo, 0, len(pod.Spec.EphemeralContainers))
+	for _, ec := range pod.Spec.EphemeralContainers {
+		info := EphemeralContainerInfo{
+			Name:            ec.Name,
+			Image:           ec.Image,
+			TargetContainer: ec.TargetContainerName,
+			Command:         append(append([]string{}, ec.Command...), ec.Args...),
+			State:           "Pending",
+			Capabilities:    []string{},
+		}
+		if sc := ec.SecurityContext; sc != nil {
+			if sc.Capabilities != nil {
+				for _, c := range sc.Capabilities.Add {
+					info.Capabilities = append(info.Capabilities, string(c))
+				}
+			}
+			info.Privileged = sc.Privileged != nil && *sc.Privileged
+		}
+
+		if status, ok := statuses[ec.Name]; ok {
+			switch {
+			case status.State.Running != nil:
+				info.State = "Running"
+				started := status.State.Running.StartedAt.Time
+				info.StartedAt = &started
+				info.Attachable = ec.Stdin && ec.TTY
+			case status.State.Terminated != nil:
+				info.State = "Terminated"
+				info.Reason = status.State.Terminated.Reason
+				started := status.State.Terminated.StartedAt.Time
+				if !started.IsZero() {
+					info.StartedAt = &started
+				}
+			case status.State.Waiting != nil:
+				info.State = "Waiting"
+				info.Reason = status.State.Waiting.Reason
+			}
+		}
+		containers = append(containers, info)
+	}
+	return containers
+}
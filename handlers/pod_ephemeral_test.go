@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newPodWithEphemeral returns a pod with a running, a waiting and a terminated debug container.
+func newPodWithEphemeral() *corev1.Pod {
+	privileged := true
+	pod := newRunningPod("default", "my-pod")
+	pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{
+		{
+			TargetContainerName: "app",
+			EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+				Name: "shell", Image: "busybox:1.36", Command: []string{"/bin/sh"}, Stdin: true, TTY: true,
+				SecurityContext: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"SYS_PTRACE"}}},
+			},
+		},
+		{EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name: "pulling", Image: "missing:latest", Stdin: true, TTY: true,
+			SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+		}},
+		{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "done", Image: "busybox:1.36"}},
+	}
+	started := metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	pod.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{
+		{Name: "shell", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: started}}},
+		{Name: "pulling", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+		{Name: "done", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed", StartedAt: started}}},
+	}
+	return pod
+}
+
+func TestListEphemeralContainers(t *testing.T) {
+	got := listEphemeralContainers(newPodWithEphemeral())
+	if len(got) != 3 {
+		t.Fatalf("expected 3 containers, got %d", len(got))
+	}
+
+	shell := got[0]
+	if shell.State != "Running" || !shell.Attachable || shell.TargetContainer != "app" || shell.StartedAt == nil {
+		t.Errorf("unexpected running container %+v", shell)
+	}
+	if len(shell.Capabilities) != 1 || shell.Capabilities[0] != "SYS_PTRACE" {
+		t.Errorf("capabilities = %v", shell.Capabilities)
+	}
+
+	pulling := got[1]
+	if pulling.State != "Waiting" || pulling.Reason != "ImagePullBackOff" || pulling.Attachable || !pulling.Privileged {
+		t.Errorf("unexpected waiting container %+v", pulling)
+	}
+
+	done := got[2]
+	if done.State != "Terminated" || done.Reason != "Completed" || done.Attachable {
+		t.Errorf("unexpected terminated container %+v", done)
+	}
+}
+
+func TestPodEphemeralContainersHandler(t *testing.T) {
+	withDebugClientset(t, fake.NewSimpleClientset(newPodWithEphemeral()))
+
+	w := httptest.NewRecorder()
+	PodEphemeralContainersHandler(w, httptest.NewRequest(http.MethodGet, "/api/pods/ephemeral/default/my-pod", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var containers []EphemeralContainerInfo
+	if err := json.NewDecoder(w.Body).Decode(&containers); err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 3 || containers[0].Name != "shell" {
+		t.Errorf("unexpected containers %+v", containers)
+	}
+
+	w = httptest.NewRecorder()
+	PodEphemeralContainersHandler(w, httptest.NewRequest(http.MethodGet, "/api/pods/ephemeral/default/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing pod: expected 404, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	PodEphemeralContainersHandler(w, httptest.NewRequest(http.MethodGet, "/api/pods/ephemeral/default", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid path: expected 400, got %d", w.Code)
+	}
+}
+
+func TestPodExecHandler_AttachValidation(t *testing.T) {
+	old := getExecClientset
+	cs := fake.NewSimpleClientset(newPodWithEphemeral())
+	getExecClientset = func() (kubernetes.Interface, error) { return cs, nil }
+	t.Cleanup(func() { getExecClientset = old })
+
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{"unknown mode", "?container=shell&mode=spy", http.StatusBadRequest},
+		{"container without tty", "?container=app&mode=attach", http.StatusBadRequest},
+		{"container not running", "?container=pulling&mode=attach", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			PodExecHandler(w, httptest.NewRequest(http.MethodGet, "/api/pods/exec/default/my-pod"+tt.query, nil))
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	if !isAttachable(newPodWithEphemeral(), "shell") {
+		t.Error("running debug shell should be attachable")
+	}
+}
@@ -84,8 +84,16 @@ var getExecRESTConfig func() (*rest.Config, error) = getRESTConfig
 // Tests may override this to inject a mock executor.
 var newSPDYExecutor = remotecommand.NewSPDYExecutor
 
-// PodExecHandler handles the GET /api/pods/exec/{namespace}/{name}?container=... endpoint.
+// Exec WebSocket modes selected with the mode query parameter.
+const (
+	execModeExec   = "exec"
+	execModeAttach = "attach"
+)
+
+// PodExecHandler handles the GET /api/pods/exec/{namespace}/{name}?container=...&mode=... endpoint.
 // It upgrades the connection to WebSocket and bridges it to a Kubernetes exec stream.
+// With mode=attach it reattaches to the TTY of the container's running process
+// (typically an ephemeral debug shell) via pods/attach instead of starting a new shell.
 func PodExecHandler(w http.ResponseWriter, r *http.Request) {
 	namespace, name, err := parseResourcePath(r.URL.Path, podExecPathPrefix, "")
 	if err != nil {
@@ -100,6 +108,15 @@ func PodExecHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = execModeExec
+	}
+	if mode != execModeExec && mode != execModeAttach {
+		writeError(w, http.StatusBadRequest, errMsgExecModeInvalid)
+		return
+	}
+
 	clientset, err := getExecClientset()
 	if err != nil {
 		slog.Error("Failed to create Kubernetes client", "error", err)
@@ -121,6 +138,11 @@ func PodExecHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if mode == execModeAttach && !isAttachable(pod, container) {
+		writeError(w, http.StatusBadRequest, errMsgContainerNotAttachable)
+		return
+	}
+
 	config, err := getExecRESTConfig()
 	if err != nil {
 		slog.Error("Failed to get REST config", "error", err)
@@ -143,21 +165,32 @@ func PodExecHandler(w http.ResponseWriter, r *http.Request) {
 		doneChan: make(chan struct{}),
 	}
 
-	// Build the exec request URL.
-	execURL := clientset.CoreV1().RESTClient().Post().
+	// Build the exec or attach request URL.
+	req := clientset.CoreV1().RESTClient().Post().
 		Resource("pods").
 		Name(name).
-		Namespace(namespace).
-		SubResource("exec").
-		VersionedParams(&corev1.PodExecOptions{
-			Container: container,
-			Command:   []string{"/bin/sh"},
-			Stdin:     true,
-			Stdout:    true,
-			Stderr:    true,
-			TTY:       true,
-		}, scheme.ParameterCodec).
-		URL()
+		Namespace(namespace)
+	if mode == execModeAttach {
+		req = req.SubResource("attach").
+			VersionedParams(&corev1.PodAttachOptions{
+				Container: container,
+				Stdin:     true,
+				Stdout:    true,
+				Stderr:    true,
+				TTY:       true,
+			}, scheme.ParameterCodec)
+	} else {
+		req = req.SubResource("exec").
+			VersionedParams(&corev1.PodExecOptions{
+				Container: container,
+				Command:   []string{"/bin/sh"},
+				Stdin:     true,
+				Stdout:    true,
+				Stderr:    true,
+				TTY:       true,
+			}, scheme.ParameterCodec)
+	}
+	execURL := req.URL()
 
 	executor, err := newSPDYExecutor(config, "POST", execURL)
 	if err != nil {
@@ -201,6 +234,11 @@ func PodExecHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
+	// Node debug pods exist only while a shell session is open on them.
+	if isNodeDebugPod(pod) {
+		defer trackNodeDebugSession(clientset, namespace, name)()
+	}
+
 	// Run the exec stream. This blocks until the remote process exits or the connection is closed.
 	err = executor.StreamWithContext(r.Context(), remotecommand.StreamOptions{
 		Stdin:             stdinReader,
@@ -236,3 +274,30 @@ func hasContainer(pod *corev1.Pod, containerName string) bool {
 	}
 	return false
 }
+
+// isAttachable reports whether the named container is running with stdin and a TTY,
+// which pods/attach needs to hand over an interactive terminal.
+func isAttachable(pod *corev1.Pod, containerName string) bool {
+	attachable := false
+	for _, c := range pod.Spec.Containers {
+		if c.Name == containerName {
+			attachable = c.Stdin && c.TTY
+		}
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == containerName {
+			attachable = c.Stdin && c.TTY
+		}
+	}
+	if !attachable {
+		return false
+	}
+
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.ContainerStatuses...), pod.Status.EphemeralContainerStatuses...)
+	for _, s := range statuses {
+		if s.Name == containerName {
+			return s.State.Running != nil
+		}
+	}
+	return false
+}
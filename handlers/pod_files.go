@@ -0,0 +1,508 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// maxDownloadBytes caps the size of a file or directory downloaded from a container.
+// Tests may override this.
+var maxDownloadBytes int64 = 1 << 30
+
+// maxUploadBytes caps the size of a single file uploaded into a container.
+// Tests may override this.
+var maxUploadBytes int64 = 100 << 20
+
+// FileEntry describes a single entry of a container directory listing.
+type FileEntry struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Size    int64  `json:"size"`
+	Mode    string `json:"mode"`
+	ModTime string `json:"modTime"`
+}
+
+// DirectoryListing is the response of the directory list endpoint.
+type DirectoryListing struct {
+	Path    string      `json:"path"`
+	Entries []FileEntry `json:"entries"`
+}
+
+// listDirScript prints one "type|size|mtime|mode|name" line per entry of $1.
+// It relies only on sh and stat so it works in busybox-based images.
+const listDirScript = `cd -- "$1" || exit 2
+for f in * .[!.]* ..?*; do
+  if [ -e "$f" ] || [ -L "$f" ]; then stat -c '%F|%s|%Y|%A|%n' -- "$f"; fi
+done`
+
+// PodFilesHandler handles /api/pods/files/{namespace}/{name}[/download|/upload]?container=...&path=...
+// GET lists a directory, GET .../download streams a file or directory as tar or zip,
+// and POST .../upload extracts a multipart file into a directory of the container.
+func PodFilesHandler(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, downloadPathSuffix):
+		handleFileDownload(w, r)
+	case strings.HasSuffix(r.URL.Path, uploadPathSuffix):
+		handleFileUpload(w, r)
+	default:
+		handleListDirectory(w, r)
+	}
+}
+
+// fileRequest holds the validated parameters shared by all file endpoints.
+type fileRequest struct {
+	namespace string
+	name      string
+	container string
+	path      string
+	config    *rest.Config
+}
+
+// parseFileRequest validates the path, container and file path parameters, and
+// checks that the container exists. It writes an error response and returns nil on failure.
+func parseFileRequest(w http.ResponseWriter, r *http.Request, suffix string) *fileRequest {
+	namespace, name, err := parseResourcePath(r.URL.Path, podFilesPathPrefix, suffix)
+	if err != nil {
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("Invalid path format. Expected %s{namespace}/{name}%s", podFilesPathPrefix, suffix))
+		return nil
+	}
+
+	container := r.URL.Query().Get("container")
+	if container == "" {
+		writeError(w, http.StatusBadRequest, errMsgContainerRequired)
+		return nil
+	}
+
+	filePath := r.URL.Query().Get("path")
+	if filePath == "" || !path.IsAbs(filePath) {
+		writeError(w, http.StatusBadRequest, errMsgFilePathInvalid)
+		return nil
+	}
+
+	clientset, err := getExecClientset()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return nil
+	}
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(r.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		writeResourceError(w, err, errMsgPodNotFound, errMsgPodExecFailed)
+		return nil
+	}
+	if !hasContainer(pod, container) {
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("Container %q not found in pod %s/%s", container, namespace, name))
+		return nil
+	}
+
+	config, err := getExecRESTConfig()
+	if err != nil {
+		slog.Error("Failed to get REST config", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return nil
+	}
+
+	return &fileRequest{
+		namespace: namespace,
+		name:      name,
+		container: container,
+		path:      path.Clean(filePath),
+		config:    config,
+	}
+}
+
+func handleListDirectory(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	r = withTimeout(r)
+
+	fr := parseFileRequest(w, r, "")
+	if fr == nil {
+		return
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := execInContainer(r.Context(), fr.config, fr.namespace, fr.name, fr.container,
+		[]string{"sh", "-c", listDirScript, "sh", fr.path}, nil, &stdout, &stderr)
+	if err != nil {
+		writeFileExecError(w, err, stderr.String(), "sh")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, DirectoryListing{Path: fr.path, Entries: parseDirectoryListing(stdout.String())})
+}
+
+func handleFileDownload(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	fr := parseFileRequest(w, r, downloadPathSuffix)
+	if fr == nil {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "tar"
+	}
+	if format != "tar" && format != "zip" {
+		writeError(w, http.StatusBadRequest, "Format must be tar or zip")
+		return
+	}
+
+	if size, ok := containerPathSize(r.Context(), fr); ok && size > maxDownloadBytes {
+		writeError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("%s: %d bytes exceeds the %d byte limit", errMsgFileTooLarge, size, maxDownloadBytes))
+		return
+	}
+
+	base := path.Base(fr.path)
+	if base == "/" {
+		base = "root"
+	}
+
+	// Headers are deferred until tar produces its first byte, so a missing
+	// tar binary or a bad path can still be reported as a JSON error.
+	out := &lazyResponseWriter{w: w, start: func(w http.ResponseWriter) {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", base+"."+format))
+		if format == "zip" {
+			w.Header().Set("Content-Type", "application/zip")
+		} else {
+			w.Header().Set("Content-Type", "application/x-tar")
+		}
+		w.WriteHeader(http.StatusOK)
+	}}
+	limited := &limitedWriter{w: out, remaining: maxDownloadBytes}
+
+	var stderr bytes.Buffer
+	// "--" keeps a base name starting with a dash from being read as an option.
+	tarCmd := []string{"tar", "cf", "-", "-C", path.Dir(fr.path), "--", path.Base(fr.path)}
+	if fr.path == "/" {
+		tarCmd = []string{"tar", "cf", "-", "-C", "/", "."}
+	}
+
+	var err error
+	if format == "zip" {
+		pr, pw := io.Pipe()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			execErr := execInContainer(r.Context(), fr.config, fr.namespace, fr.name, fr.container,
+				tarCmd, nil, pw, &stderr)
+			pw.CloseWithError(execErr)
+		}()
+		err = tarToZip(pr, limited)
+		pr.CloseWithError(err)
+		<-done
+	} else {
+		err = execInContainer(r.Context(), fr.config, fr.namespace, fr.name, fr.container,
+			tarCmd, nil, limited, &stderr)
+	}
+
+	if err == nil {
+		if !out.started {
+			out.start(w)
+		}
+		return
+	}
+	if out.started {
+		// The archive is already partially on the wire; all we can do is log and cut it short.
+		slog.Error("File download aborted", "error", err, "namespace", fr.namespace,
+			"pod", fr.name, "container", fr.container, "path", fr.path)
+		return
+	}
+	if errors.Is(err, errTransferLimit) {
+		writeError(w, http.StatusRequestEntityTooLarge, errMsgFileTooLarge)
+		return
+	}
+	writeFileExecError(w, err, stderr.String(), "tar")
+}
+
+func handleFileUpload(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	fr := parseFileRequest(w, r, uploadPathSuffix)
+	if fr == nil {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, errMsgFileTooLarge)
+			return
+		}
+		writeError(w, http.StatusBadRequest, "Multipart form field \"file\" is required")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxUploadBytes {
+		writeError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("%s: %d bytes exceeds the %d byte limit", errMsgFileTooLarge, header.Size, maxUploadBytes))
+		return
+	}
+
+	fileName := path.Base(header.Filename)
+	if fileName == "." || fileName == ".." || fileName == "/" || strings.ContainsAny(fileName, `\`) {
+		writeError(w, http.StatusBadRequest, "Invalid file name")
+		return
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := tw.WriteHeader(&tar.Header{
+			Name:    fileName,
+			Mode:    0o644,
+			Size:    header.Size,
+			ModTime: time.Now(),
+		})
+		if err == nil {
+			_, err = io.Copy(tw, file)
+		}
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	var stderr bytes.Buffer
+	err = execInContainer(r.Context(), fr.config, fr.namespace, fr.name, fr.container,
+		[]string{"tar", "xmf", "-", "-C", fr.path}, pr, io.Discard, &stderr)
+	pr.Close()
+	if err != nil {
+		writeFileExecError(w, err, stderr.String(), "tar")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "File uploaded successfully",
+		"path":    path.Join(fr.path, fileName),
+		"size":    header.Size,
+	})
+}
+
+// execInContainer runs command in the container without a TTY and waits for it to finish.
+func execInContainer(
+	ctx context.Context,
+	config *rest.Config,
+	namespace, podName, container string,
+	command []string,
+	stdin io.Reader,
+	stdout, stderr io.Writer,
+) error {
+	execURL, err := url.Parse(config.Host)
+	if err != nil {
+		return err
+	}
+	execURL.Path = path.Join(execURL.Path, "api", "v1", "namespaces", namespace, "pods", podName, "exec")
+	params, err := scheme.ParameterCodec.EncodeParameters(&corev1.PodExecOptions{
+		Container: container,
+		Command:   command,
+		Stdin:     stdin != nil,
+		Stdout:    stdout != nil,
+		Stderr:    stderr != nil,
+	}, corev1.SchemeGroupVersion)
+	if err != nil {
+		return err
+	}
+	execURL.RawQuery = params.Encode()
+
+	executor, err := newSPDYExecutor(config, http.MethodPost, execURL)
+	if err != nil {
+		return err
+	}
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
+
+// containerPathSize returns the disk usage of the request path in bytes using du.
+// ok is false when du is unavailable or fails; the streaming limit still applies then.
+func containerPathSize(ctx context.Context, fr *fileRequest) (size int64, ok bool) {
+	var stdout bytes.Buffer
+	err := execInContainer(ctx, fr.config, fr.namespace, fr.name, fr.container,
+		[]string{"du", "-sk", fr.path}, nil, &stdout, io.Discard)
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(stdout.String())
+	if len(fields) == 0 {
+		return 0, false
+	}
+	kb, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return kb * 1024, true
+}
+
+// writeFileExecError maps an exec failure onto an HTTP response, reporting a
+// missing binary in the image and missing paths explicitly.
+func writeFileExecError(w http.ResponseWriter, err error, stderr, binary string) {
+	msg := strings.TrimSpace(stderr)
+	lower := strings.ToLower(msg + " " + err.Error())
+
+	var exitErr utilexec.ExitError
+	exitCode := -1
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitStatus()
+	}
+
+	switch {
+	case exitCode == 127 || strings.Contains(lower, "executable file not found") ||
+		strings.Contains(lower, binary+": not found"):
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("%q is not available in the container image; file transfer requires it", binary))
+	case strings.Contains(lower, "no such file or directory") || strings.Contains(lower, "can't cd"):
+		writeError(w, http.StatusNotFound, errMsgFileNotFound)
+	case strings.Contains(lower, "permission denied"):
+		writeError(w, http.StatusForbidden, msg)
+	case exitCode > 0 && msg != "":
+		writeError(w, http.StatusBadRequest, msg)
+	default:
+		slog.Error("File transfer exec failed", "error", err, "stderr", msg)
+		writeError(w, http.StatusInternalServerError, errMsgFileTransferFailed)
+	}
+}
+
+// parseDirectoryListing parses the output of listDirScript.
+func parseDirectoryListing(output string) []FileEntry {
+	entries := make([]FileEntry, 0)
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, "|", 5)
+		if len(parts) != 5 {
+			continue
+		}
+		size, _ := strconv.ParseInt(parts[1], 10, 64)
+		entry := FileEntry{
+			Name: parts[4],
+			Type: fileTypeFromStat(parts[0]),
+			Size: size,
+			Mode: parts[3],
+		}
+		if mtime, err := strconv.ParseInt(parts[2], 10, 64); err == nil {
+			entry.ModTime = time.Unix(mtime, 0).UTC().Format(time.RFC3339)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// fileTypeFromStat normalizes the %F output of GNU and busybox stat.
+func fileTypeFromStat(t string) string {
+	switch {
+	case strings.Contains(t, "directory"):
+		return "directory"
+	case strings.Contains(t, "symbolic link"):
+		return "symlink"
+	case strings.Contains(t, "regular"):
+		return "file"
+	default:
+		return "other"
+	}
+}
+
+// tarToZip re-encodes a tar stream as a zip archive. Entries with absolute or
+// parent-relative names are rejected so the archive extracts safely.
+func tarToZip(r io.Reader, w io.Writer) error {
+	tr := tar.NewReader(r)
+	zw := zip.NewWriter(w)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if name == "." || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if _, err := zw.CreateHeader(&zip.FileHeader{Name: name + "/", Modified: hdr.ModTime}); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			fh := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: hdr.ModTime}
+			fh.SetMode(hdr.FileInfo().Mode())
+			fw, err := zw.CreateHeader(fh)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(fw, tr); err != nil {
+				return err
+			}
+		}
+	}
+	return zw.Close()
+}
+
+// errTransferLimit is returned by limitedWriter once the size limit is exceeded.
+var errTransferLimit = errors.New("transfer size limit exceeded")
+
+// limitedWriter fails writes once more than remaining bytes have been written.
+type limitedWriter struct {
+	w         io.Writer
+	remaining int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining {
+		return 0, errTransferLimit
+	}
+	n, err := l.w.Write(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// lazyResponseWriter calls start before the first byte is written, so the
+// status and headers can still be chosen after a command fails early.
+type lazyResponseWriter struct {
+	w       http.ResponseWriter
+	start   func(w http.ResponseWriter)
+	started bool
+}
+
+func (l *lazyResponseWriter) Write(p []byte) (int, error) {
+	if !l.started {
+		l.started = true
+		l.start(l.w)
+	}
+	return l.w.Write(p)
+}
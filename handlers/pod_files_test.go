@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// fakeExecutor answers exec requests through a callback keyed on the command line.
+type fakeExecutor struct {
+	command []string
+	run     func(command []string, opts remotecommand.StreamOptions) error
+}
+
+func (f *fakeExecutor) Stream(opts remotecommand.StreamOptions) error {
+	return f.StreamWithContext(context.Background(), opts)
+}
+
+func (f *fakeExecutor) StreamWithContext(_ context.Context, opts remotecommand.StreamOptions) error {
+	return f.run(f.command, opts)
+}
+
+// withExecFakes swaps the exec clientset, REST config and SPDY executor for the duration of the test.
+func withExecFakes(t *testing.T, cs kubernetes.Interface, run func(command []string, opts remotecommand.StreamOptions) error) {
+	t.Helper()
+	oldCS, oldCfg, oldExec := getExecClientset, getExecRESTConfig, newSPDYExecutor
+	getExecClientset = func() (kubernetes.Interface, error) { return cs, nil }
+	getExecRESTConfig = func() (*rest.Config, error) { return &rest.Config{Host: "https://fake"}, nil }
+	newSPDYExecutor = func(_ *rest.Config, _ string, u *url.URL) (remotecommand.Executor, error) {
+		return &fakeExecutor{command: u.Query()["command"], run: run}, nil
+	}
+	t.Cleanup(func() { getExecClientset, getExecRESTConfig, newSPDYExecutor = oldCS, oldCfg, oldExec })
+}
+
+func buildTar(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "logs/", Typeflag: tar.TypeDir, Mode: 0o755}) //nolint:errcheck
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content)) //nolint:errcheck
+	}
+	tw.Close()
+	return buf.Bytes()
+}
+
+func TestParseDirectoryListing(t *testing.T) {
+	out := "directory|4096|1700000000|drwxr-xr-x|etc\n" +
+		"regular file|12|1700000000|-rw-r--r--|a|b.txt\n" +
+		"symbolic link|7|1700000000|lrwxrwxrwx|link\n" +
+		"garbage\n"
+	entries := parseDirectoryListing(out)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Type != "directory" || entries[1].Type != "file" || entries[2].Type != "symlink" {
+		t.Errorf("unexpected types: %+v", entries)
+	}
+	if entries[1].Name != "a|b.txt" || entries[1].Size != 12 {
+		t.Errorf("unexpected file entry: %+v", entries[1])
+	}
+	if entries[0].ModTime != "2023-11-14T22:13:20Z" {
+		t.Errorf("unexpected modTime %q", entries[0].ModTime)
+	}
+}
+
+func TestPodFilesHandler_Validation(t *testing.T) {
+	withExecFakes(t, fake.NewSimpleClientset(newRunningPod("default", "web")), nil)
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		want   int
+	}{
+		{"missing container", http.MethodGet, "/api/pods/files/default/web?path=/tmp", http.StatusBadRequest},
+		{"relative path", http.MethodGet, "/api/pods/files/default/web?container=app&path=tmp", http.StatusBadRequest},
+		{"unknown container", http.MethodGet, "/api/pods/files/default/web?container=nope&path=/tmp", http.StatusBadRequest},
+		{"pod not found", http.MethodGet, "/api/pods/files/default/missing?container=app&path=/tmp", http.StatusNotFound},
+		{"upload requires POST", http.MethodGet, "/api/pods/files/default/web/upload?container=app&path=/tmp", http.StatusMethodNotAllowed},
+		{"download requires GET", http.MethodPost, "/api/pods/files/default/web/download?container=app&path=/tmp", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			PodFilesHandler(w, httptest.NewRequest(tt.method, tt.url, nil))
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPodFilesHandler_ListDirectory(t *testing.T) {
+	var gotCmd []string
+	withExecFakes(t, fake.NewSimpleClientset(newRunningPod("default", "web")),
+		func(cmd []string, opts remotecommand.StreamOptions) error {
+			gotCmd = cmd
+			io.WriteString(opts.Stdout, "regular file|3|1700000000|-rw-r--r--|heap.hprof\n") //nolint:errcheck
+			return nil
+		})
+
+	w := httptest.NewRecorder()
+	PodFilesHandler(w, httptest.NewRequest(http.MethodGet, "/api/pods/files/default/web?container=app&path=/tmp/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(gotCmd) == 0 || gotCmd[len(gotCmd)-1] != "/tmp" {
+		t.Errorf("expected cleaned path as last argument, got %v", gotCmd)
+	}
+
+	var listing DirectoryListing
+	if err := json.NewDecoder(w.Body).Decode(&listing); err != nil {
+		t.Fatal(err)
+	}
+	if listing.Path != "/tmp" || len(listing.Entries) != 1 || listing.Entries[0].Name != "heap.hprof" {
+		t.Errorf("unexpected listing: %+v", listing)
+	}
+}
+
+func TestPodFilesHandler_DownloadTar(t *testing.T) {
+	archive := buildTar(t, map[string]string{"logs/app.log": "hello"})
+	var tarCmd []string
+	withExecFakes(t, fake.NewSimpleClientset(newRunningPod("default", "web")),
+		func(cmd []string, opts remotecommand.StreamOptions) error {
+			if cmd[0] == "du" {
+				io.WriteString(opts.Stdout, "4\t/var/logs\n") //nolint:errcheck
+				return nil
+			}
+			tarCmd = cmd
+			opts.Stdout.Write(archive) //nolint:errcheck
+			return nil
+		})
+
+	w := httptest.NewRecorder()
+	PodFilesHandler(w, httptest.NewRequest(http.MethodGet,
+		"/api/pods/files/default/web/download?container=app&path=/var/logs", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := strings.Join(tarCmd, " "); got != "tar cf - -C /var -- logs" {
+		t.Errorf("unexpected tar command %q", got)
+	}
+	if w.Header().Get("Content-Type") != "application/x-tar" {
+		t.Errorf("unexpected content type %q", w.Header().Get("Content-Type"))
+	}
+	if !bytes.Equal(w.Body.Bytes(), archive) {
+		t.Error("tar body was not streamed through unchanged")
+	}
+}
+
+func TestPodFilesHandler_DownloadDashPrefixedName(t *testing.T) {
+	archive := buildTar(t, map[string]string{"--checkpoint-action=exec=sh": "x"})
+	var tarCmd []string
+	withExecFakes(t, fake.NewSimpleClientset(newRunningPod("default", "web")),
+		func(cmd []string, opts remotecommand.StreamOptions) error {
+			if cmd[0] == "du" {
+				return fmt.Errorf("du missing")
+			}
+			tarCmd = cmd
+			opts.Stdout.Write(archive) //nolint:errcheck
+			return nil
+		})
+
+	w := httptest.NewRecorder()
+	PodFilesHandler(w, httptest.NewRequest(http.MethodGet,
+		"/api/pods/files/default/web/download?container=app&path=/tmp/--checkpoint-action=exec=sh", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	want := []string{"tar", "cf", "-", "-C", "/tmp", "--", "--checkpoint-action=exec=sh"}
+	if strings.Join(tarCmd, "\x00") != strings.Join(want, "\x00") {
+		t.Errorf("tar command = %q, want %q", tarCmd, want)
+	}
+}
+
+func TestPodFilesHandler_DownloadZip(t *testing.T) {
+	archive := buildTar(t, map[string]string{"logs/app.log": "hello", "../escape": "x"})
+	withExecFakes(t, fake.NewSimpleClientset(newRunningPod("default", "web")),
+		func(cmd []string, opts remotecommand.StreamOptions) error {
+			if cmd[0] == "du" {
+				return fmt.Errorf("du missing")
+			}
+			opts.Stdout.Write(archive) //nolint:errcheck
+			return nil
+		})
+
+	w := httptest.NewRecorder()
+	PodFilesHandler(w, httptest.NewRequest(http.MethodGet,
+		"/api/pods/files/default/web/download?container=app&path=/var/logs&format=zip", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	names := make([]string, 0, len(zr.File))
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if strings.Join(names, ",") != "logs/,logs/app.log" {
+		t.Errorf("unexpected zip entries %v", names)
+	}
+}
+
+func TestPodFilesHandler_DownloadErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		err    error
+		limit  int64
+		want   int
+	}{
+		{"tar missing", "", fmt.Errorf(`exec: "tar": executable file not found in $PATH`), 0, http.StatusBadRequest},
+		{"tar exit 127", "sh: tar: not found", utilexec.CodeExitError{Err: fmt.Errorf("exit"), Code: 127}, 0, http.StatusBadRequest},
+		{"missing path", "tar: /nope: No such file or directory", utilexec.CodeExitError{Err: fmt.Errorf("exit"), Code: 2}, 0, http.StatusNotFound},
+		{"size limit", "", nil, 1, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.limit > 0 {
+				old := maxDownloadBytes
+				maxDownloadBytes = tt.limit
+				t.Cleanup(func() { maxDownloadBytes = old })
+			}
+			withExecFakes(t, fake.NewSimpleClientset(newRunningPod("default", "web")),
+				func(cmd []string, opts remotecommand.StreamOptions) error {
+					if cmd[0] == "du" {
+						return fmt.Errorf("du missing")
+					}
+					if tt.err != nil {
+						io.WriteString(opts.Stderr, tt.stderr) //nolint:errcheck
+						return tt.err
+					}
+					_, err := opts.Stdout.Write([]byte("more than one byte"))
+					return err
+				})
+
+			w := httptest.NewRecorder()
+			PodFilesHandler(w, httptest.NewRequest(http.MethodGet,
+				"/api/pods/files/default/web/download?container=app&path=/nope", nil))
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("expected JSON error, got content type %q", ct)
+			}
+		})
+	}
+}
+
+func TestPodFilesHandler_Upload(t *testing.T) {
+	var gotCmd []string
+	var extracted map[string]string
+	withExecFakes(t, fake.NewSimpleClientset(newRunningPod("default", "web")),
+		func(cmd []string, opts remotecommand.StreamOptions) error {
+			gotCmd = cmd
+			extracted = map[string]string{}
+			tr := tar.NewReader(opts.Stdin)
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				data, _ := io.ReadAll(tr)
+				extracted[hdr.Name] = string(data)
+			}
+		})
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("file", "../../app.conf")
+	io.WriteString(fw, "debug=true") //nolint:errcheck
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/pods/files/default/web/upload?container=app&path=/etc/app", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	PodFilesHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Join(gotCmd, " ") != "tar xmf - -C /etc/app" {
+		t.Errorf("unexpected command %v", gotCmd)
+	}
+	if extracted["app.conf"] != "debug=true" || len(extracted) != 1 {
+		t.Errorf("unexpected extracted files %v", extracted)
+	}
+}
+
+func TestPodFilesHandler_UploadTooLarge(t *testing.T) {
+	old := maxUploadBytes
+	maxUploadBytes = 4
+	t.Cleanup(func() { maxUploadBytes = old })
+	withExecFakes(t, fake.NewSimpleClientset(newRunningPod("default", "web")), nil)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("file", "big.bin")
+	io.WriteString(fw, "0123456789") //nolint:errcheck
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/pods/files/default/web/upload?container=app&path=/tmp", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	PodFilesHandler(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	metricsapi "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// ResourceAmounts holds usage, requests and limits for one resource.
+// CPU is expressed in millicores and memory in bytes. Limit is 0 when at least
+// one container is unbounded, since the pod as a whole then has no limit.
+type ResourceAmounts struct {
+	Usage   int64 `json:"usage"`
+	Request int64 `json:"request"`
+	Limit   int64 `json:"limit"`
+}
+
+// ContainerResources holds CPU and memory figures for a single container.
+type ContainerResources struct {
+	Name   string          `json:"name"`
+	CPU    ResourceAmounts `json:"cpu"`
+	Memory ResourceAmounts `json:"memory"`
+}
+
+// PodResources holds CPU and memory figures for a pod and its containers.
+// MetricsAvailable is false when metrics-server had no sample for the pod,
+// in which case only requests and limits are meaningful.
+type PodResources struct {
+	MetricsAvailable bool                 `json:"metricsAvailable"`
+	CPU              ResourceAmounts      `json:"cpu"`
+	Memory           ResourceAmounts      `json:"memory"`
+	Containers       []ContainerResources `json:"containers"`
+}
+
+// TopPodInfo is a row of the "top pods" view.
+type TopPodInfo struct {
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace"`
+	Node      string          `json:"node"`
+	CPU       ResourceAmounts `json:"cpu"`
+	Memory    ResourceAmounts `json:"memory"`
+	// CPULimitRatio and MemoryLimitRatio are usage divided by limit (1.0 = at the limit);
+	// they are omitted when the pod has no limit for that resource.
+	CPULimitRatio    *float64 `json:"cpuLimitRatio,omitempty"`
+	MemoryLimitRatio *float64 `json:"memoryLimitRatio,omitempty"`
+}
+
+// Sort keys accepted by the top pods endpoint.
+const (
+	topSortCPU              = "cpu"
+	topSortMemory           = "memory"
+	topSortCPULimitRatio    = "cpuLimitRatio"
+	topSortMemoryLimitRatio = "memoryLimitRatio"
+)
+
+// podMetricsUsage maps container name to its CPU (millicores) and memory (bytes) usage.
+type podMetricsUsage map[string]nodeMetricsUsage
+
+// getPodMetricsClient returns the metrics client used for pod usage, or nil when
+// metrics-server is unavailable. Tests may override this to inject a fake client.
+var getPodMetricsClient = func() metricsv.Interface {
+	mc := getMetricsClientSafe()
+	if mc == nil {
+		return nil
+	}
+	return mc
+}
+
+// getPodMetricsClientset is a package-level variable for obtaining the Kubernetes client
+// used by the pod detail and top pods endpoints. Tests may override this.
+var getPodMetricsClientset func() (kubernetes.Interface, error) = func() (kubernetes.Interface, error) {
+	return getKubernetesClient()
+}
+
+// fetchPodMetrics queries metrics-server for pod usage in the namespace (all namespaces when empty).
+// Returns a map keyed by "namespace/name", or nil if metrics-server is unavailable.
+func fetchPodMetrics(ctx context.Context, metricsClient metricsv.Interface, namespace string) map[string]podMetricsUsage {
+	if metricsClient == nil {
+		return nil
+	}
+
+	podMetricsList, err := metricsClient.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Warn("metrics-server unavailable, pod usage omitted", "error", err)
+		return nil
+	}
+
+	result := make(map[string]podMetricsUsage, len(podMetricsList.Items))
+	for _, pm := range podMetricsList.Items {
+		result[pm.Namespace+"/"+pm.Name] = containerMetricsUsage(pm.Containers)
+	}
+	return result
+}
+
+// containerMetricsUsage converts a metrics-server container sample list into podMetricsUsage.
+func containerMetricsUsage(containers []metricsapi.ContainerMetrics) podMetricsUsage {
+	usage := make(podMetricsUsage, len(containers))
+	for _, c := range containers {
+		cpu := c.Usage[corev1.ResourceCPU]
+		mem := c.Usage[corev1.ResourceMemory]
+		usage[c.Name] = nodeMetricsUsage{cpuMillis: cpu.MilliValue(), memoryBytes: mem.Value()}
+	}
+	return usage
+}
+
+// buildPodResources combines the pod's container requests and limits with its
+// metrics-server usage. usage may be nil when no sample is available.
+func buildPodResources(pod corev1.Pod, usage podMetricsUsage) PodResources {
+	res := PodResources{
+		MetricsAvailable: usage != nil,
+		Containers:       make([]ContainerResources, 0, len(pod.Spec.Containers)),
+	}
+
+	cpuUnbounded, memUnbounded := false, false
+	for _, c := range pod.Spec.Containers {
+		cr := ContainerResources{Name: c.Name}
+		cr.CPU.Request = c.Resources.Requests.Cpu().MilliValue()
+		cr.CPU.Limit = c.Resources.Limits.Cpu().MilliValue()
+		cr.Memory.Request = c.Resources.Requests.Memory().Value()
+		cr.Memory.Limit = c.Resources.Limits.Memory().Value()
+		if u, ok := usage[c.Name]; ok {
+			cr.CPU.Usage = u.cpuMillis
+			cr.Memory.Usage = u.memoryBytes
+		}
+
+		res.CPU.Usage += cr.CPU.Usage
+		res.CPU.Request += cr.CPU.Request
+		res.CPU.Limit += cr.CPU.Limit
+		res.Memory.Usage += cr.Memory.Usage
+		res.Memory.Request += cr.Memory.Request
+		res.Memory.Limit += cr.Memory.Limit
+		cpuUnbounded = cpuUnbounded || cr.CPU.Limit == 0
+		memUnbounded = memUnbounded || cr.Memory.Limit == 0
+
+		res.Containers = append(res.Containers, cr)
+	}
+
+	// Init containers run before the app containers, so the effective request is
+	// the larger of their individual requests and the sum of the app containers.
+	for _, c := range pod.Spec.InitContainers {
+		if v := c.Resources.Requests.Cpu().MilliValue(); v > res.CPU.Request {
+			res.CPU.Request = v
+		}
+		if v := c.Resources.Requests.Memory().Value(); v > res.Memory.Request {
+			res.Memory.Request = v
+		}
+	}
+
+	if cpuUnbounded {
+		res.CPU.Limit = 0
+	}
+	if memUnbounded {
+		res.Memory.Limit = 0
+	}
+	return res
+}
+
+// limitRatio returns usage/limit, or nil when there is no limit.
+func limitRatio(a ResourceAmounts) *float64 {
+	if a.Limit <= 0 {
+		return nil
+	}
+	ratio := float64(a.Usage) / float64(a.Limit)
+	return &ratio
+}
+
+// PodDetailHandler handles GET /api/pods/detail/{namespace}/{name}.
+// It returns the pod summary together with per-container usage, requests and limits.
+func PodDetailHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, err := parseResourcePath(r.URL.Path, podDetailPathPrefix, "")
+	if err != nil {
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("Invalid path format. Expected %s{namespace}/{name}", podDetailPathPrefix))
+		return
+	}
+
+	clientset, err := getPodMetricsClientset()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(r.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		writeResourceError(w, err, errMsgPodNotFound, errMsgPodDetailFetch)
+		return
+	}
+
+	var usage podMetricsUsage
+	if mc := getPodMetricsClient(); mc != nil {
+		pm, err := mc.MetricsV1beta1().PodMetricses(namespace).Get(r.Context(), name, metav1.GetOptions{})
+		if err != nil {
+			slog.Warn("pod metrics unavailable", "error", err, "namespace", namespace, "name", name)
+		} else {
+			usage = containerMetricsUsage(pm.Containers)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, buildPodDetails(*pod, usage))
+}
+
+// TopPodsHandler handles GET /api/pods/top?ns=...&sortBy=...&limit=N.
+// sortBy is one of cpu (default), memory, cpuLimitRatio or memoryLimitRatio;
+// pods without a limit sort after those with one when ordering by ratio.
+func TopPodsHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	r = withTimeout(r)
+
+	query := r.URL.Query()
+	sortBy := query.Get("sortBy")
+	if sortBy == "" {
+		sortBy = topSortCPU
+	}
+	switch sortBy {
+	case topSortCPU, topSortMemory, topSortCPULimitRatio, topSortMemoryLimitRatio:
+	default:
+		writeError(w, http.StatusBadRequest, errMsgTopSortInvalid)
+		return
+	}
+
+	limit := 0
+	if l := query.Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 0 {
+			writeError(w, http.StatusBadRequest, errMsgTopLimitInvalid)
+			return
+		}
+		limit = parsed
+	}
+
+	clientset, err := getPodMetricsClientset()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	metricsMap := fetchPodMetrics(r.Context(), getPodMetricsClient(), query.Get("ns"))
+	if metricsMap == nil {
+		writeError(w, http.StatusServiceUnavailable, errMsgMetricsUnavailable)
+		return
+	}
+
+	top, err := getTopPods(r.Context(), clientset, metricsMap, query.Get("ns"), sortBy, limit)
+	if err != nil {
+		slog.Error("Failed to compute top pods", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgTopPods)
+		return
+	}
+	writeJSON(w, http.StatusOK, top)
+}
+
+// getTopPods ranks pods with metrics samples by the chosen sort key, highest first.
+func getTopPods(ctx context.Context, clientset kubernetes.Interface, metricsMap map[string]podMetricsUsage, namespace, sortBy string, limit int) ([]TopPodInfo, error) {
+	podList, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	top := make([]TopPodInfo, 0, len(metricsMap))
+	for _, pod := range podList.Items {
+		usage, ok := metricsMap[pod.Namespace+"/"+pod.Name]
+		if !ok {
+			continue
+		}
+		res := buildPodResources(pod, usage)
+		top = append(top, TopPodInfo{
+			Name:             pod.Name,
+			Namespace:        pod.Namespace,
+			Node:             pod.Spec.NodeName,
+			CPU:              res.CPU,
+			Memory:           res.Memory,
+			CPULimitRatio:    limitRatio(res.CPU),
+			MemoryLimitRatio: limitRatio(res.Memory),
+		})
+	}
+
+	key := func(p TopPodInfo) (float64, bool) {
+		switch sortBy {
+		case topSortMemory:
+			return float64(p.Memory.Usage), true
+		case topSortCPULimitRatio:
+			if p.CPULimitRatio == nil {
+				return 0, false
+			}
+			return *p.CPULimitRatio, true
+		case topSortMemoryLimitRatio:
+			if p.MemoryLimitRatio == nil {
+				return 0, false
+			}
+			return *p.MemoryLimitRatio, true
+		default:
+			return float64(p.CPU.Usage), true
+		}
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		ki, oki := key(top[i])
+		kj, okj := key(top[j])
+		if oki != okj {
+			return oki
+		}
+		if ki != kj {
+			return ki > kj
+		}
+		return top[i].Namespace+"/"+top[i].Name < top[j].Namespace+"/"+top[j].Name
+	})
+
+	if limit > 0 && len(top) > limit {
+		top = top[:limit]
+	}
+	return top, nil
+}
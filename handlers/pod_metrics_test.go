@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	metricsapi "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// withPodMetricsFakes injects fake Kubernetes and metrics clients. The metrics
+// fake serves the given samples from a reactor, since its tracker does not map
+// PodMetrics objects to the "pods" resource the typed client requests.
+func withPodMetricsFakes(t *testing.T, cs kubernetes.Interface, samples ...metricsapi.PodMetrics) {
+	t.Helper()
+	mc := metricsfake.NewSimpleClientset()
+	mc.PrependReactor("list", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		ns := action.GetNamespace()
+		list := &metricsapi.PodMetricsList{}
+		for _, s := range samples {
+			if ns == "" || s.Namespace == ns {
+				list.Items = append(list.Items, s)
+			}
+		}
+		return true, list, nil
+	})
+	mc.PrependReactor("get", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		get := action.(clienttesting.GetAction)
+		for i := range samples {
+			if samples[i].Namespace == get.GetNamespace() && samples[i].Name == get.GetName() {
+				return true, &samples[i], nil
+			}
+		}
+		return true, nil, &metricsNotFound{}
+	})
+
+	oldCS, oldMC := getPodMetricsClientset, getPodMetricsClient
+	getPodMetricsClientset = func() (kubernetes.Interface, error) { return cs, nil }
+	getPodMetricsClient = func() metricsv.Interface { return mc }
+	t.Cleanup(func() {
+		getPodMetricsClientset = oldCS
+		getPodMetricsClient = oldMC
+	})
+}
+
+type metricsNotFound struct{}
+
+func (metricsNotFound) Error() string { return "not found" }
+
+func newPodSample(namespace, name string, containers map[string][2]string) metricsapi.PodMetrics {
+	pm := metricsapi.PodMetrics{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	for c, u := range containers {
+		pm.Containers = append(pm.Containers, metricsapi.ContainerMetrics{
+			Name: c,
+			Usage: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(u[0]),
+				corev1.ResourceMemory: resource.MustParse(u[1]),
+			},
+		})
+	}
+	return pm
+}
+
+func newPodWithResources(namespace, name string, containers ...corev1.Container) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       corev1.PodSpec{NodeName: "node-1", Containers: containers},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func resourceContainer(name, cpuReq, cpuLim, memReq, memLim string) corev1.Container {
+	c := corev1.Container{Name: name, Resources: corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{},
+		Limits:   corev1.ResourceList{},
+	}}
+	set := func(l corev1.ResourceList, k corev1.ResourceName, v string) {
+		if v != "" {
+			l[k] = resource.MustParse(v)
+		}
+	}
+	set(c.Resources.Requests, corev1.ResourceCPU, cpuReq)
+	set(c.Resources.Limits, corev1.ResourceCPU, cpuLim)
+	set(c.Resources.Requests, corev1.ResourceMemory, memReq)
+	set(c.Resources.Limits, corev1.ResourceMemory, memLim)
+	return c
+}
+
+func TestBuildPodResources(t *testing.T) {
+	pod := newPodWithResources("default", "web",
+		resourceContainer("app", "200m", "500m", "128Mi", "256Mi"),
+		resourceContainer("sidecar", "50m", "", "32Mi", "64Mi"),
+	)
+	pod.Spec.InitContainers = []corev1.Container{resourceContainer("migrate", "1", "", "64Mi", "")}
+	usage := podMetricsUsage{
+		"app":     {cpuMillis: 150, memoryBytes: 100 << 20},
+		"sidecar": {cpuMillis: 10, memoryBytes: 20 << 20},
+	}
+
+	res := buildPodResources(*pod, usage)
+	if !res.MetricsAvailable || len(res.Containers) != 2 {
+		t.Fatalf("unexpected %+v", res)
+	}
+	if res.CPU.Usage != 160 || res.Memory.Usage != 120<<20 {
+		t.Errorf("usage = %d cpu, %d mem", res.CPU.Usage, res.Memory.Usage)
+	}
+	if res.CPU.Request != 1000 {
+		t.Errorf("init container request should dominate, got %d", res.CPU.Request)
+	}
+	if res.Memory.Request != 160<<20 {
+		t.Errorf("memory request = %d", res.Memory.Request)
+	}
+	if res.CPU.Limit != 0 {
+		t.Errorf("cpu limit should be unbounded when a container has none, got %d", res.CPU.Limit)
+	}
+	if res.Memory.Limit != 320<<20 {
+		t.Errorf("memory limit = %d", res.Memory.Limit)
+	}
+	if app := res.Containers[0]; app.CPU.Usage != 150 || app.CPU.Limit != 500 {
+		t.Errorf("app container = %+v", app)
+	}
+
+	if res := buildPodResources(*pod, nil); res.MetricsAvailable || res.CPU.Usage != 0 {
+		t.Errorf("no metrics: unexpected %+v", res)
+	}
+}
+
+func TestTopPodsHandler(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		newPodWithResources("default", "busy", resourceContainer("app", "", "2", "", "1Gi")),
+		newPodWithResources("default", "throttled", resourceContainer("app", "", "100m", "", "")),
+		newPodWithResources("kube-system", "dns", resourceContainer("app", "", "", "", "")),
+		newPodWithResources("default", "no-sample", resourceContainer("app", "", "", "", "")),
+	)
+	withPodMetricsFakes(t, cs,
+		newPodSample("default", "busy", map[string][2]string{"app": {"800m", "512Mi"}}),
+		newPodSample("default", "throttled", map[string][2]string{"app": {"95m", "10Mi"}}),
+		newPodSample("kube-system", "dns", map[string][2]string{"app": {"300m", "900Mi"}}),
+	)
+
+	get := func(query string) []TopPodInfo {
+		t.Helper()
+		w := httptest.NewRecorder()
+		TopPodsHandler(w, httptest.NewRequest(http.MethodGet, "/api/pods/top"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", query, w.Code, w.Body.String())
+		}
+		var top []TopPodInfo
+		if err := json.NewDecoder(w.Body).Decode(&top); err != nil {
+			t.Fatal(err)
+		}
+		return top
+	}
+	names := func(top []TopPodInfo) string {
+		s := ""
+		for _, p := range top {
+			s += p.Name + ","
+		}
+		return s
+	}
+
+	if got := names(get("")); got != "busy,dns,throttled," {
+		t.Errorf("by cpu: %s", got)
+	}
+	if got := names(get("?sortBy=memory&limit=1")); got != "dns," {
+		t.Errorf("by memory with limit: %s", got)
+	}
+	if got := names(get("?sortBy=cpuLimitRatio")); got != "throttled,busy,dns," {
+		t.Errorf("by cpu ratio, unlimited last: %s", got)
+	}
+	if got := names(get("?ns=default")); got != "busy,throttled," {
+		t.Errorf("namespace filter: %s", got)
+	}
+
+	for _, query := range []string{"?sortBy=disk", "?limit=-1"} {
+		w := httptest.NewRecorder()
+		TopPodsHandler(w, httptest.NewRequest(http.MethodGet, "/api/pods/top"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestTopPodsHandler_MetricsUnavailable(t *testing.T) {
+	withPodMetricsFakes(t, fake.NewSimpleClientset())
+	old := getPodMetricsClient
+	getPodMetricsClient = func() metricsv.Interface { return nil }
+	t.Cleanup(func() { getPodMetricsClient = old })
+
+	w := httptest.NewRecorder()
+	TopPodsHandler(w, httptest.NewRequest(http.MethodGet, "/api/pods/top", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", w.Code)
+	}
+}
+
+func TestPodDetailHandler(t *testing.T) {
+	cs := fake.NewSimpleClientset(newPodWithResources("default", "web", resourceContainer("app", "100m", "", "", "")))
+	withPodMetricsFakes(t, cs, newPodSample("default", "web", map[string][2]string{"app": {"42m", "10Mi"}}))
+
+	w := httptest.NewRecorder()
+	PodDetailHandler(w, httptest.NewRequest(http.MethodGet, "/api/pods/detail/default/web", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var detail PodDetails
+	if err := json.NewDecoder(w.Body).Decode(&detail); err != nil {
+		t.Fatal(err)
+	}
+	if !detail.Resources.MetricsAvailable || detail.Resources.CPU.Usage != 42 || detail.Resources.CPU.Request != 100 {
+		t.Errorf("unexpected resources %+v", detail.Resources)
+	}
+
+	w = httptest.NewRecorder()
+	PodDetailHandler(w, httptest.NewRequest(http.MethodGet, "/api/pods/detail/default/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
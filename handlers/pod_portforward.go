@@ -0,0 +1,571 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// proxyContentSecurityPolicy sandboxes proxied responses. allow-same-origin is
+// deliberately absent, so proxied pages cannot read the dashboard's storage or
+// call its API with the user's credentials.
+const proxyContentSecurityPolicy = "sandbox allow-scripts allow-forms allow-popups allow-modals allow-downloads"
+
+// maxCloseReasonLength is the longest reason a WebSocket close frame can carry.
+const maxCloseReasonLength = 123
+
+// portForwardTarget identifies the pod and container port a tunnel should be opened to.
+type portForwardTarget struct {
+	namespace string
+	pod       string
+	port      int32
+}
+
+// getPortForwardClientset returns the Kubernetes client used by the port-forward handlers.
+// Tests may override this to inject a fake clientset.
+var getPortForwardClientset func() (kubernetes.Interface, error) = func() (kubernetes.Interface, error) {
+	return getKubernetesClient()
+}
+
+// newPortForwardDialer is a package-level variable for creating the SPDY dialer
+// used to reach the pods/portforward subresource. Tests may override this.
+var newPortForwardDialer = func(config *rest.Config, u *url.URL) (httpstream.Dialer, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, err
+	}
+	return spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, u), nil
+}
+
+// portForwardUpgrader upgrades port-forward sockets. Unlike exec, a tunnel
+// reaches arbitrary pod ports, so cross-site pages must not be able to open one.
+var portForwardUpgrader = websocket.Upgrader{
+	ReadBufferSize:  8192,
+	WriteBufferSize: 8192,
+	CheckOrigin:     sameOriginOrLoopback,
+}
+
+// sameOriginOrLoopback accepts requests without an Origin header (non-browser
+// clients), from the dashboard's own origin, and from a loopback origin when the
+// dashboard itself is reached over loopback, as with the Vite dev proxy.
+func sameOriginOrLoopback(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return isLoopbackHost(u.Hostname()) && isLoopbackHost(hostWithoutPort(r.Host))
+}
+
+func hostWithoutPort(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return hostport
+}
+
+// proxyRequestAllowed guards the HTTP proxy against cross-site request forgery:
+// state-changing requests must pass sameOriginOrLoopback. Proxied pages are
+// sandboxed into an opaque origin and send "Origin: null", so that is accepted
+// only from a page served under the same proxy base path of this host.
+func proxyRequestAllowed(r *http.Request, basePath string) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	if r.Header.Get("Origin") != "null" {
+		return sameOriginOrLoopback(r)
+	}
+	referer, err := url.Parse(r.Referer())
+	if err != nil || !strings.EqualFold(referer.Host, r.Host) {
+		return false
+	}
+	return referer.Path == basePath || strings.HasPrefix(referer.Path, basePath+"/")
+}
+
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// PodPortForwardHandler handles GET /api/pods/portforward/{namespace}/{name}?port=...
+// It upgrades the connection to WebSocket and relays binary frames to and from the pod port.
+func PodPortForwardHandler(w http.ResponseWriter, r *http.Request) {
+	handlePortForward(w, r, podPortForwardPathPrefix, resolvePodTarget)
+}
+
+// ServicePortForwardHandler handles GET /api/services/portforward/{namespace}/{name}?port=...
+// The service port is resolved to a ready backing pod, the same way kubectl port-forward svc/... does.
+func ServicePortForwardHandler(w http.ResponseWriter, r *http.Request) {
+	handlePortForward(w, r, servicePortForwardPathPrefix, resolveServiceTarget)
+}
+
+// PodProxyHandler handles /api/pods/proxy/{namespace}/{name}/{port}/{path...}.
+// Requests are reverse-proxied over a port-forward tunnel to the pod's HTTP port.
+func PodProxyHandler(w http.ResponseWriter, r *http.Request) {
+	handlePortForwardProxy(w, r, podProxyPathPrefix, resolvePodTarget)
+}
+
+// ServiceProxyHandler handles /api/services/proxy/{namespace}/{name}/{port}/{path...}.
+func ServiceProxyHandler(w http.ResponseWriter, r *http.Request) {
+	handlePortForwardProxy(w, r, serviceProxyPathPrefix, resolveServiceTarget)
+}
+
+// targetResolver maps a namespace, resource name and requested port onto a concrete pod port.
+type targetResolver func(ctx context.Context, clientset kubernetes.Interface, namespace, name, port string) (*portForwardTarget, error)
+
+func handlePortForward(w http.ResponseWriter, r *http.Request, pathPrefix string, resolve targetResolver) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	namespace, name, err := parseResourcePath(r.URL.Path, pathPrefix, "")
+	if err != nil {
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("Invalid path format. Expected %s{namespace}/{name}", pathPrefix))
+		return
+	}
+
+	port := r.URL.Query().Get("port")
+	if port == "" {
+		writeError(w, http.StatusBadRequest, errMsgPortRequired)
+		return
+	}
+
+	// Reject cross-origin sockets before a tunnel is opened.
+	if !portForwardUpgrader.CheckOrigin(r) {
+		writeError(w, http.StatusForbidden, errMsgOriginForbidden)
+		return
+	}
+
+	clientset, err := getPortForwardClientset()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	target, err := resolve(r.Context(), clientset, namespace, name, port)
+	if err != nil {
+		writePortForwardError(w, err)
+		return
+	}
+
+	config, err := getExecRESTConfig()
+	if err != nil {
+		slog.Error("Failed to get REST config", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	tunnel, err := openPortForwardTunnel(config, target)
+	if err != nil {
+		slog.Error("Failed to open port-forward tunnel", "error", err,
+			"namespace", target.namespace, "pod", target.pod, "port", target.port)
+		writeError(w, http.StatusBadGateway, errMsgPortForwardFailed)
+		return
+	}
+	defer tunnel.Close()
+
+	wsConn, err := portForwardUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		slog.Error("WebSocket upgrade failed", "error", err)
+		// Upgrade already wrote the HTTP error response.
+		return
+	}
+	defer wsConn.Close()
+
+	// WebSocket -> pod. Closing the tunnel unblocks the copy loop below.
+	go func() {
+		defer tunnel.Close()
+		for {
+			msgType, data, err := wsConn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if msgType != websocket.BinaryMessage && msgType != websocket.TextMessage {
+				continue
+			}
+			if _, err := tunnel.Write(data); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Pod -> WebSocket.
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := tunnel.Read(buf)
+		if n > 0 {
+			if wErr := wsConn.WriteMessage(websocket.BinaryMessage, buf[:n]); wErr != nil {
+				return
+			}
+		}
+		if err != nil {
+			if remoteErr := tunnel.remoteError(); remoteErr != nil {
+				slog.Error("Port-forward ended with error", "error", remoteErr,
+					"namespace", target.namespace, "pod", target.pod, "port", target.port)
+				closePortForwardSocket(wsConn, websocket.CloseInternalServerErr, remoteErr.Error())
+				return
+			}
+			closePortForwardSocket(wsConn, websocket.CloseNormalClosure, "")
+			return
+		}
+	}
+}
+
+// closePortForwardSocket ends a tunnel socket with a close frame instead of an
+// in-band message, since the data frames carry the raw byte stream. The reason
+// is truncated to fit a control frame.
+func closePortForwardSocket(wsConn *websocket.Conn, code int, reason string) {
+	if len(reason) > maxCloseReasonLength {
+		reason = reason[:maxCloseReasonLength]
+	}
+	wsConn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), //nolint:errcheck
+		time.Now().Add(time.Second))
+}
+
+func handlePortForwardProxy(w http.ResponseWriter, r *http.Request, pathPrefix string, resolve targetResolver) {
+	namespace, name, port, upstreamPath, err := parseProxyPath(r.URL.Path, pathPrefix)
+	if err != nil {
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("Invalid path format. Expected %s{namespace}/{name}/{port}/{path}", pathPrefix))
+		return
+	}
+
+	basePath := fmt.Sprintf("%s%s/%s/%s", pathPrefix, namespace, name, port)
+	if !proxyRequestAllowed(r, basePath) {
+		writeError(w, http.StatusForbidden, errMsgOriginForbidden)
+		return
+	}
+
+	clientset, err := getPortForwardClientset()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	target, err := resolve(r.Context(), clientset, namespace, name, port)
+	if err != nil {
+		writePortForwardError(w, err)
+		return
+	}
+
+	config, err := getExecRESTConfig()
+	if err != nil {
+		slog.Error("Failed to get REST config", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	newPortForwardProxy(config, target, basePath, upstreamPath).ServeHTTP(w, r)
+}
+
+// newPortForwardProxy builds a reverse proxy whose transport dials a fresh
+// port-forward tunnel per upstream connection. Redirects to absolute paths are
+// rewritten to stay under basePath so the proxied app remains browsable.
+//
+// Proxied apps are served from the dashboard's origin, so their responses are
+// sandboxed into an opaque origin and may not set cookies: otherwise a pod's
+// page could script the dashboard API or plant cookies on it.
+func newPortForwardProxy(
+	config *rest.Config,
+	target *portForwardTarget,
+	basePath, upstreamPath string,
+) *httputil.ReverseProxy {
+	upstreamHost := fmt.Sprintf("%s.%s:%d", target.pod, target.namespace, target.port)
+	return &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = "http"
+			req.URL.Host = upstreamHost
+			req.URL.Path = upstreamPath
+			req.URL.RawPath = ""
+			req.Host = upstreamHost
+			req.Header.Set("X-Forwarded-Prefix", basePath)
+		},
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return openPortForwardTunnel(config, target)
+			},
+			DisableKeepAlives:     true,
+			ResponseHeaderTimeout: apiTimeout,
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			resp.Header.Del("Set-Cookie")
+			resp.Header.Set("Content-Security-Policy", proxyContentSecurityPolicy)
+			if loc := resp.Header.Get("Location"); strings.HasPrefix(loc, "/") && !strings.HasPrefix(loc, "//") {
+				resp.Header.Set("Location", basePath+loc)
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			slog.Error("Port-forward proxy request failed", "error", err,
+				"namespace", target.namespace, "pod", target.pod, "port", target.port)
+			writeError(w, http.StatusBadGateway, errMsgPortForwardFailed)
+		},
+	}
+}
+
+// parseProxyPath splits /prefix/{namespace}/{name}/{port}/{path...} into its parts.
+// The returned upstream path always begins with "/".
+func parseProxyPath(urlPath, prefix string) (namespace, name, port, upstreamPath string, err error) {
+	parts := strings.SplitN(strings.TrimPrefix(urlPath, prefix), "/", 4)
+	if len(parts) < 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", "", fmt.Errorf("invalid path format, expected %s{namespace}/{name}/{port}/{path}", prefix)
+	}
+	upstreamPath = "/"
+	if len(parts) == 4 {
+		upstreamPath += parts[3]
+	}
+	return parts[0], parts[1], parts[2], upstreamPath, nil
+}
+
+// writePortForwardError maps a resolver error onto an HTTP response.
+func writePortForwardError(w http.ResponseWriter, err error) {
+	var sErr *statusError
+	if errors.As(err, &sErr) {
+		writeError(w, sErr.status, sErr.message)
+		return
+	}
+	writeResourceError(w, err, errMsgPodNotFound, errMsgPortForwardFailed)
+}
+
+// resolvePodTarget validates that the pod is running and resolves a numeric or
+// named container port.
+func resolvePodTarget(ctx context.Context, clientset kubernetes.Interface, namespace, name, port string) (*portForwardTarget, error) {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return nil, &statusError{status: http.StatusBadRequest, message: errMsgPodNotRunning}
+	}
+
+	portNum, err := resolveContainerPort(pod, port)
+	if err != nil {
+		return nil, err
+	}
+	return &portForwardTarget{namespace: namespace, pod: name, port: portNum}, nil
+}
+
+// resolveServiceTarget picks a ready pod behind the service and translates the
+// service port (by number or name) into that pod's target port.
+func resolveServiceTarget(ctx context.Context, clientset kubernetes.Interface, namespace, name, port string) (*portForwardTarget, error) {
+	svc, err := clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, &statusError{status: http.StatusNotFound, message: errMsgServiceNotFound}
+		}
+		return nil, err
+	}
+
+	var svcPort *corev1.ServicePort
+	for i := range svc.Spec.Ports {
+		p := &svc.Spec.Ports[i]
+		if p.Name == port || strconv.Itoa(int(p.Port)) == port {
+			svcPort = p
+			break
+		}
+	}
+	if svcPort == nil {
+		return nil, &statusError{status: http.StatusBadRequest, message: errMsgServicePortNotFound}
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return nil, &statusError{status: http.StatusBadRequest, message: errMsgServiceNoReadyPods}
+	}
+
+	podList, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil || !isPodReady(pod) {
+			continue
+		}
+
+		targetPort := svcPort.TargetPort.String()
+		if svcPort.TargetPort.Type == intstr.Int && svcPort.TargetPort.IntVal == 0 {
+			targetPort = strconv.Itoa(int(svcPort.Port))
+		}
+		portNum, err := resolveContainerPort(pod, targetPort)
+		if err != nil {
+			continue
+		}
+		return &portForwardTarget{namespace: namespace, pod: pod.Name, port: portNum}, nil
+	}
+
+	return nil, &statusError{status: http.StatusServiceUnavailable, message: errMsgServiceNoReadyPods}
+}
+
+// resolveContainerPort returns port as a number, or looks it up by name among the pod's container ports.
+func resolveContainerPort(pod *corev1.Pod, port string) (int32, error) {
+	if n, err := strconv.Atoi(port); err == nil {
+		if n < 1 || n > 65535 {
+			return 0, &statusError{status: http.StatusBadRequest, message: errMsgPortInvalid}
+		}
+		return int32(n), nil
+	}
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			if p.Name == port {
+				return p.ContainerPort, nil
+			}
+		}
+	}
+	return 0, &statusError{status: http.StatusBadRequest, message: errMsgPortInvalid}
+}
+
+// isPodReady reports whether the pod's Ready condition is True.
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// portForwardRequestID numbers the stream pairs created on each SPDY connection.
+var portForwardRequestID atomic.Int64
+
+// portForwardTunnel is a single forwarded TCP connection carried over SPDY.
+// It implements net.Conn so it can back an http.Transport as well as a WebSocket relay.
+type portForwardTunnel struct {
+	conn       httpstream.Connection
+	data       httpstream.Stream
+	errCh      chan error
+	closeOnce  sync.Once
+	remoteErr  error
+	remoteOnce sync.Once
+	target     *portForwardTarget
+}
+
+// openPortForwardTunnel dials the pods/portforward subresource and creates the
+// error and data stream pair for a single forwarded connection.
+func openPortForwardTunnel(config *rest.Config, target *portForwardTarget) (*portForwardTunnel, error) {
+	pfURL, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, err
+	}
+	pfURL.Path = path.Join(pfURL.Path, "api", "v1", "namespaces", target.namespace,
+		"pods", target.pod, "portforward")
+
+	dialer, err := newPortForwardDialer(config, pfURL)
+	if err != nil {
+		return nil, err
+	}
+	conn, _, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	headers.Set(corev1.StreamType, corev1.StreamTypeError)
+	headers.Set(corev1.PortHeader, strconv.Itoa(int(target.port)))
+	headers.Set(corev1.PortForwardRequestIDHeader, strconv.FormatInt(portForwardRequestID.Add(1), 10))
+	errorStream, err := conn.CreateStream(headers)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// We never write to the error stream.
+	errorStream.Close()
+
+	headers.Set(corev1.StreamType, corev1.StreamTypeData)
+	dataStream, err := conn.CreateStream(headers)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	t := &portForwardTunnel{conn: conn, data: dataStream, errCh: make(chan error, 1), target: target}
+	go func() {
+		message, err := io.ReadAll(errorStream)
+		switch {
+		case err != nil:
+			t.errCh <- err
+		case len(message) > 0:
+			t.errCh <- fmt.Errorf("port %d: %s", target.port, string(message))
+		}
+		close(t.errCh)
+	}()
+	return t, nil
+}
+
+// remoteError returns the error reported by the kubelet on the error stream, if any.
+func (t *portForwardTunnel) remoteError() error {
+	t.remoteOnce.Do(func() {
+		select {
+		case err := <-t.errCh:
+			t.remoteErr = err
+		case <-time.After(time.Second):
+		}
+	})
+	return t.remoteErr
+}
+
+func (t *portForwardTunnel) Read(p []byte) (int, error)  { return t.data.Read(p) }
+func (t *portForwardTunnel) Write(p []byte) (int, error) { return t.data.Write(p) }
+
+// Close tears down the data stream and the underlying SPDY connection.
+func (t *portForwardTunnel) Close() error {
+	t.closeOnce.Do(func() {
+		t.data.Reset() //nolint:errcheck
+		t.conn.Close()
+	})
+	return nil
+}
+
+func (t *portForwardTunnel) LocalAddr() net.Addr { return portForwardAddr("dashboard") }
+func (t *portForwardTunnel) RemoteAddr() net.Addr {
+	return portForwardAddr(fmt.Sprintf("%s/%s:%d", t.target.namespace, t.target.pod, t.target.port))
+}
+
+// Deadlines are not supported by SPDY streams; timeouts are enforced by the caller.
+func (t *portForwardTunnel) SetDeadline(time.Time) error      { return nil }
+func (t *portForwardTunnel) SetReadDeadline(time.Time) error  { return nil }
+func (t *portForwardTunnel) SetWriteDeadline(time.Time) error { return nil }
+
+// portForwardAddr is a net.Addr describing one end of a port-forward tunnel.
+type portForwardAddr string
+
+func (a portForwardAddr) Network() string { return "portforward" }
+func (a portForwardAddr) String() string  { return string(a) }
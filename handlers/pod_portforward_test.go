@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+// fakePFStream adapts a net.Conn to httpstream.Stream.
+type fakePFStream struct {
+	net.Conn
+	headers http.Header
+}
+
+func (s *fakePFStream) Reset() error         { return s.Conn.Close() }
+func (s *fakePFStream) Headers() http.Header { return s.headers }
+func (s *fakePFStream) Identifier() uint32   { return 0 }
+
+// fakePFConnection hands out an immediately-closed error stream and a data
+// stream whose remote end is passed to serve.
+type fakePFConnection struct {
+	serve func(remote net.Conn, headers http.Header)
+	done  chan bool
+}
+
+func (c *fakePFConnection) CreateStream(headers http.Header) (httpstream.Stream, error) {
+	h := headers.Clone()
+	local, remote := net.Pipe()
+	if h.Get(corev1.StreamType) == corev1.StreamTypeError {
+		remote.Close()
+		return &fakePFStream{Conn: local, headers: h}, nil
+	}
+	go c.serve(remote, h)
+	return &fakePFStream{Conn: local, headers: h}, nil
+}
+func (c *fakePFConnection) Close() error                       { return nil }
+func (c *fakePFConnection) CloseChan() <-chan bool             { return c.done }
+func (c *fakePFConnection) SetIdleTimeout(time.Duration)       {}
+func (c *fakePFConnection) RemoveStreams(...httpstream.Stream) {}
+
+type fakePFDialer struct{ conn *fakePFConnection }
+
+func (d *fakePFDialer) Dial(...string) (httpstream.Connection, string, error) {
+	return d.conn, "portforward.k8s.io", nil
+}
+
+// withPortForwardFakes swaps the clientset, REST config and SPDY dialer used by the
+// port-forward handlers. serve receives the pod side of every data stream.
+func withPortForwardFakes(t *testing.T, cs kubernetes.Interface, serve func(remote net.Conn, headers http.Header)) {
+	t.Helper()
+	oldCS, oldCfg, oldDialer := getPortForwardClientset, getExecRESTConfig, newPortForwardDialer
+	getPortForwardClientset = func() (kubernetes.Interface, error) { return cs, nil }
+	getExecRESTConfig = func() (*rest.Config, error) { return &rest.Config{Host: "https://fake"}, nil }
+	newPortForwardDialer = func(*rest.Config, *url.URL) (httpstream.Dialer, error) {
+		return &fakePFDialer{conn: &fakePFConnection{serve: serve, done: make(chan bool)}}, nil
+	}
+	t.Cleanup(func() {
+		getPortForwardClientset, getExecRESTConfig, newPortForwardDialer = oldCS, oldCfg, oldDialer
+	})
+}
+
+func newReadyPod(namespace, name string, lbls map[string]string, ports ...corev1.ContainerPort) *corev1.Pod {
+	pod := newRunningPod(namespace, name)
+	pod.Labels = lbls
+	pod.Spec.Containers[0].Ports = ports
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	return pod
+}
+
+func TestParseProxyPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		wantPath string
+		wantErr  bool
+	}{
+		{"/api/pods/proxy/default/web/8080", "/", false},
+		{"/api/pods/proxy/default/web/8080/", "/", false},
+		{"/api/pods/proxy/default/web/8080/metrics", "/metrics", false},
+		{"/api/pods/proxy/default/web/http/a/b", "/a/b", false},
+		{"/api/pods/proxy/default/web", "", true},
+		{"/api/pods/proxy/default//8080", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, _, _, got, err := parseProxyPath(tt.path, podProxyPathPrefix)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.wantPath {
+				t.Errorf("path = %q, want %q", got, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestResolveContainerPort(t *testing.T) {
+	pod := newReadyPod("default", "web", nil, corev1.ContainerPort{Name: "metrics", ContainerPort: 9090})
+
+	if p, err := resolveContainerPort(pod, "8080"); err != nil || p != 8080 {
+		t.Errorf("numeric port: got %d, %v", p, err)
+	}
+	if p, err := resolveContainerPort(pod, "metrics"); err != nil || p != 9090 {
+		t.Errorf("named port: got %d, %v", p, err)
+	}
+	for _, bad := range []string{"0", "70000", "nope"} {
+		if _, err := resolveContainerPort(pod, bad); err == nil {
+			t.Errorf("expected error for port %q", bad)
+		}
+	}
+}
+
+func TestResolveServiceTarget(t *testing.T) {
+	selector := map[string]string{"app": "web"}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Selector: selector,
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromString("web")},
+				{Name: "admin", Port: 9000},
+			},
+		},
+	}
+	notReady := newRunningPod("default", "web-0")
+	notReady.Labels = selector
+	ready := newReadyPod("default", "web-1", selector, corev1.ContainerPort{Name: "web", ContainerPort: 8080})
+
+	cs := fake.NewSimpleClientset(svc, notReady, ready)
+	ctx := context.Background()
+
+	target, err := resolveServiceTarget(ctx, cs, "default", "web", "http")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.pod != "web-1" || target.port != 8080 {
+		t.Errorf("got %s:%d, want web-1:8080", target.pod, target.port)
+	}
+
+	target, err = resolveServiceTarget(ctx, cs, "default", "web", "9000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.port != 9000 {
+		t.Errorf("unset targetPort should default to port, got %d", target.port)
+	}
+
+	if _, err := resolveServiceTarget(ctx, cs, "default", "web", "443"); err == nil {
+		t.Error("expected error for unknown service port")
+	}
+	if _, err := resolveServiceTarget(ctx, cs, "default", "missing", "80"); err == nil {
+		t.Error("expected error for missing service")
+	}
+}
+
+func TestPodPortForwardHandler_Validation(t *testing.T) {
+	stopped := newRunningPod("default", "stopped")
+	stopped.Status.Phase = corev1.PodPending
+	withPortForwardFakes(t, fake.NewSimpleClientset(newRunningPod("default", "web"), stopped), nil)
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		want   int
+	}{
+		{"rejects POST", http.MethodPost, "/api/pods/portforward/default/web?port=80", http.StatusMethodNotAllowed},
+		{"invalid path", http.MethodGet, "/api/pods/portforward/default?port=80", http.StatusBadRequest},
+		{"missing port", http.MethodGet, "/api/pods/portforward/default/web", http.StatusBadRequest},
+		{"invalid port", http.MethodGet, "/api/pods/portforward/default/web?port=99999", http.StatusBadRequest},
+		{"pod not found", http.MethodGet, "/api/pods/portforward/default/missing?port=80", http.StatusNotFound},
+		{"pod not running", http.MethodGet, "/api/pods/portforward/default/stopped?port=80", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			PodPortForwardHandler(w, httptest.NewRequest(tt.method, tt.url, nil))
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPodProxyHandler_ProxiesOverTunnel(t *testing.T) {
+	var gotPort string
+	withPortForwardFakes(t, fake.NewSimpleClientset(newRunningPod("default", "web")), func(remote net.Conn, headers http.Header) {
+		defer remote.Close()
+		gotPort = headers.Get(corev1.PortHeader)
+		req, err := http.ReadRequest(bufio.NewReader(remote))
+		if err != nil {
+			return
+		}
+		body := "path=" + req.URL.Path + " prefix=" + req.Header.Get("X-Forwarded-Prefix")
+		resp := &http.Response{
+			StatusCode:    http.StatusFound,
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Location": []string{"/login"}, "Set-Cookie": []string{"session=abc; Path=/"}},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+		}
+		resp.Write(remote) //nolint:errcheck
+	})
+
+	w := httptest.NewRecorder()
+	PodProxyHandler(w, httptest.NewRequest(http.MethodGet, "/api/pods/proxy/default/web/8080/admin", nil))
+
+	if w.Code != http.StatusFound {
+		t.Fatalf("expected 302, got %d: %s", w.Code, w.Body.String())
+	}
+	if gotPort != "8080" {
+		t.Errorf("expected port header 8080, got %q", gotPort)
+	}
+	if loc := w.Header().Get("Location"); loc != "/api/pods/proxy/default/web/8080/login" {
+		t.Errorf("expected rewritten Location, got %q", loc)
+	}
+	if body := w.Body.String(); body != "path=/admin prefix=/api/pods/proxy/default/web/8080" {
+		t.Errorf("unexpected upstream view: %q", body)
+	}
+	if w.Header().Get("Set-Cookie") != "" {
+		t.Error("upstream cookies must not be set on the dashboard origin")
+	}
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.HasPrefix(csp, "sandbox") || strings.Contains(csp, "allow-same-origin") {
+		t.Errorf("expected a sandbox policy without allow-same-origin, got %q", csp)
+	}
+}
+
+func TestPortForwardOriginCheck(t *testing.T) {
+	tests := []struct {
+		host, origin string
+		want         bool
+	}{
+		{"dashboard.example.com", "", true},
+		{"dashboard.example.com", "https://dashboard.example.com", true},
+		{"dashboard.example.com", "https://evil.example.com", false},
+		{"localhost:8080", "http://localhost:5173", true},
+		{"127.0.0.1:8080", "http://localhost:5173", true},
+		{"dashboard.example.com", "http://localhost:5173", false},
+		{"dashboard.example.com", "://bad", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/api/pods/portforward/default/web?port=80", nil)
+		r.Host = tt.host
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if got := sameOriginOrLoopback(r); got != tt.want {
+			t.Errorf("host %q origin %q: got %v, want %v", tt.host, tt.origin, got, tt.want)
+		}
+	}
+
+	opened := false
+	withPortForwardFakes(t, fake.NewSimpleClientset(newReadyPod("default", "web", nil)), func(remote net.Conn, _ http.Header) {
+		opened = true
+		remote.Close()
+	})
+	r := httptest.NewRequest(http.MethodGet, "/api/pods/portforward/default/web?port=80", nil)
+	r.Header.Set("Origin", "https://evil.example.com")
+	w := httptest.NewRecorder()
+	PodPortForwardHandler(w, r)
+	if w.Code != http.StatusForbidden || opened {
+		t.Errorf("cross-origin socket: expected 403 without a tunnel, got %d (tunnel opened: %v)", w.Code, opened)
+	}
+}
+
+func TestPortForwardProxyRequestAllowed(t *testing.T) {
+	const base = "/api/pods/proxy/default/web/8080"
+	tests := []struct {
+		name, method, origin, referer string
+		want                          bool
+	}{
+		{"cross-site GET", http.MethodGet, "https://evil.example.com", "", true},
+		{"same-origin POST", http.MethodPost, "https://dashboard.example.com", "", true},
+		{"cross-site POST", http.MethodPost, "https://evil.example.com", "", false},
+		{"cross-site DELETE", http.MethodDelete, "https://evil.example.com", "", false},
+		{"sandboxed proxied page", http.MethodPost, "null", "https://dashboard.example.com" + base + "/admin", true},
+		{"sandboxed page of another target", http.MethodPost, "null", "https://dashboard.example.com/api/pods/proxy/default/db/8080/", false},
+		{"sandboxed cross-site page", http.MethodPut, "null", "https://evil.example.com" + base + "/", false},
+		{"sandboxed page without referer", http.MethodPost, "null", "", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, base+"/admin", nil)
+		r.Host = "dashboard.example.com"
+		r.Header.Set("Origin", tt.origin)
+		if tt.referer != "" {
+			r.Header.Set("Referer", tt.referer)
+		}
+		if got := proxyRequestAllowed(r, base); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	opened := false
+	withPortForwardFakes(t, fake.NewSimpleClientset(newRunningPod("default", "web")), func(remote net.Conn, _ http.Header) {
+		opened = true
+		remote.Close()
+	})
+	r := httptest.NewRequest(http.MethodPost, base+"/admin/delete", strings.NewReader("all=1"))
+	r.Header.Set("Origin", "https://evil.example.com")
+	w := httptest.NewRecorder()
+	PodProxyHandler(w, r)
+	if w.Code != http.StatusForbidden || opened {
+		t.Errorf("cross-site POST: expected 403 without a tunnel, got %d (tunnel opened: %v)", w.Code, opened)
+	}
+}
+
+func TestPodPortForwardHandler_ClosesWithCloseFrame(t *testing.T) {
+	withPortForwardFakes(t, fake.NewSimpleClientset(newReadyPod("default", "web", nil)), func(remote net.Conn, _ http.Header) {
+		remote.Write([]byte("pong")) //nolint:errcheck
+		remote.Close()
+	})
+	srv := httptest.NewServer(http.HandlerFunc(PodPortForwardHandler))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/pods/portforward/default/web?port=80", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	msgType, data, err := conn.ReadMessage()
+	if err != nil || msgType != websocket.BinaryMessage || string(data) != "pong" {
+		t.Fatalf("expected binary pong, got %d %q %v", msgType, data, err)
+	}
+	_, data, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		t.Fatalf("expected a close frame after the stream ended, got %q %v", data, err)
+	}
+	if closeErr.Code != websocket.CloseNormalClosure && closeErr.Code != websocket.CloseInternalServerErr {
+		t.Errorf("unexpected close code %d", closeErr.Code)
+	}
+}
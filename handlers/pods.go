@@ -19,30 +19,33 @@ import (
 
 // PodDetails represents detailed information about a pod
 type PodDetails struct {
-	Name                string   `json:"name"`
-	Namespace           string   `json:"namespace"`
-	Status              string   `json:"status"`
-	Restarts            int32    `json:"restarts"`
-	Node                string   `json:"node"`
-	Age                 string   `json:"age"`
-	Containers          []string `json:"containers"`
-	InitContainers      []string `json:"initContainers"`
-	EphemeralContainers []string `json:"ephemeralContainers"`
+	Name                string       `json:"name"`
+	Namespace           string       `json:"namespace"`
+	Status              string       `json:"status"`
+	Restarts            int32        `json:"restarts"`
+	Node                string       `json:"node"`
+	Age                 string       `json:"age"`
+	Containers          []string     `json:"containers"`
+	InitContainers      []string     `json:"initContainers"`
+	EphemeralContainers []string     `json:"ephemeralContainers"`
+	Resources           PodResources `json:"resources"`
+	// Diagnosis is only populated by the unhealthy pods endpoint.
+	Diagnosis []DiagnosisFinding `json:"diagnosis,omitempty"`
 }
 
 // podFilter is a predicate used to select which pods to include in results.
 type podFilter func(corev1.Pod) bool
 
-// UnhealthyPodsHandler handles the GET /api/pods/unhealthy endpoint
+// UnhealthyPodsHandler handles the GET /api/pods/unhealthy endpoint.
+// Each pod carries a ranked diagnosis of its probable root causes.
 var UnhealthyPodsHandler = handleGet("Failed to fetch pods data", func(r *http.Request) (interface{}, error) {
 	clientset, err := getKubernetesClient()
 	if err != nil {
 		return nil, err
 	}
 	namespace := r.URL.Query().Get("ns")
-	return listPods(r.Context(), clientset, namespace, func(pod corev1.Pod) bool {
-		return !isPodHealthy(pod)
-	})
+	metricsMap := fetchPodMetrics(r.Context(), getPodMetricsClient(), namespace)
+	return listUnhealthyPods(r.Context(), clientset, namespace, metricsMap)
 })
 
 // getLogClientset is a package-level variable that returns a Kubernetes client.
@@ -162,12 +165,19 @@ var AllPodsHandler = handleGet("Failed to fetch pods data", func(r *http.Request
 		return nil, err
 	}
 	namespace := r.URL.Query().Get("ns")
-	return listPods(r.Context(), clientset, namespace, nil)
+	metricsMap := fetchPodMetrics(r.Context(), getPodMetricsClient(), namespace)
+	return listPodsWithMetrics(r.Context(), clientset, namespace, nil, metricsMap)
 })
 
 // listPods fetches pods from Kubernetes and converts them to PodDetails.
 // If filter is non-nil, only pods matching the filter are included.
 func listPods(ctx context.Context, clientset kubernetes.Interface, namespace string, filter podFilter) ([]PodDetails, error) {
+	return listPodsWithMetrics(ctx, clientset, namespace, filter, nil)
+}
+
+// listPodsWithMetrics is listPods with usage from metrics-server, keyed by "namespace/name".
+// A nil metricsMap leaves usage out and reports only requests and limits.
+func listPodsWithMetrics(ctx context.Context, clientset kubernetes.Interface, namespace string, filter podFilter, metricsMap map[string]podMetricsUsage) ([]PodDetails, error) {
 	podList, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, err
@@ -178,41 +188,46 @@ func listPods(ctx context.Context, clientset kubernetes.Interface, namespace str
 		if filter != nil && !filter(pod) {
 			continue
 		}
+		pods = append(pods, buildPodDetails(pod, metricsMap[pod.Namespace+"/"+pod.Name]))
+	}
 
-		nodeName := pod.Spec.NodeName
-		if nodeName == "" {
-			nodeName = podNodePending
-		}
+	return pods, nil
+}
 
-		containerNames := make([]string, 0, len(pod.Spec.Containers))
-		for _, c := range pod.Spec.Containers {
-			containerNames = append(containerNames, c.Name)
-		}
+// buildPodDetails converts a pod to PodDetails, attaching usage when available.
+func buildPodDetails(pod corev1.Pod, usage podMetricsUsage) PodDetails {
+	nodeName := pod.Spec.NodeName
+	if nodeName == "" {
+		nodeName = podNodePending
+	}
 
-		initContainerNames := make([]string, 0, len(pod.Spec.InitContainers))
-		for _, c := range pod.Spec.InitContainers {
-			initContainerNames = append(initContainerNames, c.Name)
-		}
+	containerNames := make([]string, 0, len(pod.Spec.Containers))
+	for _, c := range pod.Spec.Containers {
+		containerNames = append(containerNames, c.Name)
+	}
 
-		ephemeralContainerNames := make([]string, 0, len(pod.Spec.EphemeralContainers))
-		for _, c := range pod.Spec.EphemeralContainers {
-			ephemeralContainerNames = append(ephemeralContainerNames, c.Name)
-		}
+	initContainerNames := make([]string, 0, len(pod.Spec.InitContainers))
+	for _, c := range pod.Spec.InitContainers {
+		initContainerNames = append(initContainerNames, c.Name)
+	}
 
-		pods = append(pods, PodDetails{
-			Name:                pod.Name,
-			Namespace:           pod.Namespace,
-			Status:              getPodStatus(pod),
-			Restarts:            getPodRestartCount(pod),
-			Node:                nodeName,
-			Age:                 formatPodAge(pod.CreationTimestamp.Time),
-			Containers:          containerNames,
-			InitContainers:      initContainerNames,
-			EphemeralContainers: ephemeralContainerNames,
-		})
+	ephemeralContainerNames := make([]string, 0, len(pod.Spec.EphemeralContainers))
+	for _, c := range pod.Spec.EphemeralContainers {
+		ephemeralContainerNames = append(ephemeralContainerNames, c.Name)
 	}
 
-	return pods, nil
+	return PodDetails{
+		Name:                pod.Name,
+		Namespace:           pod.Namespace,
+		Status:              getPodStatus(pod),
+		Restarts:            getPodRestartCount(pod),
+		Node:                nodeName,
+		Age:                 formatPodAge(pod.CreationTimestamp.Time),
+		Containers:          containerNames,
+		InitContainers:      initContainerNames,
+		EphemeralContainers: ephemeralContainerNames,
+		Resources:           buildPodResources(pod, usage),
+	}
 }
 
 // CleanupPodsResult represents the result of a pod cleanup operation.
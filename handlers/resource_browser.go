@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+)
+
+// tableAcceptHeader asks the API server for the Table rendering of a list, the
+// same server-side printing kubectl get uses.
+const tableAcceptHeader = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json"
+
+// defaultPermissionNamespace is the namespace whose RBAC rules decide which
+// resources the browser offers when no namespace is given.
+const defaultPermissionNamespace = "default"
+
+// getBrowserClientset is a package-level variable for obtaining the Kubernetes
+// client used for API discovery and permission checks. Tests may override this.
+var getBrowserClientset func() (kubernetes.Interface, error) = func() (kubernetes.Interface, error) {
+	return getKubernetesClient()
+}
+
+// getResourceTable fetches one page of a resource list in the server's Table
+// format, with each row carrying its full object. Tests may override this.
+var getResourceTable = func(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*metav1.Table, error) {
+	clientset, err := getKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+
+	path := []string{"/apis", gvr.Group, gvr.Version}
+	if gvr.Group == "" {
+		path = []string{"/api", gvr.Version}
+	}
+	if namespace != "" {
+		path = append(path, "namespaces", namespace)
+	}
+	path = append(path, gvr.Resource)
+
+	req := clientset.Discovery().RESTClient().Get().AbsPath(path...).
+		SetHeader("Accept", tableAcceptHeader).
+		Param("includeObject", string(metav1.IncludeObject))
+	if opts.Limit > 0 {
+		req = req.Param("limit", strconv.FormatInt(opts.Limit, 10))
+	}
+	if opts.Continue != "" {
+		req = req.Param("continue", opts.Continue)
+	}
+	if opts.LabelSelector != "" {
+		req = req.Param("labelSelector", opts.LabelSelector)
+	}
+	data, err := req.DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	table := &metav1.Table{}
+	if err := json.Unmarshal(data, table); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// APIResourceInfo describes a listable API resource.
+type APIResourceInfo struct {
+	Group      string   `json:"group"`
+	Version    string   `json:"version"`
+	Resource   string   `json:"resource"`
+	Kind       string   `json:"kind"`
+	Namespaced bool     `json:"namespaced"`
+	ShortNames []string `json:"shortNames,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	Verbs      []string `json:"verbs"`
+	// Editable reports whether the dashboard may patch the resource, which the
+	// YAML editor's server-side apply requires.
+	Editable bool `json:"editable"`
+}
+
+// APIResourcesResponse is the response of GET /api/resources.
+type APIResourcesResponse struct {
+	Resources []APIResourceInfo `json:"resources"`
+	// PermissionsIncomplete is set when the API server could not enumerate all
+	// permissions (e.g. a webhook authorizer is in use); resources are then
+	// listed by what they support rather than by what may be listed.
+	PermissionsIncomplete bool `json:"permissionsIncomplete"`
+	// FailedGroups lists group versions whose discovery failed, such as an
+	// unavailable aggregated API.
+	FailedGroups []string `json:"failedGroups,omitempty"`
+}
+
+// ResourceColumn is a printer column of the server's Table rendering.
+type ResourceColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Format      string `json:"format,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Priority 0 columns are shown by kubectl get; higher ones only with -o wide.
+	Priority int32 `json:"priority"`
+}
+
+// ResourceCondition is an entry of an object's status.conditions.
+type ResourceCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ResourceItem is one object of a generic resource list.
+type ResourceItem struct {
+	Name       string              `json:"name"`
+	Namespace  string              `json:"namespace,omitempty"`
+	Age        string              `json:"age"`
+	CreatedAt  time.Time           `json:"createdAt"`
+	Conditions []ResourceCondition `json:"conditions"`
+	// Cells are the values of Columns for this object, when the table was available.
+	Cells []interface{} `json:"cells,omitempty"`
+}
+
+// ResourceListResponse is the response of GET /api/resources/{group}/{version}/{resource}.
+type ResourceListResponse struct {
+	Columns []ResourceColumn `json:"columns"`
+	Items   []ResourceItem   `json:"items"`
+	// Continue is the token for the next page, empty on the last page.
+	Continue string `json:"continue,omitempty"`
+}
+
+// APIResourcesHandler handles GET /api/resources. It lists the resources the
+// dashboard's service account may list in the ns namespace (default "default"),
+// in the version preferred by the server.
+var APIResourcesHandler = handleGet(errMsgAPIResourcesFetch, func(r *http.Request) (interface{}, error) {
+	clientset, err := getBrowserClientset()
+	if err != nil {
+		return nil, err
+	}
+	namespace := r.URL.Query().Get("ns")
+	if namespace == "" {
+		namespace = defaultPermissionNamespace
+	}
+	return getAPIResources(r.Context(), clientset, namespace)
+})
+
+func getAPIResources(ctx context.Context, clientset kubernetes.Interface, namespace string) (*APIResourcesResponse, error) {
+	resp := &APIResourcesResponse{Resources: []APIResourceInfo{}}
+
+	lists, err := discovery.ServerPreferredResources(clientset.Discovery())
+	if err != nil {
+		groupErr, ok := err.(*discovery.ErrGroupDiscoveryFailed)
+		if !ok {
+			return nil, err
+		}
+		for gv := range groupErr.Groups {
+			resp.FailedGroups = append(resp.FailedGroups, gv.String())
+		}
+		sort.Strings(resp.FailedGroups)
+	}
+
+	review, err := clientset.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, &authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: namespace},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	resp.PermissionsIncomplete = review.Status.Incomplete
+
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, res := range list.APIResources {
+			if strings.Contains(res.Name, "/") || !containsString(res.Verbs, "list") {
+				continue
+			}
+			if !resp.PermissionsIncomplete && !rulesAllow(review.Status.ResourceRules, gv.Group, res.Name, "list") {
+				continue
+			}
+			resp.Resources = append(resp.Resources, APIResourceInfo{
+				Group:      gv.Group,
+				Version:    gv.Version,
+				Resource:   res.Name,
+				Kind:       res.Kind,
+				Namespaced: res.Namespaced,
+				ShortNames: res.ShortNames,
+				Categories: res.Categories,
+				Verbs:      res.Verbs,
+				Editable: containsString(res.Verbs, "patch") &&
+					(resp.PermissionsIncomplete || rulesAllow(review.Status.ResourceRules, gv.Group, res.Name, "patch")),
+			})
+		}
+	}
+	sort.Slice(resp.Resources, func(i, j int) bool {
+		a, b := resp.Resources[i], resp.Resources[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		return a.Resource < b.Resource
+	})
+	return resp, nil
+}
+
+// rulesAllow reports whether a rule grants verb on every object of the resource.
+// Rules restricted to resourceNames do not count.
+func rulesAllow(rules []authorizationv1.ResourceRule, group, resource, verb string) bool {
+	for _, rule := range rules {
+		if len(rule.ResourceNames) == 0 &&
+			matchesRuleValue(rule.Verbs, verb) &&
+			matchesRuleValue(rule.APIGroups, group) &&
+			matchesRuleValue(rule.Resources, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesRuleValue(values []string, value string) bool {
+	return containsString(values, "*") || containsString(values, value)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// handleListResources handles GET /api/resources/{group}/{version}/{resource}
+// with optional ns, labelSelector, limit and continue query parameters.
+func handleListResources(w http.ResponseWriter, r *http.Request, gvr schema.GroupVersionResource) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	r = withTimeout(r)
+
+	query := r.URL.Query()
+	opts := metav1.ListOptions{LabelSelector: query.Get("labelSelector"), Continue: query.Get("continue")}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || limit < 0 {
+			writeError(w, http.StatusBadRequest, errMsgListLimitInvalid)
+			return
+		}
+		opts.Limit = limit
+	}
+
+	namespace := query.Get("ns")
+	table, err := getResourceTable(r.Context(), gvr, namespace, opts)
+	if err == nil {
+		if resp, ok := tableResourceList(table); ok {
+			writeJSON(w, http.StatusOK, resp)
+			return
+		}
+		err = errTableWithoutObjects
+	}
+
+	// Without the Table rendering the list still has the generic fields.
+	slog.Warn("Failed to fetch table rendering", "resource", gvr.String(), "error", err)
+	client, err := getResourceDynamicClient()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+	list, err := resourceClient(client, gvr, namespace).List(r.Context(), opts)
+	if err != nil {
+		writeAPIError(w, err, errMsgResourceList)
+		return
+	}
+
+	resp := &ResourceListResponse{
+		Columns:  []ResourceColumn{},
+		Items:    make([]ResourceItem, 0, len(list.Items)),
+		Continue: list.GetContinue(),
+	}
+	for i := range list.Items {
+		resp.Items = append(resp.Items, buildResourceItem(&list.Items[i]))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// errTableWithoutObjects reports a Table whose rows lack the embedded objects,
+// as returned by servers that ignore includeObject.
+var errTableWithoutObjects = errors.New("table rows do not include objects")
+
+// tableResourceList builds the list response from a Table fetched with
+// includeObject=Object: the columns and cells come from the table and the
+// generic fields from each row's object. It reports false if a row has no
+// decodable object.
+func tableResourceList(table *metav1.Table) (*ResourceListResponse, bool) {
+	resp := &ResourceListResponse{
+		Columns:  make([]ResourceColumn, 0, len(table.ColumnDefinitions)),
+		Items:    make([]ResourceItem, 0, len(table.Rows)),
+		Continue: table.Continue,
+	}
+	for _, c := range table.ColumnDefinitions {
+		resp.Columns = append(resp.Columns, ResourceColumn{
+			Name:        c.Name,
+			Type:        c.Type,
+			Format:      c.Format,
+			Description: c.Description,
+			Priority:    c.Priority,
+		})
+	}
+	for _, row := range table.Rows {
+		obj := &unstructured.Unstructured{}
+		if len(row.Object.Raw) == 0 || obj.UnmarshalJSON(row.Object.Raw) != nil {
+			return nil, false
+		}
+		item := buildResourceItem(obj)
+		item.Cells = row.Cells
+		resp.Items = append(resp.Items, item)
+	}
+	return resp, true
+}
+
+func buildResourceItem(obj *unstructured.Unstructured) ResourceItem {
+	item := ResourceItem{
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+		Age:        formatPodAge(obj.GetCreationTimestamp().Time),
+		CreatedAt:  obj.GetCreationTimestamp().Time,
+		Conditions: []ResourceCondition{},
+	}
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condition := ResourceCondition{}
+		condition.Type, _ = m["type"].(string)
+		condition.Status, _ = m["status"].(string)
+		condition.Reason, _ = m["reason"].(string)
+		condition.Message, _ = m["message"].(string)
+		if condition.Type != "" {
+			item.Conditions = append(item.Conditions, condition)
+		}
+	}
+	return item
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// newDiscoveryClientset serves core pods and configmaps, apps deployments and a
+// CRD, and answers rules reviews with rules.
+func newDiscoveryClientset(rules []authorizationv1.ResourceRule, incomplete bool) *fake.Clientset {
+	cs := fake.NewSimpleClientset()
+	cs.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"get", "list"}},
+			{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: []string{"get"}},
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"get", "list", "patch"}},
+			{Name: "bindings", Kind: "Binding", Namespaced: true, Verbs: []string{"create"}},
+		}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+			{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: []string{"get", "list"}},
+		}},
+		{GroupVersion: "example.com/v1", APIResources: []metav1.APIResource{
+			{Name: "widgets", Kind: "Widget", Namespaced: false, ShortNames: []string{"wd"}, Verbs: []string{"get", "list", "patch"}},
+		}},
+	}
+	cs.PrependReactor("create", "selfsubjectrulesreviews", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &authorizationv1.SelfSubjectRulesReview{
+			Status: authorizationv1.SubjectRulesReviewStatus{ResourceRules: rules, Incomplete: incomplete},
+		}, nil
+	})
+	return cs
+}
+
+func TestGetAPIResources(t *testing.T) {
+	rules := []authorizationv1.ResourceRule{
+		{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods"}},
+		{Verbs: []string{"list"}, APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"one"}},
+		{Verbs: []string{"*"}, APIGroups: []string{"example.com"}, Resources: []string{"*"}},
+		{Verbs: []string{"get"}, APIGroups: []string{"apps"}, Resources: []string{"deployments"}},
+	}
+
+	resp, err := getAPIResources(context.Background(), newDiscoveryClientset(rules, false), "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range resp.Resources {
+		got = append(got, r.Group+"/"+r.Version+"/"+r.Resource)
+	}
+	if len(got) != 2 || got[0] != "/v1/pods" || got[1] != "example.com/v1/widgets" {
+		t.Errorf("expected only listable resources, got %v", got)
+	}
+	if resp.Resources[1].Namespaced || resp.Resources[1].ShortNames[0] != "wd" {
+		t.Errorf("unexpected widget info: %+v", resp.Resources[1])
+	}
+	if resp.Resources[0].Editable || !resp.Resources[1].Editable {
+		t.Errorf("only widgets may be patched: %+v", resp.Resources)
+	}
+
+	resp, err = getAPIResources(context.Background(), newDiscoveryClientset(nil, true), "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.PermissionsIncomplete || len(resp.Resources) != 4 {
+		t.Errorf("incomplete rules should fall back to every list-capable resource, got %+v", resp)
+	}
+	for _, r := range resp.Resources {
+		if r.Editable != (r.Resource == "configmaps" || r.Resource == "widgets") {
+			t.Errorf("with incomplete rules, %s editable = %v", r.Resource, r.Editable)
+		}
+	}
+}
+
+func TestResourcesHandler_List(t *testing.T) {
+	widgetsGVR := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	widget := func(name string, conditions ...interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.com/v1", "kind": "Widget",
+			"metadata": map[string]interface{}{"name": name, "namespace": "default"},
+		}}
+		if len(conditions) > 0 {
+			unstructured.SetNestedSlice(obj.Object, conditions, "status", "conditions") //nolint:errcheck
+		}
+		return obj
+	}
+	alpha := widget("alpha", map[string]interface{}{"type": "Ready", "status": "True", "reason": "Synced"})
+	beta := widget("beta")
+	withResourceDynamicClient(t, dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{widgetsGVR: "WidgetList"}, alpha, beta))
+
+	oldTable := getResourceTable
+	t.Cleanup(func() { getResourceTable = oldTable })
+	var tableNamespace string
+	var tableErr error
+	getResourceTable = func(_ context.Context, gvr schema.GroupVersionResource, namespace string, _ metav1.ListOptions) (*metav1.Table, error) {
+		tableNamespace = namespace
+		if tableErr != nil {
+			return nil, tableErr
+		}
+		row := func(obj *unstructured.Unstructured, size string) metav1.TableRow {
+			raw, _ := obj.MarshalJSON()
+			return metav1.TableRow{Cells: []interface{}{obj.GetName(), size}, Object: runtime.RawExtension{Raw: raw}}
+		}
+		return &metav1.Table{
+			ListMeta:          metav1.ListMeta{Continue: "next"},
+			ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name", Type: "string"}, {Name: "Size", Type: "string", Priority: 1}},
+			Rows:              []metav1.TableRow{row(beta, "L"), row(alpha, "S")},
+		}, nil
+	}
+
+	list := func() ResourceListResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		ResourcesHandler(w, httptest.NewRequest(http.MethodGet, "/api/resources/example.com/v1/widgets?ns=default", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp ResourceListResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := list()
+	if tableNamespace != "default" || len(resp.Columns) != 2 || resp.Columns[1].Priority != 1 || resp.Continue != "next" {
+		t.Errorf("unexpected table response: %+v", resp)
+	}
+	if len(resp.Items) != 2 || resp.Items[0].Name != "beta" {
+		t.Fatalf("items should follow the table rows, got %+v", resp.Items)
+	}
+	if a := resp.Items[1]; len(a.Conditions) != 1 || a.Conditions[0].Reason != "Synced" || a.Cells[1] != "S" {
+		t.Errorf("alpha = %+v", a)
+	}
+	if b := resp.Items[0]; len(b.Conditions) != 0 || b.Cells[1] != "L" {
+		t.Errorf("beta = %+v", b)
+	}
+
+	// Without the table the plain list still lists the objects.
+	tableErr = errors.New("table rendering unavailable")
+	resp = list()
+	if len(resp.Columns) != 0 || len(resp.Items) != 2 {
+		t.Fatalf("fallback response = %+v", resp)
+	}
+	for _, item := range resp.Items {
+		if item.Cells != nil || (item.Name == "alpha" && len(item.Conditions) != 1) {
+			t.Errorf("fallback item = %+v", item)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	ResourcesHandler(w, httptest.NewRequest(http.MethodGet, "/api/resources/example.com/v1/widgets?limit=x", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid limit: expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// dashboardFieldManager is the server-side apply field manager for edits made
+// through the dashboard.
+const dashboardFieldManager = "kubernetes-dashboard"
+
+// Path placeholders of the generic resource endpoints: the core API group has
+// an empty name, and cluster-scoped objects have no namespace.
+const (
+	coreGroupPathSegment    = "core"
+	clusterScopePathSegment = "_"
+)
+
+// maxApplyBodyBytes bounds the YAML accepted by the apply endpoint; etcd rejects
+// objects over about 1.5 MiB anyway.
+const maxApplyBodyBytes = 3 << 20
+
+// getResourceDynamicClient returns the dynamic client used by the generic
+// resource endpoints. Tests may override this.
+var getResourceDynamicClient = getDynamicClient
+
+// resourceRef identifies one object of any resource type.
+type resourceRef struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+	name      string
+}
+
+// ApplyResponse is the result of applying edited YAML.
+type ApplyResponse struct {
+	DryRun  bool `json:"dryRun"`
+	Changed bool `json:"changed"`
+	// Diff is a unified diff from the live object to the applied result.
+	Diff string `json:"diff"`
+	// YAML is the applied (or, on dry run, would-be) object.
+	YAML            string `json:"yaml"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	Message         string `json:"message"`
+}
+
+// ResourcesHandler handles the /api/resources/{group}/{version}/{resource} list
+// endpoint and the /api/resources/{group}/{version}/{resource}/{ns}/{name} object
+// endpoints. {group} is "core" for the core API group and {ns} is "_" for
+// cluster-scoped objects.
+func ResourcesHandler(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, resourcesPathPrefix), "/")
+	for _, s := range segments {
+		if s == "" {
+			writeError(w, http.StatusBadRequest, errMsgResourcePathInvalid)
+			return
+		}
+	}
+	if len(segments) == 3 {
+		handleListResources(w, r, parseGVR(segments[0], segments[1], segments[2]))
+		return
+	}
+	if len(segments) != 5 {
+		writeError(w, http.StatusBadRequest, errMsgResourcePathInvalid)
+		return
+	}
+
+	ref := resourceRef{gvr: parseGVR(segments[0], segments[1], segments[2]), namespace: segments[3], name: segments[4]}
+	if ref.namespace == clusterScopePathSegment {
+		ref.namespace = ""
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		handleGetResource(w, r, ref)
+	case http.MethodPut:
+		handleApplyResource(w, r, ref)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// parseGVR builds a GroupVersionResource from path segments.
+func parseGVR(group, version, resource string) schema.GroupVersionResource {
+	if group == coreGroupPathSegment {
+		group = ""
+	}
+	return schema.GroupVersionResource{Group: group, Version: version, Resource: resource}
+}
+
+// resourceClient returns the dynamic client for the resource, scoped to its namespace.
+func resourceClient(client dynamic.Interface, gvr schema.GroupVersionResource, namespace string) dynamic.ResourceInterface {
+	if namespace == "" {
+		return client.Resource(gvr)
+	}
+	return client.Resource(gvr).Namespace(namespace)
+}
+
+func handleGetResource(w http.ResponseWriter, r *http.Request, ref resourceRef) {
+	r = withTimeout(r)
+
+	client, err := getResourceDynamicClient()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	obj, err := resourceClient(client, ref.gvr, ref.namespace).Get(r.Context(), ref.name, metav1.GetOptions{})
+	if err != nil {
+		writeAPIError(w, err, errMsgResourceFetch)
+		return
+	}
+	stripManagedFields(obj)
+
+	if r.URL.Query().Get("format") != "yaml" {
+		writeJSON(w, http.StatusOK, obj.Object)
+		return
+	}
+	data, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		slog.Error("Failed to encode resource as YAML", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgResourceFetch)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data) //nolint:errcheck
+}
+
+// handleApplyResource applies the YAML body with server-side apply. With
+// dryRun=true nothing is persisted and the response only carries the diff;
+// force=true takes ownership of fields managed by other field managers.
+func handleApplyResource(w http.ResponseWriter, r *http.Request, ref resourceRef) {
+	r = withTimeout(r)
+
+	query := r.URL.Query()
+	dryRun, _ := strconv.ParseBool(query.Get("dryRun"))
+	force, _ := strconv.ParseBool(query.Get("force"))
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxApplyBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, errMsgResourceTooLarge)
+		return
+	}
+	obj, err := parseAppliedObject(body, ref)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	client, err := getResourceDynamicClient()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		writeError(w, http.StatusInternalServerError, errMsgClientCreate)
+		return
+	}
+
+	resp, err := applyResource(r.Context(), resourceClient(client, ref.gvr, ref.namespace), ref.name, obj, dryRun, force)
+	if k8serrors.IsForbidden(err) {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("%s: %s", errMsgResourceApplyForbidden, err.Error()))
+		return
+	}
+	if err != nil {
+		writeAPIError(w, err, errMsgResourceApply)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseAppliedObject decodes edited YAML (or JSON) and checks that it names the
+// object in the path. Server-populated fields are dropped so they are neither
+// claimed by the dashboard field manager nor rejected by the API server;
+// metadata.resourceVersion is kept so edits of a stale copy fail with a conflict.
+func parseAppliedObject(body []byte, ref resourceRef) (*unstructured.Unstructured, error) {
+	data, err := yaml.YAMLToJSON(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid YAML: %v", err)
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("invalid object: %v", err)
+	}
+
+	if obj.GetName() != ref.name {
+		return nil, fmt.Errorf("metadata.name %q does not match %q in the path", obj.GetName(), ref.name)
+	}
+	if ns := obj.GetNamespace(); ns != "" && ns != ref.namespace {
+		return nil, fmt.Errorf("metadata.namespace %q does not match %q in the path", ns, ref.namespace)
+	}
+	if gv := obj.GroupVersionKind().GroupVersion(); gv != ref.gvr.GroupVersion() {
+		return nil, fmt.Errorf("apiVersion %q does not match %q in the path", obj.GetAPIVersion(), ref.gvr.GroupVersion().String())
+	}
+	obj.SetNamespace(ref.namespace)
+
+	stripManagedFields(obj)
+	for _, field := range [][]string{
+		{"metadata", "creationTimestamp"},
+		{"metadata", "generation"},
+		{"metadata", "uid"},
+		{"metadata", "selfLink"},
+		{"status"},
+	} {
+		unstructured.RemoveNestedField(obj.Object, field...)
+	}
+	return obj, nil
+}
+
+// applyResource server-side applies obj and diffs the result against the live object.
+func applyResource(ctx context.Context, client dynamic.ResourceInterface, name string, obj *unstructured.Unstructured, dryRun, force bool) (*ApplyResponse, error) {
+	live, err := client.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	stripManagedFields(live)
+
+	opts := metav1.ApplyOptions{FieldManager: dashboardFieldManager, Force: force}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	applied, err := client.Apply(ctx, name, obj, opts)
+	if err != nil {
+		return nil, err
+	}
+	stripManagedFields(applied)
+
+	diff, err := yamlDiff(live.Object, applied.Object)
+	if err != nil {
+		return nil, err
+	}
+	out, err := yaml.Marshal(applied.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ApplyResponse{
+		DryRun:  dryRun,
+		Changed: diff != "",
+		Diff:    diff,
+		YAML:    string(out),
+	}
+	switch {
+	case dryRun:
+		resp.Message = "Dry run: no changes were persisted"
+	case resp.Changed:
+		resp.ResourceVersion = applied.GetResourceVersion()
+		resp.Message = "Changes applied"
+	default:
+		resp.ResourceVersion = applied.GetResourceVersion()
+		resp.Message = "No changes"
+	}
+	return resp, nil
+}
+
+// stripManagedFields removes metadata.managedFields, which only adds noise to
+// displayed and diffed objects.
+func stripManagedFields(obj *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+}
+
+// writeAPIError writes an API server error with its status code and message, so
+// validation failures, apply conflicts and RBAC denials reach the user verbatim.
+// Other errors are logged and reported as internalMsg.
+func writeAPIError(w http.ResponseWriter, err error, internalMsg string) {
+	var status k8serrors.APIStatus
+	if errors.As(err, &status) {
+		if code := int(status.Status().Code); code >= 400 && code < 500 {
+			writeError(w, code, status.Status().Message)
+			return
+		}
+	}
+	slog.Error("Resource operation failed", "error", err)
+	writeError(w, http.StatusInternalServerError, internalMsg)
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+var configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// withResourceDynamicClient injects client for the generic resource endpoints.
+func withResourceDynamicClient(t *testing.T, client dynamic.Interface) {
+	t.Helper()
+	old := getResourceDynamicClient
+	getResourceDynamicClient = func() (dynamic.Interface, error) { return client, nil }
+	t.Cleanup(func() { getResourceDynamicClient = old })
+}
+
+func newUnstructuredConfigMap(data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name": "settings", "namespace": "default", "resourceVersion": "7",
+			"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+		},
+		"data": data,
+	}}
+}
+
+// newApplyClient returns a fake dynamic client holding the settings ConfigMap.
+// Server-side apply is simulated by replacing data with the applied data; the
+// applied objects and dry-run flags are recorded.
+func newApplyClient(applied *[]*unstructured.Unstructured) *dynamicfake.FakeDynamicClient {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{configMapsGVR: "ConfigMapList"},
+		newUnstructuredConfigMap(map[string]interface{}{"mode": "fast"}))
+	client.PrependReactor("patch", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patch := action.(clienttesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		*applied = append(*applied, obj)
+		if obj.GetResourceVersion() == "1" {
+			return true, nil, k8serrors.NewConflict(configMapsGVR.GroupResource(), "settings",
+				errors.New("the object has been modified"))
+		}
+		result := newUnstructuredConfigMap(obj.Object["data"].(map[string]interface{}))
+		result.SetResourceVersion("8")
+		return true, result, nil
+	})
+	return client
+}
+
+func TestResourcesHandler_GetYAML(t *testing.T) {
+	withResourceDynamicClient(t, newApplyClient(new([]*unstructured.Unstructured)))
+
+	w := httptest.NewRecorder()
+	ResourcesHandler(w, httptest.NewRequest(http.MethodGet, "/api/resources/core/v1/configmaps/default/settings?format=yaml", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "application/yaml" {
+		t.Errorf("Content-Type = %q", w.Header().Get("Content-Type"))
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "mode: fast") || strings.Contains(body, "managedFields") {
+		t.Errorf("unexpected YAML:\n%s", body)
+	}
+
+	w = httptest.NewRecorder()
+	ResourcesHandler(w, httptest.NewRequest(http.MethodGet, "/api/resources/core/v1/configmaps/default/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing object: expected 404, got %d", w.Code)
+	}
+}
+
+func TestResourcesHandler_Apply(t *testing.T) {
+	var applied []*unstructured.Unstructured
+	withResourceDynamicClient(t, newApplyClient(&applied))
+	put := func(query, body string) (*httptest.ResponseRecorder, ApplyResponse) {
+		w := httptest.NewRecorder()
+		ResourcesHandler(w, httptest.NewRequest(http.MethodPut, "/api/resources/core/v1/configmaps/default/settings"+query, strings.NewReader(body)))
+		var resp ApplyResponse
+		if w.Code == http.StatusOK {
+			json.NewDecoder(w.Body).Decode(&resp) //nolint:errcheck
+		}
+		return w, resp
+	}
+	edited := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  resourceVersion: "7"
+  uid: abc
+  managedFields:
+  - manager: kubectl
+data:
+  mode: safe
+status: {}
+`
+
+	w, resp := put("?dryRun=true", edited)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !resp.DryRun || !resp.Changed || !strings.Contains(resp.Diff, "-  mode: fast\n+  mode: safe") {
+		t.Errorf("unexpected dry run response: %+v", resp)
+	}
+	if strings.Contains(resp.Diff, "managedFields") || strings.Contains(resp.YAML, "managedFields") {
+		t.Errorf("managedFields must be stripped: %+v", resp)
+	}
+
+	sent := applied[0]
+	if _, ok := sent.Object["status"]; ok || sent.GetUID() != "" || sent.GetManagedFields() != nil {
+		t.Errorf("server-populated fields should be dropped before applying: %v", sent.Object)
+	}
+	if sent.GetNamespace() != "default" || sent.GetResourceVersion() != "7" {
+		t.Errorf("namespace should be filled in and resourceVersion kept: %v", sent.Object)
+	}
+
+	w, resp = put("", edited)
+	if w.Code != http.StatusOK || resp.DryRun || resp.ResourceVersion != "8" {
+		t.Errorf("apply: %d %+v", w.Code, resp)
+	}
+
+	w, _ = put("", strings.Replace(edited, `resourceVersion: "7"`, `resourceVersion: "1"`, 1))
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "modified") {
+		t.Errorf("stale edit: expected 409 with the API message, got %d: %s", w.Code, w.Body.String())
+	}
+
+	for name, body := range map[string]string{
+		"name mismatch":      strings.Replace(edited, "name: settings", "name: other", 1),
+		"namespace mismatch": strings.Replace(edited, "name: settings", "name: settings\n  namespace: kube-system", 1),
+		"group mismatch":     strings.Replace(edited, "apiVersion: v1", "apiVersion: apps/v1", 1),
+		"invalid yaml":       "data: [",
+	} {
+		if w, _ := put("", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestResourcesHandler_ApplyForbidden(t *testing.T) {
+	client := newApplyClient(new([]*unstructured.Unstructured))
+	client.PrependReactor("patch", "configmaps", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewForbidden(configMapsGVR.GroupResource(), "settings",
+			errors.New(`cannot patch resource "configmaps"`))
+	})
+	withResourceDynamicClient(t, client)
+
+	w := httptest.NewRecorder()
+	ResourcesHandler(w, httptest.NewRequest(http.MethodPut, "/api/resources/core/v1/configmaps/default/settings",
+		strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\ndata:\n  mode: safe\n")))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+	if body := w.Body.String(); !strings.Contains(body, "not allowed to patch") || !strings.Contains(body, "cannot patch") {
+		t.Errorf("expected the missing permission to be explained: %s", body)
+	}
+}
+
+func TestResourcesHandler_Path(t *testing.T) {
+	withResourceDynamicClient(t, newApplyClient(new([]*unstructured.Unstructured)))
+	for _, path := range []string{
+		"/api/resources/core/v1/configmaps/default",
+		"/api/resources/core/v1/configmaps/default/settings/extra",
+		"/api/resources/core//configmaps/default/settings",
+	} {
+		w := httptest.NewRecorder()
+		ResourcesHandler(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, w.Code)
+		}
+	}
+
+	if gvr := parseGVR("apps", "v1", "deployments"); gvr.Group != "apps" {
+		t.Errorf("parseGVR(apps) = %v", gvr)
+	}
+	if gvr := parseGVR(coreGroupPathSegment, "v1", "pods"); gvr.Group != "" {
+		t.Errorf("core group should map to the empty group, got %v", gvr)
+	}
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// getNetworkClientset is a package-level variable for obtaining the Kubernetes client
+// used by the service and ingress endpoints. Tests may override this.
+var getNetworkClientset func() (kubernetes.Interface, error) = func() (kubernetes.Interface, error) {
+	return getKubernetesClient()
+}
+
+// ServicePortInfo is a port exposed by a service.
+type ServicePortInfo struct {
+	Name       string `json:"name,omitempty"`
+	Protocol   string `json:"protocol"`
+	Port       int32  `json:"port"`
+	TargetPort string `json:"targetPort"`
+	NodePort   int32  `json:"nodePort,omitempty"`
+}
+
+// EndpointCounts counts a service's endpoints by readiness.
+type EndpointCounts struct {
+	Ready    int `json:"ready"`
+	NotReady int `json:"notReady"`
+}
+
+// ServiceInfo represents summary information about a service
+type ServiceInfo struct {
+	Name         string   `json:"name"`
+	Namespace    string   `json:"namespace"`
+	Type         string   `json:"type"`
+	ClusterIP    string   `json:"clusterIP,omitempty"`
+	ExternalName string   `json:"externalName,omitempty"`
+	ExternalIPs  []string `json:"externalIPs,omitempty"`
+	// LoadBalancer lists the ingress addresses of a LoadBalancer service's load balancer.
+	LoadBalancer []string          `json:"loadBalancer,omitempty"`
+	Ports        []ServicePortInfo `json:"ports"`
+	Selector     map[string]string `json:"selector,omitempty"`
+	Endpoints    EndpointCounts    `json:"endpoints"`
+	// NoMatchingPods is set when the service has a selector that matches no pods.
+	NoMatchingPods bool   `json:"noMatchingPods"`
+	Age            string `json:"age"`
+}
+
+// ServiceEndpoint is one endpoint of a service from its EndpointSlices.
+type ServiceEndpoint struct {
+	Addresses   []string `json:"addresses"`
+	Ready       bool     `json:"ready"`
+	Serving     bool     `json:"serving"`
+	Terminating bool     `json:"terminating"`
+	Pod         string   `json:"pod,omitempty"`
+	Node        string   `json:"node,omitempty"`
+	Zone        string   `json:"zone,omitempty"`
+}
+
+// ServicePod is a pod selected by a service.
+type ServicePod struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Ready  bool   `json:"ready"`
+	Node   string `json:"node,omitempty"`
+	IP     string `json:"ip,omitempty"`
+}
+
+// ServiceDetail is the response of GET /api/services/{ns}/{name}.
+type ServiceDetail struct {
+	ServiceInfo
+	Labels       map[string]string `json:"labels"`
+	EndpointList []ServiceEndpoint `json:"endpointList"`
+	Pods         []ServicePod      `json:"pods"`
+	// Ingresses names the ingresses in the namespace routing to the service.
+	Ingresses []string `json:"ingresses"`
+}
+
+// ServicesHandler handles the GET /api/services endpoint
+var ServicesHandler = handleGet("Failed to fetch services data", func(r *http.Request) (interface{}, error) {
+	clientset, err := getNetworkClientset()
+	if err != nil {
+		return nil, err
+	}
+	return getServicesData(r.Context(), clientset, r.URL.Query().Get("ns"))
+})
+
+func getServicesData(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]ServiceInfo, error) {
+	idx, err := loadServiceIndex(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]ServiceInfo, 0, len(idx.services))
+	for _, svc := range idx.services {
+		services = append(services, idx.serviceInfo(svc))
+	}
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Namespace != services[j].Namespace {
+			return services[i].Namespace < services[j].Namespace
+		}
+		return services[i].Name < services[j].Name
+	})
+	return services, nil
+}
+
+// ServiceDetailHandler handles GET /api/services/{ns}/{name}.
+func ServiceDetailHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, clientset := parseNamespacedAction(w, r, servicesPathPrefix, "", getNetworkClientset)
+	if clientset == nil {
+		return
+	}
+
+	detail, err := getServiceDetail(r.Context(), clientset, namespace, name)
+	if err != nil {
+		writeResourceError(w, err, errMsgServiceNotFound, errMsgServiceFetch)
+		return
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+func getServiceDetail(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*ServiceDetail, error) {
+	if _, err := clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return nil, err
+	}
+	idx, err := loadServiceIndex(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	svc := idx.services[namespace+"/"+name]
+	if svc == nil {
+		// Deleted between the two calls.
+		return nil, &statusError{status: http.StatusNotFound, message: errMsgServiceNotFound}
+	}
+	ingresses, err := clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &ServiceDetail{
+		ServiceInfo:  idx.serviceInfo(svc),
+		Labels:       svc.Labels,
+		EndpointList: []ServiceEndpoint{},
+		Pods:         []ServicePod{},
+		Ingresses:    []string{},
+	}
+	for _, slice := range idx.slices[namespace+"/"+name] {
+		for _, ep := range slice.Endpoints {
+			endpoint := ServiceEndpoint{
+				Addresses:   ep.Addresses,
+				Ready:       ep.Conditions.Ready == nil || *ep.Conditions.Ready,
+				Serving:     ep.Conditions.Serving == nil || *ep.Conditions.Serving,
+				Terminating: ep.Conditions.Terminating != nil && *ep.Conditions.Terminating,
+			}
+			if ep.TargetRef != nil && ep.TargetRef.Kind == "Pod" {
+				endpoint.Pod = ep.TargetRef.Name
+			}
+			if ep.NodeName != nil {
+				endpoint.Node = *ep.NodeName
+			}
+			if ep.Zone != nil {
+				endpoint.Zone = *ep.Zone
+			}
+			detail.EndpointList = append(detail.EndpointList, endpoint)
+		}
+	}
+	for _, pod := range idx.matchingPods(svc) {
+		detail.Pods = append(detail.Pods, ServicePod{
+			Name:   pod.Name,
+			Status: getPodStatus(pod),
+			Ready:  isPodReady(&pod),
+			Node:   pod.Spec.NodeName,
+			IP:     pod.Status.PodIP,
+		})
+	}
+	for _, ing := range ingresses.Items {
+		for _, backend := range ingressServiceBackends(&ing) {
+			if backend.Name == name {
+				detail.Ingresses = append(detail.Ingresses, ing.Name)
+				break
+			}
+		}
+	}
+	return detail, nil
+}
+
+// serviceIndex holds the services, pods and EndpointSlices of a namespace (or
+// of all namespaces) to resolve service selectors and endpoints without
+// further API calls.
+type serviceIndex struct {
+	services map[string]*corev1.Service // by namespace/name
+	pods     map[string][]corev1.Pod    // by namespace
+	slices   map[string][]discoveryv1.EndpointSlice
+}
+
+func loadServiceIndex(ctx context.Context, clientset kubernetes.Interface, namespace string) (*serviceIndex, error) {
+	services, err := clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	slices, err := clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &serviceIndex{
+		services: make(map[string]*corev1.Service, len(services.Items)),
+		pods:     make(map[string][]corev1.Pod),
+		slices:   make(map[string][]discoveryv1.EndpointSlice),
+	}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		idx.services[svc.Namespace+"/"+svc.Name] = svc
+	}
+	for _, pod := range pods.Items {
+		idx.pods[pod.Namespace] = append(idx.pods[pod.Namespace], pod)
+	}
+	for _, slice := range slices.Items {
+		if owner := slice.Labels[discoveryv1.LabelServiceName]; owner != "" {
+			key := slice.Namespace + "/" + owner
+			idx.slices[key] = append(idx.slices[key], slice)
+		}
+	}
+	return idx, nil
+}
+
+func (idx *serviceIndex) serviceInfo(svc *corev1.Service) ServiceInfo {
+	info := ServiceInfo{
+		Name:         svc.Name,
+		Namespace:    svc.Namespace,
+		Type:         string(svc.Spec.Type),
+		ClusterIP:    svc.Spec.ClusterIP,
+		ExternalName: svc.Spec.ExternalName,
+		ExternalIPs:  append([]string(nil), svc.Spec.ExternalIPs...),
+		Ports:        make([]ServicePortInfo, 0, len(svc.Spec.Ports)),
+		Selector:     svc.Spec.Selector,
+		Endpoints:    idx.endpointCounts(svc.Namespace, svc.Name),
+		Age:          formatPodAge(svc.CreationTimestamp.Time),
+	}
+	for _, ing := range svc.Status.LoadBalancer.Ingress {
+		info.LoadBalancer = append(info.LoadBalancer, loadBalancerAddress(ing.IP, ing.Hostname))
+	}
+	for _, p := range svc.Spec.Ports {
+		info.Ports = append(info.Ports, ServicePortInfo{
+			Name:       p.Name,
+			Protocol:   string(p.Protocol),
+			Port:       p.Port,
+			TargetPort: p.TargetPort.String(),
+			NodePort:   p.NodePort,
+		})
+	}
+	info.NoMatchingPods = len(svc.Spec.Selector) > 0 && len(idx.matchingPods(svc)) == 0
+	return info
+}
+
+// matchingPods returns the pods selected by the service, sorted by name. A
+// service without a selector selects no pods; its endpoints are managed by hand.
+func (idx *serviceIndex) matchingPods(svc *corev1.Service) []corev1.Pod {
+	if len(svc.Spec.Selector) == 0 {
+		return nil
+	}
+	selector := labels.SelectorFromSet(svc.Spec.Selector)
+	var pods []corev1.Pod
+	for _, pod := range idx.pods[svc.Namespace] {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			pods = append(pods, pod)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	return pods
+}
+
+// endpointCounts counts the service's endpoints across its EndpointSlices. An
+// endpoint that appears in one slice per address family counts once.
+func (idx *serviceIndex) endpointCounts(namespace, name string) EndpointCounts {
+	var counts EndpointCounts
+	seen := make(map[string]bool)
+	for _, slice := range idx.slices[namespace+"/"+name] {
+		for _, ep := range slice.Endpoints {
+			key := strings.Join(ep.Addresses, ",")
+			if ep.TargetRef != nil {
+				key = ep.TargetRef.Kind + "/" + ep.TargetRef.Name
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			// A nil ready condition means the endpoint is ready.
+			if ep.Conditions.Ready == nil || *ep.Conditions.Ready {
+				counts.Ready++
+			} else {
+				counts.NotReady++
+			}
+		}
+	}
+	return counts
+}
+
+// loadBalancerAddress returns the IP of a load balancer ingress point, or its
+// hostname when it has no IP.
+func loadBalancerAddress(ip, hostname string) string {
+	if ip != "" {
+		return ip
+	}
+	return hostname
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// withNetworkClientset injects cs for service and ingress endpoints.
+func withNetworkClientset(t *testing.T, cs kubernetes.Interface) {
+	t.Helper()
+	old := getNetworkClientset
+	getNetworkClientset = func() (kubernetes.Interface, error) { return cs, nil }
+	t.Cleanup(func() { getNetworkClientset = old })
+}
+
+func newTestService(name string, selector map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: "10.0.0.10",
+			Selector:  selector,
+			Ports: []corev1.ServicePort{{
+				Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromString("web"),
+			}},
+		},
+	}
+}
+
+func newLabeledPod(name string, podLabels map[string]string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: podLabels},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			PodIP:      "10.1.0.1",
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+// newTestEndpointSlice returns a slice of the service with one endpoint per pod;
+// ready maps pod names to their ready condition.
+func newTestEndpointSlice(name, service string, family discoveryv1.AddressType, ready map[string]bool) *discoveryv1.EndpointSlice {
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{discoveryv1.LabelServiceName: service}},
+		AddressType: family,
+	}
+	for pod, isReady := range ready {
+		isReady := isReady
+		slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{
+			Addresses:  []string{pod + "-" + string(family)},
+			Conditions: discoveryv1.EndpointConditions{Ready: &isReady},
+			TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: pod},
+		})
+	}
+	return slice
+}
+
+func TestGetServicesData(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		newTestService("web", map[string]string{"app": "web"}),
+		newTestService("orphan", map[string]string{"app": "gone"}),
+		newTestService("external", nil),
+		newLabeledPod("web-1", map[string]string{"app": "web"}, true),
+		newLabeledPod("web-2", map[string]string{"app": "web"}, false),
+		newTestEndpointSlice("web-v4", "web", discoveryv1.AddressTypeIPv4, map[string]bool{"web-1": true, "web-2": false}),
+		newTestEndpointSlice("web-v6", "web", discoveryv1.AddressTypeIPv6, map[string]bool{"web-1": true, "web-2": false}),
+	)
+
+	services, err := getServicesData(context.Background(), cs, "default")
+	if err != nil {
+		t.Fatalf("getServicesData: %v", err)
+	}
+	if len(services) != 3 || services[0].Name != "external" || services[1].Name != "orphan" || services[2].Name != "web" {
+		t.Fatalf("unexpected services: %+v", services)
+	}
+
+	web := services[2]
+	if web.Endpoints != (EndpointCounts{Ready: 1, NotReady: 1}) {
+		t.Errorf("dual-stack endpoints should be counted once: %+v", web.Endpoints)
+	}
+	if web.NoMatchingPods || web.Type != "ClusterIP" || len(web.Ports) != 1 || web.Ports[0].TargetPort != "web" {
+		t.Errorf("unexpected web service: %+v", web)
+	}
+	if !services[1].NoMatchingPods {
+		t.Error("service whose selector matches no pods should be flagged")
+	}
+	if services[0].NoMatchingPods {
+		t.Error("service without a selector should not be flagged")
+	}
+}
+
+func TestServiceInfo_LoadBalancerAddresses(t *testing.T) {
+	svc := newTestService("lb", nil)
+	svc.Spec.Type = corev1.ServiceTypeLoadBalancer
+	svc.Spec.ExternalIPs = make([]string, 1, 4)
+	svc.Spec.ExternalIPs[0] = "203.0.113.7"
+	svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "198.51.100.1"}, {Hostname: "lb.example.com"}}
+
+	info := (&serviceIndex{}).serviceInfo(svc)
+	if len(info.ExternalIPs) != 1 || info.ExternalIPs[0] != "203.0.113.7" {
+		t.Errorf("externalIPs = %v", info.ExternalIPs)
+	}
+	if len(info.LoadBalancer) != 2 || info.LoadBalancer[0] != "198.51.100.1" || info.LoadBalancer[1] != "lb.example.com" {
+		t.Errorf("loadBalancer = %v", info.LoadBalancer)
+	}
+	info.ExternalIPs = append(info.ExternalIPs, "192.0.2.1")
+	if extra := svc.Spec.ExternalIPs[:2][1]; extra != "" {
+		t.Errorf("service spec backing array was written: %q", extra)
+	}
+}
+
+func TestServiceDetailHandler(t *testing.T) {
+	pathType := networkingv1.PathTypePrefix
+	withNetworkClientset(t, fake.NewSimpleClientset(
+		newTestService("web", map[string]string{"app": "web"}),
+		newLabeledPod("web-1", map[string]string{"app": "web"}, true),
+		newLabeledPod("other", map[string]string{"app": "other"}, true),
+		newTestEndpointSlice("web-v4", "web", discoveryv1.AddressTypeIPv4, map[string]bool{"web-1": true}),
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "site"},
+			Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{{
+					Path: "/", PathType: &pathType,
+					Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Name: "http"}}},
+				}}}},
+			}}},
+		},
+	))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/services/default/web", nil)
+	w := httptest.NewRecorder()
+	ServiceDetailHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var detail ServiceDetail
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(detail.Pods) != 1 || detail.Pods[0].Name != "web-1" || !detail.Pods[0].Ready {
+		t.Errorf("unexpected pods: %+v", detail.Pods)
+	}
+	if len(detail.EndpointList) != 1 || detail.EndpointList[0].Pod != "web-1" || !detail.EndpointList[0].Ready {
+		t.Errorf("unexpected endpoints: %+v", detail.EndpointList)
+	}
+	if len(detail.Ingresses) != 1 || detail.Ingresses[0] != "site" {
+		t.Errorf("unexpected ingresses: %v", detail.Ingresses)
+	}
+}
+
+func TestServiceDetailHandler_NotFound(t *testing.T) {
+	withNetworkClientset(t, fake.NewSimpleClientset())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/services/default/missing", nil)
+	w := httptest.NewRecorder()
+	ServiceDetailHandler(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
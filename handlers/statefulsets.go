@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// StatefulSetInfo represents summary information about a statefulset
+type StatefulSetInfo struct {
+	Name              string `json:"name"`
+	Namespace         string `json:"namespace"`
+	Replicas          int32  `json:"replicas"`
+	CurrentReplicas   int32  `json:"currentReplicas"`
+	ReadyReplicas     int32  `json:"readyReplicas"`
+	UpdatedReplicas   int32  `json:"updatedReplicas"`
+	AvailableReplicas int32  `json:"availableReplicas"`
+}
+
+// StatefulSetDetail is the response of GET /api/statefulsets/{ns}/{name}.
+type StatefulSetDetail struct {
+	StatefulSetInfo
+	Labels              map[string]string   `json:"labels"`
+	Selector            map[string]string   `json:"selector"`
+	ServiceName         string              `json:"serviceName"`
+	PodManagementPolicy string              `json:"podManagementPolicy"`
+	UpdateStrategy      string              `json:"updateStrategy"`
+	Partition           *int32              `json:"partition,omitempty"`
+	CurrentRevision     string              `json:"currentRevision"`
+	UpdateRevision      string              `json:"updateRevision"`
+	Images              []string            `json:"images"`
+	Conditions          []WorkloadCondition `json:"conditions"`
+	CreatedAt           time.Time           `json:"createdAt"`
+	Pods                []PodDetails        `json:"pods"`
+}
+
+// StatefulSetsHandler handles the GET /api/statefulsets endpoint
+var StatefulSetsHandler = handleGet("Failed to fetch statefulsets data", func(r *http.Request) (interface{}, error) {
+	clientset, err := getDeploymentClientset()
+	if err != nil {
+		return nil, err
+	}
+	return getStatefulSetsData(r.Context(), clientset, r.URL.Query().Get("ns"))
+})
+
+func getStatefulSetsData(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]StatefulSetInfo, error) {
+	list, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	statefulSets := make([]StatefulSetInfo, 0, len(list.Items))
+	for i := range list.Items {
+		statefulSets = append(statefulSets, buildStatefulSetInfo(&list.Items[i]))
+	}
+	return statefulSets, nil
+}
+
+func buildStatefulSetInfo(s *appsv1.StatefulSet) StatefulSetInfo {
+	replicas := int32(1)
+	if s.Spec.Replicas != nil {
+		replicas = *s.Spec.Replicas
+	}
+	return StatefulSetInfo{
+		Name:              s.Name,
+		Namespace:         s.Namespace,
+		Replicas:          replicas,
+		CurrentReplicas:   s.Status.CurrentReplicas,
+		ReadyReplicas:     s.Status.ReadyReplicas,
+		UpdatedReplicas:   s.Status.UpdatedReplicas,
+		AvailableReplicas: s.Status.AvailableReplicas,
+	}
+}
+
+func getStatefulSetDetail(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (interface{}, error) {
+	s, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := listWorkloadPods(ctx, clientset, &workload{ObjectMeta: s.ObjectMeta, Selector: s.Spec.Selector})
+	if err != nil {
+		return nil, err
+	}
+
+	detail := StatefulSetDetail{
+		StatefulSetInfo:     buildStatefulSetInfo(s),
+		Labels:              s.Labels,
+		ServiceName:         s.Spec.ServiceName,
+		PodManagementPolicy: string(s.Spec.PodManagementPolicy),
+		UpdateStrategy:      string(s.Spec.UpdateStrategy.Type),
+		CurrentRevision:     s.Status.CurrentRevision,
+		UpdateRevision:      s.Status.UpdateRevision,
+		Images:              templateImages(s.Spec.Template),
+		Conditions:          make([]WorkloadCondition, 0, len(s.Status.Conditions)),
+		CreatedAt:           s.CreationTimestamp.Time,
+		Pods:                pods,
+	}
+	if s.Spec.Selector != nil {
+		detail.Selector = s.Spec.Selector.MatchLabels
+	}
+	if ru := s.Spec.UpdateStrategy.RollingUpdate; ru != nil {
+		detail.Partition = ru.Partition
+	}
+	for _, c := range s.Status.Conditions {
+		detail.Conditions = append(detail.Conditions, WorkloadCondition{
+			Type:               string(c.Type),
+			Status:             string(c.Status),
+			Reason:             c.Reason,
+			Message:            c.Message,
+			LastTransitionTime: c.LastTransitionTime.Time,
+		})
+	}
+	return detail, nil
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newControlledPod returns a pod labelled app=web and controlled by the given owner.
+func newControlledPod(name, ownerKind string, ownerUID types.UID) *corev1.Pod {
+	controller := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: name, Labels: map[string]string{"app": "web"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: ownerKind, Name: "owner", UID: ownerUID, Controller: &controller}},
+		},
+		Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func newTestStatefulSet() *appsv1.StatefulSet {
+	replicas := int32(3)
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db", UID: types.UID("db-uid")},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: "db-headless",
+			Selector:    &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template:    podTemplate("postgres:16"),
+		},
+		Status: appsv1.StatefulSetStatus{CurrentReplicas: 2, ReadyReplicas: 2, UpdatedReplicas: 1, AvailableReplicas: 2},
+	}
+}
+
+func TestStatefulSetsHandler(t *testing.T) {
+	withDeploymentClientset(t, fake.NewSimpleClientset(newTestStatefulSet()))
+
+	w := httptest.NewRecorder()
+	StatefulSetsHandler(w, httptest.NewRequest(http.MethodGet, "/api/statefulsets?ns=default", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var list []StatefulSetInfo
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	want := StatefulSetInfo{Name: "db", Namespace: "default", Replicas: 3, CurrentReplicas: 2, ReadyReplicas: 2, UpdatedReplicas: 1, AvailableReplicas: 2}
+	if len(list) != 1 || list[0] != want {
+		t.Errorf("got %+v, want %+v", list, want)
+	}
+}
+
+func TestStatefulSetDetail(t *testing.T) {
+	s := newTestStatefulSet()
+	withDeploymentClientset(t, fake.NewSimpleClientset(s,
+		newControlledPod("db-1", "StatefulSet", s.UID),
+		newControlledPod("db-0", "StatefulSet", s.UID),
+		newControlledPod("stray", "ReplicaSet", "other-uid"),
+	))
+
+	w := httptest.NewRecorder()
+	StatefulSetActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/statefulsets/default/db", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var detail StatefulSetDetail
+	if err := json.NewDecoder(w.Body).Decode(&detail); err != nil {
+		t.Fatal(err)
+	}
+	if detail.ServiceName != "db-headless" || detail.Images[0] != "postgres:16" || detail.Selector["app"] != "web" {
+		t.Errorf("unexpected detail: %+v", detail)
+	}
+	if len(detail.Pods) != 2 || detail.Pods[0].Name != "db-0" || detail.Pods[1].Name != "db-1" {
+		t.Errorf("expected the two owned pods in name order, got %+v", detail.Pods)
+	}
+
+	w = httptest.NewRecorder()
+	StatefulSetActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/statefulsets/default/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing statefulset: expected 404, got %d", w.Code)
+	}
+}
+
+func TestStatefulSetRestart(t *testing.T) {
+	cs := fake.NewSimpleClientset(newTestStatefulSet())
+	withDeploymentClientset(t, cs)
+
+	w := httptest.NewRecorder()
+	StatefulSetActionsHandler(w, httptest.NewRequest(http.MethodPost, "/api/statefulsets/default/db/restart", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	s, _ := cs.AppsV1().StatefulSets("default").Get(context.Background(), "db", metav1.GetOptions{})
+	if s.Spec.Template.Annotations[annotationRestartedAt] == "" {
+		t.Error("restartedAt annotation not set on the pod template")
+	}
+	if s.Spec.Template.Spec.Containers[0].Image != "postgres:16" {
+		t.Error("restart must not change the rest of the template")
+	}
+
+	w = httptest.NewRecorder()
+	StatefulSetActionsHandler(w, httptest.NewRequest(http.MethodGet, "/api/statefulsets/default/db/restart", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET restart: expected 405, got %d", w.Code)
+	}
+}
@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxClaimEvents caps how many recent events are attached to a flagged claim.
+const maxClaimEvents = 10
+
+// kubeletStatsTimeout bounds each kubelet stats request, so one unreachable node
+// does not use up the whole request timeout.
+const kubeletStatsTimeout = 5 * time.Second
+
+// kubeletStatsConcurrency caps how many kubelets are queried for volume stats at
+// once, so large clusters finish within the request timeout without flooding the
+// API server proxy.
+const kubeletStatsConcurrency = 8
+
+// annotationDefaultStorageClass marks the cluster's default StorageClass.
+const annotationDefaultStorageClass = "storageclass.kubernetes.io/is-default-class"
+
+// getStorageClientset is a package-level variable for obtaining the Kubernetes
+// client used by the storage endpoints. Tests may override this.
+var getStorageClientset func() (kubernetes.Interface, error) = func() (kubernetes.Interface, error) {
+	return getKubernetesClient()
+}
+
+// getNodeVolumeStats returns the usage of the PVC-backed volumes mounted on a
+// node, keyed by namespace/claim, from the kubelet stats summary reached through
+// the API server's node proxy. Tests may override this.
+var getNodeVolumeStats = func(ctx context.Context, clientset kubernetes.Interface, nodeName string) (map[string]VolumeUsage, error) {
+	data, err := clientset.CoreV1().RESTClient().Get().
+		Resource("nodes").Name(nodeName).SubResource("proxy").Suffix("stats", "summary").
+		DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var summary kubeletStatsSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]VolumeUsage)
+	for _, pod := range summary.Pods {
+		for _, v := range pod.Volumes {
+			if v.PVCRef == nil || v.CapacityBytes == nil || v.UsedBytes == nil {
+				continue
+			}
+			u := VolumeUsage{CapacityBytes: int64(*v.CapacityBytes), UsedBytes: int64(*v.UsedBytes)}
+			if v.AvailableBytes != nil {
+				u.AvailableBytes = int64(*v.AvailableBytes)
+			}
+			if u.CapacityBytes > 0 {
+				u.UsedPercent = float64(u.UsedBytes) / float64(u.CapacityBytes) * 100
+			}
+			usage[v.PVCRef.Namespace+"/"+v.PVCRef.Name] = u
+		}
+	}
+	return usage, nil
+}
+
+// kubeletStatsSummary is the part of the kubelet /stats/summary response that
+// reports volume usage.
+type kubeletStatsSummary struct {
+	Pods []struct {
+		Volumes []kubeletVolumeStats `json:"volume"`
+	} `json:"pods"`
+}
+
+type kubeletVolumeStats struct {
+	PVCRef *struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"pvcRef"`
+	CapacityBytes  *uint64 `json:"capacityBytes"`
+	UsedBytes      *uint64 `json:"usedBytes"`
+	AvailableBytes *uint64 `json:"availableBytes"`
+}
+
+// VolumeUsage is the filesystem usage of a mounted volume as seen by the kubelet.
+type VolumeUsage struct {
+	CapacityBytes  int64   `json:"capacityBytes"`
+	UsedBytes      int64   `json:"usedBytes"`
+	AvailableBytes int64   `json:"availableBytes"`
+	UsedPercent    float64 `json:"usedPercent"`
+}
+
+// PVCInfo represents summary information about a persistent volume claim
+type PVCInfo struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Phase     string `json:"phase"`
+	// Capacity is the bound volume's size; Requested is the size the claim asks for.
+	Capacity      string   `json:"capacity,omitempty"`
+	CapacityBytes int64    `json:"capacityBytes"`
+	Requested     string   `json:"requested,omitempty"`
+	AccessModes   []string `json:"accessModes"`
+	StorageClass  string   `json:"storageClass,omitempty"`
+	VolumeName    string   `json:"volumeName,omitempty"`
+	VolumeMode    string   `json:"volumeMode,omitempty"`
+	// Pods are the pods mounting the claim.
+	Pods []string `json:"pods"`
+	// Flagged is set for Pending and Lost claims; Events then explains why.
+	Flagged bool            `json:"flagged"`
+	Events  []ResourceEvent `json:"events,omitempty"`
+	// Usage is reported when a running pod mounts the claim and its node's
+	// kubelet stats are reachable.
+	Usage *VolumeUsage `json:"usage,omitempty"`
+	Age   string       `json:"age"`
+}
+
+// PVInfo represents summary information about a persistent volume
+type PVInfo struct {
+	Name          string   `json:"name"`
+	Phase         string   `json:"phase"`
+	Capacity      string   `json:"capacity,omitempty"`
+	CapacityBytes int64    `json:"capacityBytes"`
+	AccessModes   []string `json:"accessModes"`
+	ReclaimPolicy string   `json:"reclaimPolicy"`
+	StorageClass  string   `json:"storageClass,omitempty"`
+	VolumeMode    string   `json:"volumeMode,omitempty"`
+	// Claim is the namespace/name of the claim the volume is bound or reserved to.
+	Claim   string `json:"claim,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	Age     string `json:"age"`
+}
+
+// StorageClassInfo represents summary information about a storage class
+type StorageClassInfo struct {
+	Name                 string `json:"name"`
+	Provisioner          string `json:"provisioner"`
+	ReclaimPolicy        string `json:"reclaimPolicy,omitempty"`
+	VolumeBindingMode    string `json:"volumeBindingMode,omitempty"`
+	AllowVolumeExpansion bool   `json:"allowVolumeExpansion"`
+	Default              bool   `json:"default"`
+	Age                  string `json:"age"`
+}
+
+// PersistentVolumeClaimsHandler handles the GET /api/persistentvolumeclaims endpoint
+var PersistentVolumeClaimsHandler = handleGet("Failed to fetch persistent volume claims data", func(r *http.Request) (interface{}, error) {
+	clientset, err := getStorageClientset()
+	if err != nil {
+		return nil, err
+	}
+	return getPVCsData(r.Context(), clientset, r.URL.Query().Get("ns"))
+})
+
+// PersistentVolumesHandler handles the GET /api/persistentvolumes endpoint
+var PersistentVolumesHandler = handleGet("Failed to fetch persistent volumes data", func(r *http.Request) (interface{}, error) {
+	clientset, err := getStorageClientset()
+	if err != nil {
+		return nil, err
+	}
+	return getPVsData(r.Context(), clientset)
+})
+
+// StorageClassesHandler handles the GET /api/storageclasses endpoint
+var StorageClassesHandler = handleGet("Failed to fetch storage classes data", func(r *http.Request) (interface{}, error) {
+	clientset, err := getStorageClientset()
+	if err != nil {
+		return nil, err
+	}
+	return getStorageClassesData(r.Context(), clientset)
+})
+
+func getPVCsData(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]PVCInfo, error) {
+	list, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	podList, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Claims are keyed by namespace/name; nodes are those running a consuming pod.
+	consumers := make(map[string][]string)
+	nodes := make(map[string]bool)
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		for _, claim := range podClaimNames(pod) {
+			key := pod.Namespace + "/" + claim
+			consumers[key] = append(consumers[key], pod.Name)
+			if pod.Status.Phase == corev1.PodRunning && pod.Spec.NodeName != "" {
+				nodes[pod.Spec.NodeName] = true
+			}
+		}
+	}
+
+	claims := make([]PVCInfo, 0, len(list.Items))
+	flagged := false
+	for i := range list.Items {
+		info := buildPVCInfo(&list.Items[i])
+		info.Pods = consumers[info.Namespace+"/"+info.Name]
+		if info.Pods == nil {
+			info.Pods = []string{}
+		}
+		sort.Strings(info.Pods)
+		flagged = flagged || info.Flagged
+		claims = append(claims, info)
+	}
+
+	if flagged {
+		events, err := listClaimEvents(ctx, clientset, namespace)
+		if err != nil {
+			slog.Warn("Failed to list claim events", "error", err)
+		}
+		for i := range claims {
+			if claims[i].Flagged {
+				claims[i].Events = events[claims[i].Namespace+"/"+claims[i].Name]
+			}
+		}
+	}
+
+	usage := collectVolumeUsage(ctx, clientset, nodes)
+	for i := range claims {
+		if u, ok := usage[claims[i].Namespace+"/"+claims[i].Name]; ok {
+			claims[i].Usage = &u
+		}
+	}
+
+	sort.Slice(claims, func(i, j int) bool {
+		if claims[i].Namespace != claims[j].Namespace {
+			return claims[i].Namespace < claims[j].Namespace
+		}
+		return claims[i].Name < claims[j].Name
+	})
+	return claims, nil
+}
+
+// podClaimNames returns the claims a pod mounts, including the claims created
+// for its generic ephemeral volumes.
+func podClaimNames(pod *corev1.Pod) []string {
+	var claims []string
+	for _, v := range pod.Spec.Volumes {
+		switch {
+		case v.PersistentVolumeClaim != nil:
+			claims = append(claims, v.PersistentVolumeClaim.ClaimName)
+		case v.Ephemeral != nil:
+			claims = append(claims, pod.Name+"-"+v.Name)
+		}
+	}
+	return claims
+}
+
+// collectVolumeUsage gathers volume usage from the kubelets of the given nodes.
+// Usage is best effort: unreachable kubelets are logged and skipped.
+func collectVolumeUsage(ctx context.Context, clientset kubernetes.Interface, nodes map[string]bool) map[string]VolumeUsage {
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		usage = make(map[string]VolumeUsage)
+		sem   = make(chan struct{}, kubeletStatsConcurrency)
+	)
+	for node := range nodes {
+		wg.Add(1)
+		go func(node string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			nodeCtx, cancel := context.WithTimeout(ctx, kubeletStatsTimeout)
+			stats, err := getNodeVolumeStats(nodeCtx, clientset, node)
+			cancel()
+			if err != nil {
+				slog.Debug("Kubelet volume stats unavailable", "node", node, "error", err)
+				return
+			}
+			mu.Lock()
+			for claim, u := range stats {
+				usage[claim] = u
+			}
+			mu.Unlock()
+		}(node)
+	}
+	wg.Wait()
+	return usage
+}
+
+// listClaimEvents returns the recent events of the claims in the namespace (all
+// namespaces if empty), newest first and keyed by namespace/name.
+func listClaimEvents(ctx context.Context, clientset kubernetes.Interface, namespace string) (map[string][]ResourceEvent, error) {
+	eventList, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.kind", "PersistentVolumeClaim").String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := eventList.Items
+	sort.SliceStable(items, func(i, j int) bool { return eventTime(items[i]).After(eventTime(items[j])) })
+	events := make(map[string][]ResourceEvent)
+	for _, ev := range items {
+		if ev.InvolvedObject.Kind != "PersistentVolumeClaim" {
+			continue
+		}
+		key := ev.InvolvedObject.Namespace + "/" + ev.InvolvedObject.Name
+		if len(events[key]) < maxClaimEvents {
+			events[key] = append(events[key], buildResourceEvent(ev))
+		}
+	}
+	return events, nil
+}
+
+func buildPVCInfo(pvc *corev1.PersistentVolumeClaim) PVCInfo {
+	info := PVCInfo{
+		Name:        pvc.Name,
+		Namespace:   pvc.Namespace,
+		Phase:       string(pvc.Status.Phase),
+		AccessModes: accessModeStrings(pvc.Status.AccessModes),
+		VolumeName:  pvc.Spec.VolumeName,
+		Flagged:     pvc.Status.Phase == corev1.ClaimPending || pvc.Status.Phase == corev1.ClaimLost,
+		Age:         formatPodAge(pvc.CreationTimestamp.Time),
+	}
+	if len(pvc.Status.AccessModes) == 0 {
+		info.AccessModes = accessModeStrings(pvc.Spec.AccessModes)
+	}
+	if q, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		info.Capacity = q.String()
+		info.CapacityBytes = q.Value()
+	}
+	if q, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		info.Requested = q.String()
+	}
+	if pvc.Spec.StorageClassName != nil {
+		info.StorageClass = *pvc.Spec.StorageClassName
+	}
+	if pvc.Spec.VolumeMode != nil {
+		info.VolumeMode = string(*pvc.Spec.VolumeMode)
+	}
+	return info
+}
+
+func getPVsData(ctx context.Context, clientset kubernetes.Interface) ([]PVInfo, error) {
+	list, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	volumes := make([]PVInfo, 0, len(list.Items))
+	for _, pv := range list.Items {
+		info := PVInfo{
+			Name:          pv.Name,
+			Phase:         string(pv.Status.Phase),
+			AccessModes:   accessModeStrings(pv.Spec.AccessModes),
+			ReclaimPolicy: string(pv.Spec.PersistentVolumeReclaimPolicy),
+			StorageClass:  pv.Spec.StorageClassName,
+			Reason:        pv.Status.Reason,
+			Message:       pv.Status.Message,
+			Age:           formatPodAge(pv.CreationTimestamp.Time),
+		}
+		if q, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
+			info.Capacity = q.String()
+			info.CapacityBytes = q.Value()
+		}
+		if pv.Spec.VolumeMode != nil {
+			info.VolumeMode = string(*pv.Spec.VolumeMode)
+		}
+		if ref := pv.Spec.ClaimRef; ref != nil {
+			info.Claim = ref.Namespace + "/" + ref.Name
+		}
+		volumes = append(volumes, info)
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
+	return volumes, nil
+}
+
+func getStorageClassesData(ctx context.Context, clientset kubernetes.Interface) ([]StorageClassInfo, error) {
+	list, err := clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	classes := make([]StorageClassInfo, 0, len(list.Items))
+	for _, sc := range list.Items {
+		classes = append(classes, buildStorageClassInfo(&sc))
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Name < classes[j].Name })
+	return classes, nil
+}
+
+func buildStorageClassInfo(sc *storagev1.StorageClass) StorageClassInfo {
+	info := StorageClassInfo{
+		Name:                 sc.Name,
+		Provisioner:          sc.Provisioner,
+		AllowVolumeExpansion: sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion,
+		Default:              sc.Annotations[annotationDefaultStorageClass] == "true",
+		Age:                  formatPodAge(sc.CreationTimestamp.Time),
+	}
+	if sc.ReclaimPolicy != nil {
+		info.ReclaimPolicy = string(*sc.ReclaimPolicy)
+	}
+	if sc.VolumeBindingMode != nil {
+		info.VolumeBindingMode = string(*sc.VolumeBindingMode)
+	}
+	return info
+}
+
+func accessModeStrings(modes []corev1.PersistentVolumeAccessMode) []string {
+	out := make([]string, 0, len(modes))
+	for _, m := range modes {
+		out = append(out, string(m))
+	}
+	return out
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// withNodeVolumeStats replaces kubelet stats lookups with stats, keyed by node;
+// nodes missing from stats are unreachable.
+func withNodeVolumeStats(t *testing.T, stats map[string]map[string]VolumeUsage) {
+	t.Helper()
+	old := getNodeVolumeStats
+	getNodeVolumeStats = func(_ context.Context, _ kubernetes.Interface, node string) (map[string]VolumeUsage, error) {
+		if s, ok := stats[node]; ok {
+			return s, nil
+		}
+		return nil, errors.New("node proxy unavailable")
+	}
+	t.Cleanup(func() { getNodeVolumeStats = old })
+}
+
+func newTestPVC(name string, phase corev1.PersistentVolumeClaimPhase) *corev1.PersistentVolumeClaim {
+	storageClass := "standard"
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &storageClass,
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceStorage: resource.MustParse("1Gi"),
+			}},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: phase},
+	}
+	if phase == corev1.ClaimBound {
+		pvc.Spec.VolumeName = "pv-" + name
+		pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("2Gi")}
+	}
+	return pvc
+}
+
+func newClaimPod(name, node string, claims ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	for _, claim := range claims {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name:         claim,
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim}},
+		})
+	}
+	return pod
+}
+
+func newClaimEvent(name, claim, reason string, lastSeen time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: name},
+		InvolvedObject: corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "default", Name: claim},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		LastTimestamp:  metav1.Time{Time: lastSeen},
+	}
+}
+
+func TestGetPVCsData(t *testing.T) {
+	now := time.Now()
+	withNodeVolumeStats(t, map[string]map[string]VolumeUsage{
+		"node-1": {"default/data": {CapacityBytes: 2000, UsedBytes: 500, AvailableBytes: 1500, UsedPercent: 25}},
+	})
+	cs := fake.NewSimpleClientset(
+		newTestPVC("data", corev1.ClaimBound),
+		newTestPVC("cache", corev1.ClaimBound),
+		newTestPVC("waiting", corev1.ClaimPending),
+		newClaimPod("db-0", "node-1", "data"),
+		newClaimPod("db-1", "node-2", "data", "cache"),
+		newClaimEvent("old", "waiting", "ExternalProvisioning", now.Add(-time.Hour)),
+		newClaimEvent("new", "waiting", "ProvisioningFailed", now),
+		newClaimEvent("bound", "data", "ProvisioningSucceeded", now),
+	)
+
+	claims, err := getPVCsData(context.Background(), cs, "default")
+	if err != nil {
+		t.Fatalf("getPVCsData: %v", err)
+	}
+	if len(claims) != 3 || claims[0].Name != "cache" || claims[1].Name != "data" || claims[2].Name != "waiting" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+
+	data := claims[1]
+	if data.Phase != "Bound" || data.Capacity != "2Gi" || data.Requested != "1Gi" || data.StorageClass != "standard" ||
+		len(data.AccessModes) != 1 || data.AccessModes[0] != "ReadWriteOnce" || data.VolumeName != "pv-data" {
+		t.Errorf("unexpected bound claim: %+v", data)
+	}
+	if len(data.Pods) != 2 || data.Pods[0] != "db-0" || data.Pods[1] != "db-1" {
+		t.Errorf("unexpected consuming pods: %v", data.Pods)
+	}
+	if data.Flagged || len(data.Events) != 0 {
+		t.Errorf("bound claim should not be flagged: %+v", data)
+	}
+	if data.Usage == nil || data.Usage.UsedBytes != 500 || data.Usage.UsedPercent != 25 {
+		t.Errorf("unexpected usage: %+v", data.Usage)
+	}
+	if claims[0].Usage != nil {
+		t.Error("usage from an unreachable kubelet should be omitted")
+	}
+
+	waiting := claims[2]
+	if !waiting.Flagged || len(waiting.Pods) != 0 {
+		t.Errorf("pending claim should be flagged: %+v", waiting)
+	}
+	if len(waiting.Events) != 2 || waiting.Events[0].Reason != "ProvisioningFailed" {
+		t.Errorf("expected events newest first: %+v", waiting.Events)
+	}
+}
+
+func TestGetPVsData(t *testing.T) {
+	reclaim := corev1.PersistentVolumeReclaimRetain
+	cs := fake.NewSimpleClientset(
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-data"},
+			Spec: corev1.PersistentVolumeSpec{
+				Capacity:                      corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("2Gi")},
+				AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				PersistentVolumeReclaimPolicy: reclaim,
+				StorageClassName:              "standard",
+				ClaimRef:                      &corev1.ObjectReference{Namespace: "default", Name: "data"},
+			},
+			Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+		},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-free"},
+			Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+		},
+	)
+
+	volumes, err := getPVsData(context.Background(), cs)
+	if err != nil {
+		t.Fatalf("getPVsData: %v", err)
+	}
+	if len(volumes) != 2 {
+		t.Fatalf("expected 2 volumes, got %d", len(volumes))
+	}
+	bound := volumes[0]
+	if bound.Claim != "default/data" || bound.ReclaimPolicy != "Retain" || bound.Capacity != "2Gi" || bound.CapacityBytes != 2<<30 {
+		t.Errorf("unexpected bound volume: %+v", bound)
+	}
+	if free := volumes[1]; free.Claim != "" || free.Phase != "Available" {
+		t.Errorf("unexpected available volume: %+v", free)
+	}
+}
+
+func TestCollectVolumeUsage_QueriesNodesConcurrently(t *testing.T) {
+	nodes := make(map[string]bool)
+	for i := 0; i < 3*kubeletStatsConcurrency; i++ {
+		nodes[fmt.Sprintf("node-%d", i)] = true
+	}
+
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	old := getNodeVolumeStats
+	getNodeVolumeStats = func(_ context.Context, _ kubernetes.Interface, node string) (map[string]VolumeUsage, error) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		if node == "node-0" {
+			return nil, errors.New("node proxy unavailable")
+		}
+		return map[string]VolumeUsage{"default/" + node: {UsedBytes: 1}}, nil
+	}
+	t.Cleanup(func() { getNodeVolumeStats = old })
+
+	usage := collectVolumeUsage(context.Background(), fake.NewSimpleClientset(), nodes)
+	if len(usage) != len(nodes)-1 {
+		t.Errorf("usage entries = %d, want %d", len(usage), len(nodes)-1)
+	}
+	if peak < 2 || peak > kubeletStatsConcurrency {
+		t.Errorf("peak concurrent kubelet queries = %d, want between 2 and %d", peak, kubeletStatsConcurrency)
+	}
+}
+
+func TestGetStorageClassesData(t *testing.T) {
+	expand := true
+	cs := fake.NewSimpleClientset(
+		&storagev1.StorageClass{
+			ObjectMeta:           metav1.ObjectMeta{Name: "standard", Annotations: map[string]string{annotationDefaultStorageClass: "true"}},
+			Provisioner:          "ebs.csi.aws.com",
+			AllowVolumeExpansion: &expand,
+		},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "local"}, Provisioner: "kubernetes.io/no-provisioner"},
+	)
+
+	classes, err := getStorageClassesData(context.Background(), cs)
+	if err != nil {
+		t.Fatalf("getStorageClassesData: %v", err)
+	}
+	if len(classes) != 2 || classes[0].Name != "local" || classes[0].Default {
+		t.Fatalf("unexpected classes: %+v", classes)
+	}
+	if standard := classes[1]; !standard.Default || !standard.AllowVolumeExpansion || standard.Provisioner != "ebs.csi.aws.com" {
+		t.Errorf("unexpected standard class: %+v", standard)
+	}
+}
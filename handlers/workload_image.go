@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// imageUpdateRequest is the JSON body for PUT /api/{kind}/{ns}/{name}/image.
+type imageUpdateRequest struct {
+	// Images maps container names (regular or init) to their new image.
+	Images map[string]string `json:"images"`
+	// ChangeCause is recorded in the kubernetes.io/change-cause annotation.
+	// It defaults to a kubectl-style "set image" description.
+	ChangeCause string `json:"changeCause,omitempty"`
+}
+
+// ImageUpdateResponse reports the result of an image update.
+type ImageUpdateResponse struct {
+	Kind        string            `json:"kind"`
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Images      map[string]string `json:"images"`
+	ChangeCause string            `json:"changeCause,omitempty"`
+	// Changed is false when every container already ran the requested image.
+	Changed bool `json:"changed"`
+	// Revision is the rollout revision the controller assigns to the updated
+	// pod template; it equals PreviousRevision when nothing changed.
+	Revision         int64 `json:"revision"`
+	PreviousRevision int64 `json:"previousRevision"`
+	// Generation can be passed to the rollout status stream of deployments.
+	Generation int64  `json:"generation"`
+	Message    string `json:"message"`
+}
+
+// DeploymentImageHandler handles PUT /api/deployments/{ns}/{name}/image.
+func DeploymentImageHandler(w http.ResponseWriter, r *http.Request) {
+	handleWorkloadImage(w, r, workloadDeployment)
+}
+
+// handleWorkloadImage sets container images of a workload with a strategic merge patch.
+func handleWorkloadImage(w http.ResponseWriter, r *http.Request, kind workloadKind) {
+	if !requireMethod(w, r, http.MethodPut) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, clientset := parseWorkloadAction(w, r, kind.pathPrefix, imagePathSuffix)
+	if clientset == nil {
+		return
+	}
+
+	var req imageUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Images) == 0 {
+		writeError(w, http.StatusBadRequest, errMsgImageInvalidRequest)
+		return
+	}
+	for container, image := range req.Images {
+		if container == "" || strings.TrimSpace(image) == "" {
+			writeError(w, http.StatusBadRequest, errMsgImageInvalidRequest)
+			return
+		}
+	}
+
+	resp, err := updateWorkloadImages(r.Context(), clientset, kind, namespace, name, req)
+	if err != nil {
+		writeWorkloadError(w, err, kind, errMsgImageUpdate)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// updateWorkloadImages validates the requested containers against the pod
+// template and patches the changed images together with the change-cause annotation.
+func updateWorkloadImages(ctx context.Context, clientset kubernetes.Interface, kind workloadKind, namespace, name string, req imageUpdateRequest) (*ImageUpdateResponse, error) {
+	current, err := kind.get(ctx, clientset, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make(map[string]string)
+	initContainers := make(map[string]bool)
+	for _, c := range current.Template.Spec.Containers {
+		images[c.Name] = c.Image
+	}
+	for _, c := range current.Template.Spec.InitContainers {
+		images[c.Name] = c.Image
+		initContainers[c.Name] = true
+	}
+
+	var unknown []string
+	for container := range req.Images {
+		if _, ok := images[container]; !ok {
+			unknown = append(unknown, container)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, &statusError{status: http.StatusBadRequest, message: fmt.Sprintf(
+			"%s %s has no container named %s", kind.kind, name, strings.Join(unknown, ", "))}
+	}
+
+	previous, err := workloadRevision(ctx, clientset, kind, current)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ImageUpdateResponse{
+		Kind:             kind.kind,
+		Name:             name,
+		Namespace:        namespace,
+		Revision:         previous,
+		PreviousRevision: previous,
+		Generation:       current.Generation,
+	}
+
+	var containers, inits []map[string]string
+	var changes []string
+	for _, container := range sortedKeys(req.Images) {
+		image := strings.TrimSpace(req.Images[container])
+		if images[container] == image {
+			continue
+		}
+		images[container] = image
+		changes = append(changes, container+"="+image)
+		entry := map[string]string{"name": container, "image": image}
+		if initContainers[container] {
+			inits = append(inits, entry)
+		} else {
+			containers = append(containers, entry)
+		}
+	}
+	resp.Images = images
+	if len(changes) == 0 {
+		resp.Message = "All containers already run the requested images"
+		return resp, nil
+	}
+
+	cause := req.ChangeCause
+	if cause == "" {
+		cause = "set image " + strings.Join(changes, " ")
+	}
+	podSpec := map[string]interface{}{}
+	if len(containers) > 0 {
+		podSpec["containers"] = containers
+	}
+	if len(inits) > 0 {
+		podSpec["initContainers"] = inits
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]string{annotationChangeCause: cause}},
+		"spec":     map[string]interface{}{"template": map[string]interface{}{"spec": podSpec}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := kind.patch(ctx, clientset, namespace, name, types.StrategicMergePatchType, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Changed = true
+	resp.ChangeCause = cause
+	resp.Revision = previous + 1
+	resp.Generation = updated.Generation
+	resp.Message = fmt.Sprintf("Updated %d container image(s); the rollout will be revision %d", len(changes), resp.Revision)
+	return resp, nil
+}
+
+// workloadRevision returns the workload's latest rollout revision. Deployments
+// record it in annotationRevision; StatefulSets and DaemonSets number their
+// ControllerRevisions. A changed template always becomes latest+1, including
+// when the controller reuses the object of an older identical template.
+func workloadRevision(ctx context.Context, clientset kubernetes.Interface, kind workloadKind, wl *workload) (int64, error) {
+	if kind.kind == workloadDeployment.kind {
+		revision, _ := strconv.ParseInt(wl.Annotations[annotationRevision], 10, 64)
+		return revision, nil
+	}
+
+	opts := metav1.ListOptions{}
+	if wl.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(wl.Selector)
+		if err != nil {
+			return 0, err
+		}
+		opts.LabelSelector = selector.String()
+	}
+	revisions, err := clientset.AppsV1().ControllerRevisions(wl.Namespace).List(ctx, opts)
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for i := range revisions.Items {
+		rev := &revisions.Items[i]
+		if owner := metav1.GetControllerOf(rev); owner != nil && owner.UID == wl.UID {
+			latest = max(latest, rev.Revision)
+		}
+	}
+	return latest, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
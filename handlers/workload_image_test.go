@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func putImages(t *testing.T, handler http.HandlerFunc, path, body string) (*httptest.ResponseRecorder, ImageUpdateResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPut, path, strings.NewReader(body)))
+	var resp ImageUpdateResponse
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w, resp
+}
+
+func TestDeploymentImageUpdate(t *testing.T) {
+	d := newRolledOutDeployment("app:v1", "app:v2")
+	d.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "migrate", Image: "migrate:v2"}}
+	d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, corev1.Container{Name: "sidecar", Image: "proxy:1"})
+	cs := fake.NewSimpleClientset(d)
+	withDeploymentClientset(t, cs)
+
+	w, resp := putImages(t, DeploymentActionsHandler, "/api/deployments/default/web/image",
+		`{"images":{"app":"app:v3","migrate":"migrate:v3","sidecar":"proxy:1"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !resp.Changed || resp.PreviousRevision != 2 || resp.Revision != 3 {
+		t.Errorf("unexpected revisions: %+v", resp)
+	}
+	if resp.ChangeCause != "set image app=app:v3 migrate=migrate:v3" {
+		t.Errorf("default change cause = %q", resp.ChangeCause)
+	}
+
+	got, _ := cs.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	spec := got.Spec.Template.Spec
+	if spec.Containers[0].Image != "app:v3" || spec.Containers[1].Image != "proxy:1" || spec.InitContainers[0].Image != "migrate:v3" {
+		t.Errorf("images not patched by name: %+v %+v", spec.Containers, spec.InitContainers)
+	}
+	if got.Annotations[annotationChangeCause] != resp.ChangeCause {
+		t.Errorf("change-cause annotation = %q", got.Annotations[annotationChangeCause])
+	}
+
+	w, resp = putImages(t, DeploymentActionsHandler, "/api/deployments/default/web/image", `{"images":{"app":"app:v3"}}`)
+	if w.Code != http.StatusOK || resp.Changed || resp.Revision != resp.PreviousRevision {
+		t.Errorf("unchanged image should be a no-op: %d %+v", w.Code, resp)
+	}
+}
+
+func TestStatefulSetImageUpdate_Revision(t *testing.T) {
+	controller := true
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db", UID: types.UID("db-uid")},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: podTemplate("postgres:15"),
+		},
+	}
+	revision := func(name string, n int64, uid types.UID) *appsv1.ControllerRevision {
+		return &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default", Name: name, Labels: map[string]string{"app": "web"},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", UID: uid, Controller: &controller}},
+			},
+			Revision: n,
+		}
+	}
+	withDeploymentClientset(t, fake.NewSimpleClientset(sts,
+		revision("db-1", 1, sts.UID), revision("db-4", 4, sts.UID), revision("other-9", 9, "other-uid")))
+
+	w, resp := putImages(t, StatefulSetActionsHandler, "/api/statefulsets/default/db/image", `{"images":{"app":"postgres:16"},"changeCause":"CVE fix"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if resp.Kind != "StatefulSet" || resp.PreviousRevision != 4 || resp.Revision != 5 || resp.ChangeCause != "CVE fix" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestWorkloadImageUpdate_Validation(t *testing.T) {
+	withDeploymentClientset(t, fake.NewSimpleClientset(newRolledOutDeployment("app:v1")))
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		path    string
+		body    string
+		want    int
+	}{
+		{"unknown container", DeploymentActionsHandler, "/api/deployments/default/web/image", `{"images":{"app":"a:1","nope":"b:1"}}`, http.StatusBadRequest},
+		{"empty image", DeploymentActionsHandler, "/api/deployments/default/web/image", `{"images":{"app":" "}}`, http.StatusBadRequest},
+		{"no images", DeploymentActionsHandler, "/api/deployments/default/web/image", `{}`, http.StatusBadRequest},
+		{"missing deployment", DeploymentActionsHandler, "/api/deployments/default/gone/image", `{"images":{"app":"a:1"}}`, http.StatusNotFound},
+		{"missing daemonset", DaemonSetActionsHandler, "/api/daemonsets/default/web/image", `{"images":{"app":"a:1"}}`, http.StatusNotFound},
+		{"unknown action", DaemonSetActionsHandler, "/api/daemonsets/default/web/explode", ``, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := putImages(t, tt.handler, tt.path, tt.body)
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+			if tt.name == "unknown container" && !strings.Contains(w.Body.String(), "nope") {
+				t.Errorf("error should name the unknown container: %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// workload is the part of a Deployment, StatefulSet or DaemonSet shared by the
+// workload endpoints.
+type workload struct {
+	metav1.ObjectMeta
+	Selector *metav1.LabelSelector
+	Template corev1.PodTemplateSpec
+}
+
+// workloadKind describes how to reach one pod-template workload type.
+type workloadKind struct {
+	kind       string
+	pathPrefix string
+	notFound   string
+	get        func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*workload, error)
+	patch      func(ctx context.Context, clientset kubernetes.Interface, namespace, name string, pt types.PatchType, data []byte) (*workload, error)
+	// detail builds the GET /{prefix}{ns}/{name} response; nil if the kind has none.
+	detail func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (interface{}, error)
+}
+
+var (
+	workloadDeployment = workloadKind{
+		kind:       "Deployment",
+		pathPrefix: deploymentsPathPrefix,
+		notFound:   errMsgDeploymentNotFound,
+		get: func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*workload, error) {
+			d, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return deploymentWorkload(d), nil
+		},
+		patch: func(ctx context.Context, clientset kubernetes.Interface, namespace, name string, pt types.PatchType, data []byte) (*workload, error) {
+			d, err := clientset.AppsV1().Deployments(namespace).Patch(ctx, name, pt, data, metav1.PatchOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return deploymentWorkload(d), nil
+		},
+	}
+	workloadStatefulSet = workloadKind{
+		kind:       "StatefulSet",
+		pathPrefix: statefulSetsPathPrefix,
+		notFound:   errMsgStatefulSetNotFound,
+		detail:     getStatefulSetDetail,
+		get: func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*workload, error) {
+			s, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return &workload{ObjectMeta: s.ObjectMeta, Selector: s.Spec.Selector, Template: s.Spec.Template}, nil
+		},
+		patch: func(ctx context.Context, clientset kubernetes.Interface, namespace, name string, pt types.PatchType, data []byte) (*workload, error) {
+			s, err := clientset.AppsV1().StatefulSets(namespace).Patch(ctx, name, pt, data, metav1.PatchOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return &workload{ObjectMeta: s.ObjectMeta, Selector: s.Spec.Selector, Template: s.Spec.Template}, nil
+		},
+	}
+	workloadDaemonSet = workloadKind{
+		kind:       "DaemonSet",
+		pathPrefix: daemonSetsPathPrefix,
+		notFound:   errMsgDaemonSetNotFound,
+		detail:     getDaemonSetDetail,
+		get: func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*workload, error) {
+			ds, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return &workload{ObjectMeta: ds.ObjectMeta, Selector: ds.Spec.Selector, Template: ds.Spec.Template}, nil
+		},
+		patch: func(ctx context.Context, clientset kubernetes.Interface, namespace, name string, pt types.PatchType, data []byte) (*workload, error) {
+			ds, err := clientset.AppsV1().DaemonSets(namespace).Patch(ctx, name, pt, data, metav1.PatchOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return &workload{ObjectMeta: ds.ObjectMeta, Selector: ds.Spec.Selector, Template: ds.Spec.Template}, nil
+		},
+	}
+)
+
+func deploymentWorkload(d *appsv1.Deployment) *workload {
+	return &workload{ObjectMeta: d.ObjectMeta, Selector: d.Spec.Selector, Template: d.Spec.Template}
+}
+
+// StatefulSetActionsHandler handles GET /api/statefulsets/{ns}/{name} and the
+// /api/statefulsets/{ns}/{name}/{action} endpoints.
+func StatefulSetActionsHandler(w http.ResponseWriter, r *http.Request) {
+	workloadActions(w, r, workloadStatefulSet)
+}
+
+// DaemonSetActionsHandler handles GET /api/daemonsets/{ns}/{name} and the
+// /api/daemonsets/{ns}/{name}/{action} endpoints.
+func DaemonSetActionsHandler(w http.ResponseWriter, r *http.Request) {
+	workloadActions(w, r, workloadDaemonSet)
+}
+
+func workloadActions(w http.ResponseWriter, r *http.Request, kind workloadKind) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, imagePathSuffix):
+		handleWorkloadImage(w, r, kind)
+	case strings.HasSuffix(r.URL.Path, restartPathSuffix):
+		handleWorkloadRestart(w, r, kind)
+	case strings.Count(strings.TrimPrefix(r.URL.Path, kind.pathPrefix), "/") == 1 && kind.detail != nil:
+		handleWorkloadDetail(w, r, kind)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Unknown %s action", strings.ToLower(kind.kind)))
+	}
+}
+
+// handleWorkloadDetail handles GET {prefix}{ns}/{name}.
+func handleWorkloadDetail(w http.ResponseWriter, r *http.Request, kind workloadKind) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, clientset := parseWorkloadAction(w, r, kind.pathPrefix, "")
+	if clientset == nil {
+		return
+	}
+
+	detail, err := kind.detail(r.Context(), clientset, namespace, name)
+	if err != nil {
+		writeWorkloadError(w, err, kind, errMsgWorkloadDetail)
+		return
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// handleWorkloadRestart handles POST {prefix}{ns}/{name}/restart.
+func handleWorkloadRestart(w http.ResponseWriter, r *http.Request, kind workloadKind) {
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+	r = withTimeout(r)
+
+	namespace, name, clientset := parseWorkloadAction(w, r, kind.pathPrefix, restartPathSuffix)
+	if clientset == nil {
+		return
+	}
+
+	generation, err := restartWorkload(r.Context(), clientset, kind, namespace, name)
+	if err != nil {
+		writeWorkloadError(w, err, kind, errMsgWorkloadRestart)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":    kind.kind + " restarted successfully",
+		"generation": generation,
+	})
+}
+
+// restartWorkload restarts a workload's pods by setting the restartedAt annotation
+// on its pod template, as restartDeployment does. It returns the new generation.
+func restartWorkload(ctx context.Context, clientset kubernetes.Interface, kind workloadKind, namespace, name string) (int64, error) {
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		annotationRestartedAt, time.Now().Format(time.RFC3339))
+	updated, err := kind.patch(ctx, clientset, namespace, name, types.StrategicMergePatchType, []byte(patch))
+	if err != nil {
+		return 0, err
+	}
+	return updated.Generation, nil
+}
+
+// WorkloadCondition is a StatefulSet or DaemonSet status condition.
+type WorkloadCondition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+}
+
+// listWorkloadPods returns the pods matching the workload's selector that it controls,
+// sorted by name.
+func listWorkloadPods(ctx context.Context, clientset kubernetes.Interface, wl *workload) ([]PodDetails, error) {
+	opts := metav1.ListOptions{}
+	if wl.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(wl.Selector)
+		if err != nil {
+			return nil, err
+		}
+		opts.LabelSelector = selector.String()
+	}
+	podList, err := clientset.CoreV1().Pods(wl.Namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	pods := make([]PodDetails, 0, len(podList.Items))
+	for i := range podList.Items {
+		pod := podList.Items[i]
+		if owner := metav1.GetControllerOf(&pod); owner == nil || owner.UID != wl.UID {
+			continue
+		}
+		pods = append(pods, buildPodDetails(pod, nil))
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	return pods, nil
+}
+
+// parseWorkloadAction extracts namespace and name from {prefix}{ns}/{name}{suffix}
+// and obtains the clientset. On failure it writes the response and returns a nil clientset.
+func parseWorkloadAction(w http.ResponseWriter, r *http.Request, prefix, suffix string) (string, string, kubernetes.Interface) {
+	return parseNamespacedAction(w, r, prefix, suffix, getDeploymentClientset)
+}
+
+// writeWorkloadError maps a workload operation error onto an HTTP response.
+func writeWorkloadError(w http.ResponseWriter, err error, kind workloadKind, internalMsg string) {
+	var sErr *statusError
+	if errors.As(err, &sErr) {
+		writeError(w, sErr.status, sErr.message)
+		return
+	}
+	writeResourceError(w, err, kind.notFound, internalMsg)
+}
@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"io/fs"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/dlddu/kubernetes-dashboard/handlers"
 )
@@ -14,13 +20,48 @@ import (
 //go:embed frontend/dist
 var frontendFS embed.FS
 
+// shutdownTimeout bounds how long in-flight requests may take to finish after
+// SIGINT or SIGTERM.
+const shutdownTimeout = 10 * time.Second
+
 func main() {
-	router := setupRouter()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server := newServer(ctx)
+	handlers.StartNodeDebugReaper(ctx)
+	historyDone := handlers.StartMetricsHistory(ctx)
 
-	slog.Info("Starting server", "addr", ":8080")
-	if err := http.ListenAndServe(":8080", router); err != nil {
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Starting server", "addr", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
 		slog.Error("Server failed", "error", err)
 		os.Exit(1)
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Server shutdown failed", "error", err)
+	}
+	<-historyDone
+}
+
+// newServer returns the dashboard's HTTP server. Request contexts end with ctx,
+// so long-lived streams such as drain, rollout status and event follow return
+// promptly on shutdown instead of holding Shutdown until shutdownTimeout.
+func newServer(ctx context.Context) *http.Server {
+	return &http.Server{
+		Addr:        ":8080",
+		Handler:     setupRouter(),
+		BaseContext: func(net.Listener) context.Context { return ctx },
 	}
 }
 
@@ -31,16 +72,51 @@ func setupRouter() http.Handler {
 	mux.HandleFunc("/api/livez", handlers.LivezHandler)
 	mux.HandleFunc("/api/readyz", handlers.ReadyzHandler)
 	mux.HandleFunc("/api/namespaces", handlers.NamespacesHandler)
+	mux.HandleFunc("/api/namespaces/", handlers.NamespaceDetailHandler)
+	mux.HandleFunc("/api/events", handlers.EventsHandler)
 	mux.HandleFunc("/api/overview", handlers.OverviewHandler)
+	mux.HandleFunc("/api/overview/history", handlers.OverviewHistoryHandler)
 	mux.HandleFunc("/api/nodes", handlers.NodesHandler)
+	mux.HandleFunc("/api/nodes/", handlers.NodeActionsHandler)
 	mux.HandleFunc("/api/pods/unhealthy", handlers.UnhealthyPodsHandler)
 	mux.HandleFunc("/api/pods/all", handlers.AllPodsHandler)
+	mux.HandleFunc("/api/pods/top", handlers.TopPodsHandler)
+	mux.HandleFunc("/api/pods/detail/", handlers.PodDetailHandler)
+	mux.HandleFunc("/api/pods/history/", handlers.PodHistoryHandler)
 	mux.HandleFunc("/api/pods/cleanup", handlers.CleanupPodsHandler)
 	mux.HandleFunc("/api/pods/logs/", handlers.PodLogsHandler)
 	mux.HandleFunc("/api/pods/exec/", handlers.PodExecHandler)
+	mux.HandleFunc("/api/pods/ephemeral/", handlers.PodEphemeralContainersHandler)
 	mux.HandleFunc("/api/pods/debug/", handlers.PodDebugHandler)
+	mux.HandleFunc("/api/pods/debug/profiles", handlers.DebugProfilesHandler)
+	mux.HandleFunc("/api/pods/debug-copies", handlers.DebugCopiesHandler)
+	mux.HandleFunc("/api/pods/debug-copies/", handlers.DebugCopyDeleteHandler)
+	mux.HandleFunc("/api/pods/portforward/", handlers.PodPortForwardHandler)
+	mux.HandleFunc("/api/pods/proxy/", handlers.PodProxyHandler)
+	mux.HandleFunc("/api/pods/files/", handlers.PodFilesHandler)
+	mux.HandleFunc("/api/services", handlers.ServicesHandler)
+	mux.HandleFunc("/api/services/", handlers.ServiceDetailHandler)
+	mux.HandleFunc("/api/services/portforward/", handlers.ServicePortForwardHandler)
+	mux.HandleFunc("/api/services/proxy/", handlers.ServiceProxyHandler)
+	mux.HandleFunc("/api/ingresses", handlers.IngressesHandler)
+	mux.HandleFunc("/api/ingresses/", handlers.IngressDetailHandler)
 	mux.HandleFunc("/api/deployments", handlers.DeploymentsHandler)
-	mux.HandleFunc("/api/deployments/", handlers.DeploymentRestartHandler)
+	mux.HandleFunc("/api/deployments/", handlers.DeploymentActionsHandler)
+	mux.HandleFunc("/api/statefulsets", handlers.StatefulSetsHandler)
+	mux.HandleFunc("/api/statefulsets/", handlers.StatefulSetActionsHandler)
+	mux.HandleFunc("/api/daemonsets", handlers.DaemonSetsHandler)
+	mux.HandleFunc("/api/daemonsets/", handlers.DaemonSetActionsHandler)
+	mux.HandleFunc("/api/jobs", handlers.JobsHandler)
+	mux.HandleFunc("/api/jobs/", handlers.JobDetailHandler)
+	mux.HandleFunc("/api/cronjobs", handlers.CronJobsHandler)
+	mux.HandleFunc("/api/cronjobs/", handlers.CronJobActionsHandler)
+	mux.HandleFunc("/api/resources", handlers.APIResourcesHandler)
+	mux.HandleFunc("/api/resources/", handlers.ResourcesHandler)
+	mux.HandleFunc("/api/configmaps", handlers.ConfigMapsHandler)
+	mux.HandleFunc("/api/configmaps/", handlers.ConfigMapActionsHandler)
+	mux.HandleFunc("/api/persistentvolumeclaims", handlers.PersistentVolumeClaimsHandler)
+	mux.HandleFunc("/api/persistentvolumes", handlers.PersistentVolumesHandler)
+	mux.HandleFunc("/api/storageclasses", handlers.StorageClassesHandler)
 	mux.HandleFunc("/api/secrets/", handlers.SecretDetailHandler)
 	mux.HandleFunc("/api/secrets", handlers.SecretsHandler)
 	mux.HandleFunc("/api/argo/workflow-templates", handlers.WorkflowTemplatesHandler)
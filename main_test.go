@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 // TestServerSetup tests the HTTP server setup
@@ -485,3 +488,42 @@ func TestArgoWorkflowTemplatesRoute(t *testing.T) {
 		}
 	})
 }
+
+// TestNewServer_ShutdownEndsStreams checks that cancelling the server context
+// ends open streams, so Shutdown does not wait for them to time out.
+func TestNewServer_ShutdownEndsStreams(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := newServer(ctx)
+	streaming := make(chan struct{})
+	server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		close(streaming)
+		<-r.Context().Done()
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(ln) //nolint:errcheck
+
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/api/events?follow=true")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	select {
+	case <-streaming:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not start")
+	}
+
+	cancel()
+	shutdownCtx, stop := context.WithTimeout(context.Background(), 2*time.Second)
+	defer stop()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		t.Errorf("shutdown with an open stream: %v", err)
+	}
+}